@@ -10,13 +10,16 @@ import (
 	"booking-schedule/internal/pkg/auth"
 	"flag"
 	"log"
+	"os"
 )
 
 var configType, pathConfig, pathCert, pathKey string
+var migrationsDir string
 
 func init() {
 	flag.StringVar(&configType, "configtype", "file", "type of configuration: environment variables (env) or env/yaml file (file)")
 	flag.StringVar(&pathConfig, "config", "./configs/auth_config.yml", "path to config file")
+	flag.StringVar(&migrationsDir, "migrations", "./deploy/migrations", "directory migrate create writes new migrations to")
 	flag.StringVar(&pathCert, "certfile", "cert.pem", "certificate PEM file")
 	flag.StringVar(&pathKey, "keyfile", "key.pem", "key PEM file")
 	time.Local = time.UTC
@@ -50,6 +53,14 @@ func main() {
 
 	ctx := context.Background()
 
+	if flag.Arg(0) == "migrate" {
+		err := auth.RunMigrateCommand(ctx, os.Stdout, configType, pathConfig, migrationsDir, flag.Arg(1), flag.Args()[min(flag.NArg(), 2):])
+		if err != nil {
+			log.Fatalf("failed to run migrate command: %s", err.Error())
+		}
+		return
+	}
+
 	app, err := auth.NewApp(ctx, configType, pathConfig, pathCert, pathKey)
 	if err != nil {
 		log.Fatalf("failed to create auth-api app object:%s\n", err.Error())
@@ -11,13 +11,16 @@ import (
 
 	"flag"
 	"log"
+	"os"
 )
 
 var configType, pathConfig, pathCert, pathKey string
+var migrationsDir string
 
 func init() {
 	flag.StringVar(&configType, "configtype", "file", "type of configuration: environment variables (env) or env/yaml file (file)")
 	flag.StringVar(&pathConfig, "config", "./configs/booking_config.yml", "path to config file")
+	flag.StringVar(&migrationsDir, "migrations", "./deploy/migrations", "directory migrate create writes new migrations to")
 	flag.StringVar(&pathCert, "certfile", "cert.pem", "certificate PEM file")
 	flag.StringVar(&pathKey, "keyfile", "key.pem", "key PEM file")
 	time.Local = time.UTC
@@ -44,6 +47,8 @@ func init() {
 // @tag.description operations with bookings, suites and intervals
 // @tag.name users
 // @tag.description service for viewing profile editing or deleting it
+// @tag.name webhooks
+// @tag.description subscriptions to booking lifecycle events and their delivery log
 //
 // @securityDefinitions.apikey Bearer
 // @in header
@@ -54,6 +59,14 @@ func main() {
 
 	ctx := context.Background()
 
+	if flag.Arg(0) == "migrate" {
+		err := bookings.RunMigrateCommand(ctx, os.Stdout, configType, pathConfig, migrationsDir, flag.Arg(1), flag.Args()[min(flag.NArg(), 2):])
+		if err != nil {
+			log.Fatalf("failed to run migrate command: %s", err.Error())
+		}
+		return
+	}
+
 	app, err := bookings.NewApp(ctx, configType, pathConfig, pathCert, pathKey)
 	if err != nil {
 		log.Fatalf("failed to create bookings-api app object:%s\n", err.Error())
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"time"
+	_ "time/tzdata" // даты бронирований считаются в часовых поясах пользователей
+
+	"booking-schedule/internal/pkg/bot"
+	"flag"
+	"log"
+	"os"
+
+	_ "go.uber.org/automaxprocs"
+)
+
+var configType, pathConfig string
+var migrationsDir string
+
+func init() {
+	flag.StringVar(&configType, "configtype", "file", "type of configuration: environment variables (env) or env/yaml file (file)")
+	flag.StringVar(&pathConfig, "config", "./configs/bot_config.yml", "path to bot config file")
+	flag.StringVar(&migrationsDir, "migrations", "./deploy/migrations", "directory migrate create writes new migrations to")
+	time.Local = time.UTC
+}
+
+func main() {
+	flag.Parse()
+	ctx := context.Background()
+
+	if flag.Arg(0) == "migrate" {
+		err := bot.RunMigrateCommand(ctx, os.Stdout, configType, pathConfig, migrationsDir, flag.Arg(1), flag.Args()[min(flag.NArg(), 2):])
+		if err != nil {
+			log.Fatalf("failed to run migrate command: %s", err.Error())
+		}
+		return
+	}
+
+	app, err := bot.NewApp(ctx, configType, pathConfig)
+	if err != nil {
+		log.Fatalf("failed to create bot app object:%s\n", err.Error())
+	}
+
+	err = app.Run(ctx)
+	if err != nil {
+		log.Fatalf("failed to run bot app: %s", err.Error())
+	}
+}
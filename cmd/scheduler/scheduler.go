@@ -3,25 +3,38 @@ package main
 import (
 	"context"
 	"time"
+	_ "time/tzdata" // тихие часы считаются в часовых поясах пользователей
 
 	"booking-schedule/internal/pkg/scheduler"
 	"flag"
 	"log"
+	"os"
 
 	_ "go.uber.org/automaxprocs"
 )
 
 var configType, pathConfig string
+var migrationsDir string
 
 func init() {
 	flag.StringVar(&configType, "configtype", "file", "type of configuration: environment variables (env) or env/yaml file (file)")
 	flag.StringVar(&pathConfig, "config", "./configs/scheduler_config.yml", "path to scheduler config file")
+	flag.StringVar(&migrationsDir, "migrations", "./deploy/migrations", "directory migrate create writes new migrations to")
 	time.Local = time.UTC
 }
 
 func main() {
 	flag.Parse()
 	ctx := context.Background()
+
+	if flag.Arg(0) == "migrate" {
+		err := scheduler.RunMigrateCommand(ctx, os.Stdout, configType, pathConfig, migrationsDir, flag.Arg(1), flag.Args()[min(flag.NArg(), 2):])
+		if err != nil {
+			log.Fatalf("failed to run migrate command: %s", err.Error())
+		}
+		return
+	}
+
 	app, err := scheduler.NewApp(ctx, configType, pathConfig)
 	if err != nil {
 		log.Fatalf("failed to create scheduler app object:%s\n", err.Error())
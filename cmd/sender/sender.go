@@ -5,27 +5,59 @@ import (
 	"context"
 	"flag"
 	"log"
+	"os"
 	"time"
+	_ "time/tzdata" // часовые пояса пользователей не зависят от наличия tzdata в образе
 
 	_ "go.uber.org/automaxprocs"
 )
 
 var configType, pathConfig string
+var migrationsDir string
+var dlqLimit int
 
 func init() {
 	flag.StringVar(&configType, "configtype", "file", "type of configuration: environment variables (env) or env/yaml file (file)")
 	flag.StringVar(&pathConfig, "config", "./configs/sender_config.yml", "path to sender config file")
+	flag.StringVar(&migrationsDir, "migrations", "./deploy/migrations", "directory migrate create writes new migrations to")
+	flag.IntVar(&dlqLimit, "limit", 10, "max number of dead-lettered messages to list or replay")
 	time.Local = time.UTC
 }
 
+// Usage:
+//
+//	sender [flags]                      run sender service
+//	sender [flags] dlq list             show messages from dead-letter queue
+//	sender [flags] dlq replay           move messages from dead-letter queue back to work queue
+//	sender [flags] migrate up           apply pending database migrations
+//	sender [flags] migrate down         roll back the last applied migration
+//	sender [flags] migrate status       list migrations and when they were applied
+//	sender [flags] migrate create NAME  create an empty migration in the -migrations directory
 func main() {
 	flag.Parse()
 	ctx := context.Background()
+
+	if flag.Arg(0) == "migrate" {
+		err := sender.RunMigrateCommand(ctx, os.Stdout, configType, pathConfig, migrationsDir, flag.Arg(1), flag.Args()[min(flag.NArg(), 2):])
+		if err != nil {
+			log.Fatalf("failed to run migrate command: %s", err.Error())
+		}
+		return
+	}
+
 	app, err := sender.NewApp(ctx, configType, pathConfig)
 	if err != nil {
 		log.Fatalf("failed to create sender app object:%s\n", err.Error())
 	}
 
+	if flag.Arg(0) == "dlq" {
+		err = app.RunDeadLetterCommand(os.Stdout, flag.Arg(1), dlqLimit)
+		if err != nil {
+			log.Fatalf("failed to run dead-letter queue command: %s", err.Error())
+		}
+		return
+	}
+
 	err = app.Run(ctx)
 	if err != nil {
 		log.Fatalf("failed to run sender app: %s", err.Error())
@@ -0,0 +1,7 @@
+-- +goose Up
+alter table users add column language text not null default 'ru';
+alter table users add column timezone text not null default 'UTC';
+
+-- +goose Down
+alter table users drop column timezone;
+alter table users drop column language;
@@ -0,0 +1,37 @@
+-- +goose Up
+create table user_preferences (
+    user_id bigint primary key,
+    lead_times interval[] not null default '{}',
+    quiet_hours_start time,
+    quiet_hours_end time,
+    disabled_types text[] not null default '{}',
+    created_at timestamp not null,
+    updated_at timestamp,
+    constraint fk_users
+        foreign key(user_id)
+            references users(id)
+            on delete cascade
+            on update cascade,
+    constraint quiet_hours_complete
+        check ((quiet_hours_start is null) = (quiet_hours_end is null))
+);
+
+create table deferred_notifications (
+    idempotency_key text primary key,
+    user_id bigint not null,
+    type text not null,
+    body bytea not null,
+    send_at timestamp not null,
+    created_at timestamp not null,
+    constraint fk_users
+        foreign key(user_id)
+            references users(id)
+            on delete cascade
+            on update cascade
+);
+
+create index ix_send_at ON deferred_notifications using btree (send_at);
+
+-- +goose Down
+drop table deferred_notifications;
+drop table user_preferences;
@@ -0,0 +1,31 @@
+-- +goose Up
+create table booking_reminders (
+    booking_id uuid not null,
+    lead_time interval not null,
+    sent_at timestamp,
+    primary key (booking_id, lead_time),
+    constraint fk_bookings
+        foreign key(booking_id)
+            references bookings(id)
+            on delete cascade
+            on update cascade
+);
+
+create index ix_unsent ON booking_reminders using btree (booking_id) where sent_at is null;
+
+-- у каждого бронирования есть напоминание в момент начала и по одному на каждый интервал
+insert into booking_reminders (booking_id, lead_time)
+    select id, '0s' from bookings;
+insert into booking_reminders (booking_id, lead_time)
+    select id, notify_at from bookings where notify_at > '0s';
+
+alter table bookings drop column notify_at;
+
+-- +goose Down
+alter table bookings add column notify_at interval default '0s';
+
+update bookings b set notify_at = r.lead_time
+    from (select booking_id, max(lead_time) as lead_time from booking_reminders group by booking_id) r
+    where r.booking_id = b.id;
+
+drop table booking_reminders;
@@ -0,0 +1,14 @@
+-- +goose Up
+alter table booking_reminders add column kind text not null default 'start';
+alter table booking_reminders drop constraint booking_reminders_pkey;
+alter table booking_reminders add primary key (booking_id, kind, lead_time);
+
+alter table user_preferences add column end_lead_times interval[] not null default '{}';
+
+-- +goose Down
+alter table user_preferences drop column end_lead_times;
+
+delete from booking_reminders where kind <> 'start';
+alter table booking_reminders drop constraint booking_reminders_pkey;
+alter table booking_reminders add primary key (booking_id, lead_time);
+alter table booking_reminders drop column kind;
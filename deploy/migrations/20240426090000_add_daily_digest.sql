@@ -0,0 +1,7 @@
+-- +goose Up
+alter table user_preferences add column digest_time time;
+alter table user_preferences add column digest_sent_on date;
+
+-- +goose Down
+alter table user_preferences drop column digest_sent_on;
+alter table user_preferences drop column digest_time;
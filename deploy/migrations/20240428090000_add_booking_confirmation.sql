@@ -0,0 +1,5 @@
+-- +goose Up
+alter table bookings add column confirmed_at timestamp;
+
+-- +goose Down
+alter table bookings drop column confirmed_at;
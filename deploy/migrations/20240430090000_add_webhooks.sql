@@ -0,0 +1,46 @@
+-- +goose Up
+alter table users add column is_admin boolean not null default false;
+
+create table webhooks (
+    id bigserial primary key,
+    user_id bigint not null,
+    url text not null,
+    secret text not null,
+    events text[] not null,
+    all_bookings boolean not null default false,
+    created_at timestamp not null,
+    constraint fk_users
+        foreign key(user_id)
+            references users(id)
+            on delete cascade
+            on update cascade
+);
+
+create index ix_webhook_owner ON webhooks using btree (user_id);
+
+create table webhook_deliveries (
+    id bigserial primary key,
+    webhook_id bigint not null,
+    event text not null,
+    payload jsonb not null,
+    status text not null default 'pending',
+    attempts int not null default 0,
+    next_attempt_at timestamp not null,
+    response_status int,
+    error text,
+    created_at timestamp not null,
+    delivered_at timestamp,
+    constraint fk_webhooks
+        foreign key(webhook_id)
+            references webhooks(id)
+            on delete cascade
+            on update cascade
+);
+
+create index ix_pending_deliveries ON webhook_deliveries using btree (next_attempt_at) where status = 'pending';
+create index ix_webhook_deliveries ON webhook_deliveries using btree (webhook_id);
+
+-- +goose Down
+drop table webhook_deliveries;
+drop table webhooks;
+alter table users drop column is_admin;
@@ -0,0 +1,13 @@
+-- +goose Up
+create table event_outbox (
+    id bigserial primary key,
+    message_id uuid not null,
+    type text not null,
+    routing_key text not null,
+    body jsonb not null,
+    created_at timestamp not null,
+    locked_until timestamp
+);
+
+-- +goose Down
+drop table event_outbox;
@@ -0,0 +1,12 @@
+-- +goose Up
+create table sent_notifications (
+    idempotency_key text primary key,
+    locked_until timestamp,
+    sent_at timestamp,
+    created_at timestamp not null
+);
+
+create index sent_notifications_sent_at_idx on sent_notifications (sent_at);
+
+-- +goose Down
+drop table sent_notifications;
@@ -0,0 +1,20 @@
+-- +goose Up
+-- секции по месяцам окончания бронирования создаются планировщиком перед переносом строк
+create table bookings_archive (
+    id uuid not null,
+    suite_id bigint not null,
+    user_id bigint not null,
+    start_date timestamp not null,
+    end_date timestamp not null,
+    created_at timestamp not null,
+    updated_at timestamp,
+    confirmed_at timestamp,
+    archived_at timestamp not null,
+    primary key (id, end_date)
+) partition by range (end_date);
+
+create index ix_archive_owner ON bookings_archive using btree (user_id, end_date);
+create index ix_archive_suite ON bookings_archive using btree (suite_id, end_date);
+
+-- +goose Down
+drop table bookings_archive;
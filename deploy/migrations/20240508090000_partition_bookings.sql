@@ -0,0 +1,140 @@
+-- +goose Up
+-- первичный ключ секционированной таблицы включает start_date, поэтому напоминания больше не ссылаются
+-- на бронирования внешним ключом; вместе с бронированием их удаляет триггер
+alter table booking_reminders drop constraint fk_bookings;
+
+alter table bookings rename to bookings_heap;
+alter index bookings_pkey rename to bookings_heap_pkey;
+
+create table bookings (
+    id uuid not null,
+    start_date timestamp not null,
+    end_date timestamp not null,
+    created_at timestamp not null,
+    updated_at timestamp,
+    suite_id bigint not null,
+    user_id bigint not null,
+    confirmed_at timestamp,
+    primary key (id, start_date),
+    constraint fk_rooms
+        foreign key(suite_id)
+            references rooms(id)
+            on delete cascade
+            on update cascade,
+    constraint fk_users
+        foreign key(user_id)
+            references users(id)
+            on delete cascade
+            on update cascade
+) partition by range (start_date);
+
+-- сюда попадают бронирования месяцев, секции которых планировщик еще не создал
+create table bookings_default partition of bookings default;
+
+-- +goose StatementBegin
+create function delete_booking_reminders() returns trigger as $$
+begin
+    -- строка перенесена в другую секцию: обновлением start_date или при создании секции
+    if exists (select 1 from bookings where id = old.id) then
+        return null;
+    end if;
+
+    delete from booking_reminders where booking_id = old.id;
+    return null;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+create function create_bookings_partition(for_date timestamp) returns boolean as $$
+declare
+    part_from timestamp := date_trunc('month', for_date);
+    part_to timestamp := date_trunc('month', for_date) + interval '1 month';
+    part_name text := 'bookings_y' || to_char(date_trunc('month', for_date), 'YYYY') || 'm' || to_char(date_trunc('month', for_date), 'MM');
+begin
+    if to_regclass(part_name) is not null then
+        return false;
+    end if;
+
+    -- секцию нельзя присоединить, пока строки ее месяца лежат в секции по умолчанию
+    execute format('create table %I (like bookings including defaults including constraints)', part_name);
+    execute format('with moved as (delete from bookings_default where start_date >= %L and start_date < %L returning *) insert into %I select * from moved', part_from, part_to, part_name);
+
+    execute format('alter table bookings attach partition %I for values from (%L) to (%L)', part_name, part_from, part_to);
+
+    return true;
+end;
+$$ language plpgsql;
+-- +goose StatementEnd
+
+-- секции для имеющихся бронирований и на три месяца вперед
+select create_bookings_partition(m)
+    from generate_series(
+        date_trunc('month', coalesce((select min(start_date) from bookings_heap), now()::timestamp)),
+        date_trunc('month', now()::timestamp) + interval '3 months',
+        interval '1 month') as m;
+
+insert into bookings (id, start_date, end_date, created_at, updated_at, suite_id, user_id, confirmed_at)
+    select id, start_date, end_date, created_at, updated_at, suite_id, user_id, confirmed_at from bookings_heap;
+
+drop table bookings_heap;
+
+create index ix_uuid ON bookings using btree (id);
+create index ix_start ON bookings using brin (start_date);
+create index ix_end ON bookings using brin (end_date);
+create index ix_suite ON bookings using btree (suite_id);
+create index ix_owner ON bookings using btree (user_id);
+
+-- изменение start_date, переносящее бронирование в другую секцию, удаляет строку из старой секции и вставляет
+-- в новую; триггер откладывается до конца транзакции и не трогает напоминания бронирования, которое еще существует
+create constraint trigger tr_delete_booking_reminders
+    after delete on bookings
+    deferrable initially deferred
+    for each row execute function delete_booking_reminders();
+
+-- +goose Down
+create table bookings_heap (
+    id uuid primary key,
+    start_date timestamp not null,
+    end_date timestamp not null,
+    created_at timestamp not null,
+    updated_at timestamp,
+    suite_id bigint not null,
+    user_id bigint not null,
+    confirmed_at timestamp,
+    constraint fk_rooms
+        foreign key(suite_id)
+            references rooms(id)
+            on delete cascade
+            on update cascade,
+    constraint fk_users
+        foreign key(user_id)
+            references users(id)
+            on delete cascade
+            on update cascade
+);
+
+insert into bookings_heap (id, start_date, end_date, created_at, updated_at, suite_id, user_id, confirmed_at)
+    select id, start_date, end_date, created_at, updated_at, suite_id, user_id, confirmed_at from bookings;
+
+-- отсоединенные секции остаются отдельными таблицами
+drop table bookings;
+drop function delete_booking_reminders();
+drop function create_bookings_partition(timestamp);
+
+alter table bookings_heap rename to bookings;
+alter index bookings_heap_pkey rename to bookings_pkey;
+
+create index ix_uuid ON bookings using btree (id);
+create index ix_start ON bookings using brin (start_date);
+create index ix_end ON bookings using brin (end_date);
+create index ix_suite ON bookings using btree (suite_id);
+create index ix_owner ON bookings using btree (user_id);
+
+delete from booking_reminders r where not exists (select 1 from bookings b where b.id = r.booking_id);
+
+alter table booking_reminders add constraint fk_bookings
+    foreign key(booking_id)
+        references bookings(id)
+        on delete cascade
+        on update cascade;
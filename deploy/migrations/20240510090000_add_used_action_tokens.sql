@@ -0,0 +1,11 @@
+-- +goose Up
+create table used_action_tokens (
+    token_id uuid primary key,
+    expires_at timestamp not null,
+    used_at timestamp not null
+);
+
+create index used_action_tokens_expires_at_idx on used_action_tokens (expires_at);
+
+-- +goose Down
+drop table used_action_tokens;
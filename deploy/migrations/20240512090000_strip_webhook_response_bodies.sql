@@ -0,0 +1,7 @@
+-- +goose Up
+-- ответы получателей больше не сохраняются, в журнале доставок остается только код ответа
+update webhook_deliveries
+set error = substring(error from '^unexpected response status [0-9]+')
+where error ~ '^unexpected response status [0-9]+: ';
+
+-- +goose Down
@@ -0,0 +1,8 @@
+// Package migrations embeds the goose migrations of the bookings database, so that every service
+// binary can apply them without the migration files at hand.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
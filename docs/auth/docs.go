@@ -147,6 +147,15 @@ const docTemplate = `{
                 "telegramNickname"
             ],
             "properties": {
+                "language": {
+                    "description": "Язык уведомлений: ru или en, по умолчанию ru",
+                    "type": "string",
+                    "enum": [
+                        "ru",
+                        "en"
+                    ],
+                    "example": "en"
+                },
                 "name": {
                     "description": "Имя пользователя",
                     "type": "string",
@@ -166,6 +175,11 @@ const docTemplate = `{
                     "description": "Никнейм пользователя в телеграме",
                     "type": "string",
                     "example": "pavel_durov"
+                },
+                "timezone": {
+                    "description": "Часовой пояс из базы IANA для отображения времени в уведомлениях, по умолчанию UTC",
+                    "type": "string",
+                    "example": "Europe/Moscow"
                 }
             }
         }
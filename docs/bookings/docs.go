@@ -23,6 +23,106 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/actions/{token}": {
+            "get": {
+                "description": "Renders a page with a button that posts the action token back, the action itself is only taken by the POST request. Following a link from a notification therefore never changes the booking by itself.",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Shows the confirmation page of an action on a booking",
+                "operationId": "showBookingAction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "action token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "confirmation page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Confirms attendance, cancels the booking or extends it by 30 minutes on behalf of the user the action token was issued to. Tokens are signed, expiring and sent as links and buttons in booking reminders that open the confirmation page, no other authorization is required. Every token is accepted once, replaying it fails with 409.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "bookings"
+                ],
+                "summary": "Takes an action on a booking from a notification",
+                "operationId": "handleBookingAction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "action token",
+                        "name": "token",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/BookingActionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    }
+                }
+            }
+        },
         "/add": {
             "post": {
                 "security": [
@@ -30,7 +130,7 @@ const docTemplate = `{
                         "Bearer": []
                     }
                 ],
-                "description": "Adds an  associated with user with given parameters. NotificationPeriod is optional and must look like {number}s,{number}m or {number}h. Implemented with the use of transaction: first rooms availibility is checked. In case one's new booking request intersects with and old one(even if belongs to him), the request is considered erratic. startDate is to be before endDate and both should not be expired.",
+                "description": "Adds an  associated with user with given parameters. NotifyAt is optional and may be a single lead time or a list of them, each must look like {number}s,{number}m or {number}h. If it is omitted, lead times from the user notification preferences are used. Implemented with the use of transaction: first rooms availibility is checked. In case one's new booking request intersects with and old one(even if belongs to him), the request is considered erratic. startDate is to be before endDate and both should not be expired.",
                 "consumes": [
                     "application/json"
                 ],
@@ -295,7 +395,369 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/me": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Responds with account info for signed in user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get info for current user",
+                "operationId": "getMyUserAuth",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/GetMyProfileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Responds with notification preferences of signed in user: default reminder lead times, quiet hours and notification types the user opted out of.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get notification preferences",
+                "operationId": "getMyPreferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/GetPreferencesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Replaces notification preferences of signed in user. Lead times are used for bookings without their own notifyAt, up to 5 positive durations not longer than 720h. Reminders due in quiet hours are deferred till they end unless the booking starts earlier. Quiet hours are set in the time zone of the user profile. The daily digest of bookings is opt-in and is sent at digestTime in the time zone of the user profile.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Set notification preferences",
+                "operationId": "setMyPreferences",
+                "parameters": [
+                    {
+                        "description": "SetPreferencesRequest",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/SetPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Responds with the webhooks registered by signed in user, secrets are not included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Lists webhooks",
+                "operationId": "getWebhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/GetWebhooksResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Registers an endpoint booking.created, booking.updated and booking.cancelled events about bookings of signed in user are posted to. Admins may subscribe to events of all bookings. Deliveries are signed with HMAC-SHA256 of the X-Webhook-Timestamp header value, a dot and the body, sent as X-Webhook-Signature. The secret is generated unless given and is only returned in this response. Failed deliveries are retried with exponential backoff.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Registers a webhook",
+                "operationId": "addWebhook",
+                "parameters": [
+                    {
+                        "description": "AddWebhookRequest",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/AddWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/AddWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhook_id}": {
+            "delete": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Deletes the webhook of signed in user together with its delivery log, pending deliveries are dropped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Deletes a webhook",
+                "operationId": "deleteWebhook",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "webhook_id",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/Error"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhook_id}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "Bearer": []
+                    }
+                ],
+                "description": "Responds with the delivery log of the webhook of signed in user, up to 100 latest deliveries, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "Lists deliveries of a webhook",
+                "operationId": "getWebhookDeliveries",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "webhook_id",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/GetDeliveriesResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -324,27 +786,43 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/me": {
-            "get": {
+        "/webhooks/{webhook_id}/deliveries/{delivery_id}/replay": {
+            "post": {
                 "security": [
                     {
                         "Bearer": []
                     }
                 ],
-                "description": "Responds with account info for signed in user.",
+                "description": "Enqueues the payload of a delivery of the webhook of signed in user once again, e.g. after the receiver was fixed. The replay is a new delivery with its own id and attempts.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "webhooks"
+                ],
+                "summary": "Replays a delivery",
+                "operationId": "replayWebhookDelivery",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "webhook_id",
+                        "name": "webhook_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "delivery_id",
+                        "name": "delivery_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get info for current user",
-                "operationId": "getMyUserAuth",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/GetMyProfileResponse"
+                            "$ref": "#/definitions/ReplayDeliveryResponse"
                         }
                     },
                     "400": {
@@ -629,9 +1107,25 @@ const docTemplate = `{
                     "example": "2024-03-29T17:43:00Z"
                 },
                 "notifyAt": {
-                    "description": "Интервал времени для предварительного уведомления о бронировании",
-                    "type": "string",
-                    "example": "24h"
+                    "description": "Интервалы времени для предварительных уведомлений о бронировании, строка или список строк",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "24h",
+                        "15m"
+                    ]
+                },
+                "notifyBeforeEnd": {
+                    "description": "Интервалы времени до окончания бронирования для напоминаний о выезде, строка или список строк",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "1h"
+                    ]
                 },
                 "startDate": {
                     "description": "Дата и время начала бронировании",
@@ -655,6 +1149,80 @@ const docTemplate = `{
                 }
             }
         },
+        "AddWebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "allBookings": {
+                    "description": "Получать события обо всех бронированиях, доступно только администраторам",
+                    "type": "boolean",
+                    "example": false
+                },
+                "events": {
+                    "description": "События, на которые подписан вебхук: booking.created, booking.updated, booking.cancelled",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "booking.created",
+                        "booking.cancelled"
+                    ]
+                },
+                "secret": {
+                    "description": "Ключ подписи доставок, если не задан, генерируется автоматически",
+                    "type": "string",
+                    "minLength": 16,
+                    "example": "verysecretivewebhook"
+                },
+                "url": {
+                    "description": "Адрес, на который отправляются события",
+                    "type": "string",
+                    "example": "https://example.com/hooks/bookings"
+                }
+            }
+        },
+        "AddWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "Ключ подписи доставок, возвращается только при создании вебхука",
+                    "type": "string",
+                    "example": "verysecretivewebhook"
+                },
+                "webhook": {
+                    "$ref": "#/definitions/Webhook"
+                }
+            }
+        },
+        "BookingActionResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "Выполненное действие: confirm, cancel или extend",
+                    "type": "string",
+                    "example": "extend"
+                },
+                "booking": {
+                    "description": "Бронирование после выполнения действия, отсутствует для отмененного бронирования",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/BookingInfo"
+                        }
+                    ]
+                },
+                "bookingID": {
+                    "description": "Идентификатор бронирования, к которому применено действие",
+                    "type": "string",
+                    "format": "uuid",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
         "BookingInfo": {
             "type": "object",
             "properties": {
@@ -664,6 +1232,11 @@ const docTemplate = `{
                     "format": "uuid",
                     "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
+                "confirmedAt": {
+                    "description": "Дата и время подтверждения того, что пользователь приедет",
+                    "type": "string",
+                    "example": "2024-03-28T09:00:00Z"
+                },
                 "createdAt": {
                     "description": "Дата и время создания",
                     "type": "string",
@@ -675,9 +1248,25 @@ const docTemplate = `{
                     "example": "2024-03-29T17:43:00Z"
                 },
                 "notifyAt": {
-                    "description": "Интервал времени для уведомления о бронировании",
-                    "type": "string",
-                    "example": "24h00m00s"
+                    "description": "Интервалы времени для уведомлений о бронировании",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "24h0m0s",
+                        "15m0s"
+                    ]
+                },
+                "notifyBeforeEnd": {
+                    "description": "Интервалы времени до окончания бронирования для напоминаний о выезде",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "1h0m0s"
+                    ]
                 },
                 "startDate": {
                     "description": "Дата и время начала бронировании",
@@ -704,6 +1293,11 @@ const docTemplate = `{
         "EditMyProfileRequest": {
             "type": "object",
             "properties": {
+                "language": {
+                    "description": "Язык уведомлений: ru или en",
+                    "type": "string",
+                    "example": "ru"
+                },
                 "name": {
                     "description": "Имя пользователя",
                     "type": "string",
@@ -723,6 +1317,11 @@ const docTemplate = `{
                     "description": "Никнейм пользователя в телеграме",
                     "type": "string",
                     "example": "kolya_durov"
+                },
+                "timezone": {
+                    "description": "Часовой пояс из базы IANA",
+                    "type": "string",
+                    "example": "Asia/Yekaterinburg"
                 }
             }
         },
@@ -758,6 +1357,17 @@ const docTemplate = `{
                 }
             }
         },
+        "GetDeliveriesResponse": {
+            "type": "object",
+            "properties": {
+                "deliveries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/WebhookDelivery"
+                    }
+                }
+            }
+        },
         "GetMyProfileResponse": {
             "type": "object",
             "properties": {
@@ -771,6 +1381,14 @@ const docTemplate = `{
                 }
             }
         },
+        "GetPreferencesResponse": {
+            "type": "object",
+            "properties": {
+                "preferences": {
+                    "$ref": "#/definitions/Preferences"
+                }
+            }
+        },
         "GetVacantDateResponse": {
             "type": "object",
             "properties": {
@@ -793,6 +1411,17 @@ const docTemplate = `{
                 }
             }
         },
+        "GetWebhooksResponse": {
+            "type": "object",
+            "properties": {
+                "webhooks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/Webhook"
+                    }
+                }
+            }
+        },
         "Interval": {
             "type": "object",
             "properties": {
@@ -808,6 +1437,135 @@ const docTemplate = `{
                 }
             }
         },
+        "Preferences": {
+            "type": "object",
+            "properties": {
+                "digestTime": {
+                    "description": "Время ежедневной сводки бронирований в часовом поясе пользователя, отсутствует, если сводка отключена",
+                    "type": "string",
+                    "example": "08:00"
+                },
+                "disabledTypes": {
+                    "description": "Типы уведомлений, от которых пользователь отказался",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "booking.reminder"
+                    ]
+                },
+                "endLeadTimes": {
+                    "description": "Интервалы до окончания бронирования для напоминаний о выезде, если у бронирования не задан notifyBeforeEnd",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "1h"
+                    ]
+                },
+                "leadTimes": {
+                    "description": "Интервалы до начала бронирования для напоминаний, если у бронирования не задан notifyAt",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "24h",
+                        "1h"
+                    ]
+                },
+                "quietHours": {
+                    "description": "Тихие часы, в которые несрочные уведомления откладываются до их окончания",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/QuietHours"
+                        }
+                    ]
+                }
+            }
+        },
+        "QuietHours": {
+            "type": "object",
+            "required": [
+                "end",
+                "start"
+            ],
+            "properties": {
+                "end": {
+                    "description": "Окончание тихих часов в часовом поясе пользователя",
+                    "type": "string",
+                    "example": "08:00"
+                },
+                "start": {
+                    "description": "Начало тихих часов в часовом поясе пользователя",
+                    "type": "string",
+                    "example": "22:00"
+                }
+            }
+        },
+        "ReplayDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "deliveryID": {
+                    "description": "Идентификатор новой доставки",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "SetPreferencesRequest": {
+            "type": "object",
+            "properties": {
+                "digestTime": {
+                    "description": "Время ежедневной сводки бронирований в часовом поясе пользователя, если не задано, сводка не отправляется",
+                    "type": "string",
+                    "example": "08:00"
+                },
+                "disabledTypes": {
+                    "description": "Типы уведомлений, от которых пользователь отказался",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "booking.reminder"
+                    ]
+                },
+                "endLeadTimes": {
+                    "description": "Интервалы до окончания бронирования для напоминаний о выезде, если у бронирования не задан notifyBeforeEnd",
+                    "type": "array",
+                    "maxItems": 5,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "1h"
+                    ]
+                },
+                "leadTimes": {
+                    "description": "Интервалы до начала бронирования для напоминаний, если у бронирования не задан notifyAt",
+                    "type": "array",
+                    "maxItems": 5,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "24h",
+                        "1h"
+                    ]
+                },
+                "quietHours": {
+                    "description": "Тихие часы, в которые несрочные уведомления откладываются до их окончания",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/QuietHours"
+                        }
+                    ]
+                }
+            }
+        },
         "Suite": {
             "type": "object",
             "properties": {
@@ -842,9 +1600,25 @@ const docTemplate = `{
                     "example": "2024-03-29T17:43:00Z"
                 },
                 "notifyAt": {
-                    "description": "Интервал времени для предварительного уведомления о бронировании",
-                    "type": "string",
-                    "example": "24h"
+                    "description": "Интервалы времени для предварительных уведомлений о бронировании, строка или список строк.\nЕсли не переданы, уведомления не меняются, пустой список отключает их",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "24h",
+                        "15m"
+                    ]
+                },
+                "notifyBeforeEnd": {
+                    "description": "Интервалы времени до окончания бронирования для напоминаний о выезде, строка или список строк.\nЕсли не переданы, уведомления не меняются, пустой список отключает их",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "1h"
+                    ]
                 },
                 "startDate": {
                     "description": "Дата и время начала бронировании",
@@ -869,6 +1643,10 @@ const docTemplate = `{
                     "description": "ID пользователя в системе",
                     "type": "integer"
                 },
+                "language": {
+                    "description": "Язык уведомлений",
+                    "type": "string"
+                },
                 "name": {
                     "description": "Имя пользователя",
                     "type": "string"
@@ -881,11 +1659,105 @@ const docTemplate = `{
                     "description": "Никнейм пользователя в телеграме",
                     "type": "string"
                 },
+                "timezone": {
+                    "description": "Часовой пояс пользователя",
+                    "type": "string"
+                },
                 "updatedAt": {
                     "description": "Дата и время обновления профиля",
                     "type": "string"
                 }
             }
+        },
+        "Webhook": {
+            "type": "object",
+            "properties": {
+                "allBookings": {
+                    "description": "Получать события обо всех бронированиях",
+                    "type": "boolean",
+                    "example": false
+                },
+                "createdAt": {
+                    "description": "Дата и время создания",
+                    "type": "string",
+                    "example": "2024-03-27T17:43:00Z"
+                },
+                "events": {
+                    "description": "События, на которые подписан вебхук",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "booking.created",
+                        "booking.cancelled"
+                    ]
+                },
+                "id": {
+                    "description": "Идентификатор вебхука",
+                    "type": "integer",
+                    "example": 1
+                },
+                "url": {
+                    "description": "Адрес, на который отправляются события",
+                    "type": "string",
+                    "example": "https://example.com/hooks/bookings"
+                }
+            }
+        },
+        "WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Количество сделанных попыток",
+                    "type": "integer",
+                    "example": 1
+                },
+                "createdAt": {
+                    "description": "Дата и время создания",
+                    "type": "string",
+                    "example": "2024-03-27T17:43:00Z"
+                },
+                "deliveredAt": {
+                    "description": "Дата и время успешной доставки",
+                    "type": "string",
+                    "example": "2024-03-27T17:43:01Z"
+                },
+                "error": {
+                    "description": "Ошибка последней попытки",
+                    "type": "string",
+                    "example": "unexpected response status 502"
+                },
+                "event": {
+                    "description": "Событие",
+                    "type": "string",
+                    "example": "booking.created"
+                },
+                "id": {
+                    "description": "Идентификатор доставки, передается в заголовке X-Webhook-Delivery",
+                    "type": "integer",
+                    "example": 1
+                },
+                "nextAttemptAt": {
+                    "description": "Дата и время следующей попытки, только для ожидающих доставки",
+                    "type": "string",
+                    "example": "2024-03-27T17:44:00Z"
+                },
+                "payload": {
+                    "description": "Тело запроса, отправляемое получателю",
+                    "type": "object"
+                },
+                "responseStatus": {
+                    "description": "HTTP статус последнего ответа получателя",
+                    "type": "integer",
+                    "example": 200
+                },
+                "status": {
+                    "description": "Состояние доставки: pending, delivered или failed",
+                    "type": "string",
+                    "example": "delivered"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -904,6 +1776,10 @@ const docTemplate = `{
         {
             "description": "service for viewing profile editing or deleting it",
             "name": "users"
+        },
+        {
+            "description": "subscriptions to booking lifecycle events and their delivery log",
+            "name": "webhooks"
         }
     ]
 }`
@@ -0,0 +1,45 @@
+package archive
+
+import (
+	archiveRepo "booking-schedule/internal/app/repository/archive"
+	userRepo "booking-schedule/internal/app/repository/user"
+	"booking-schedule/internal/app/service/archive"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Implementation struct {
+	archive *archive.Service
+	tracer  trace.Tracer
+}
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+var errInvalidLimit = errors.New("limit should be a number from 1 to 1000")
+
+func NewImplementation(archive *archive.Service, tracer trace.Tracer) *Implementation {
+	return &Implementation{
+		archive: archive,
+		tracer:  tracer,
+	}
+}
+
+func GetErrorCode(err error) int {
+	switch {
+	case errors.Is(err, userRepo.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, archive.ErrNotAdmin):
+		return http.StatusForbidden
+	case errors.Is(err, archiveRepo.ErrNoConnection):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, userRepo.ErrNoConnection):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
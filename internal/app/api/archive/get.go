@@ -0,0 +1,145 @@
+package archive
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/app/convert"
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/middleware/auth"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetArchivedBookings godoc
+//
+//	@Summary		Queries the bookings archive
+//	@Description	Responds with the bookings moved to the archive once they got older than the booking TTL, the latest first. Bookings may be filtered by user, suite and a period they overlap. Available to admins only.
+//	@ID				getArchivedBookings
+//	@Tags			archive
+//	@Produce		json
+//
+//	@Param			user_id		query	int		false	"user_id"
+//	@Param			suite_id	query	int		false	"suite_id"
+//	@Param			start		query	string	false	"start"	Format(time) default(2024-03-28T17:43:00)
+//	@Param			end			query	string	false	"end"	Format(time) default(2024-03-29T17:43:00)
+//	@Param			limit		query	int		false	"limit"	default(100) minimum(1) maximum(1000)
+//	@Param			offset		query	int		false	"offset"	default(0) minimum(0)
+//	@Success		200	{object}	api.GetArchivedBookingsResponse
+//	@Failure		400	{object}	api.errResponse
+//	@Failure		401	{object}	api.errResponse
+//	@Failure		403	{object}	api.errResponse
+//	@Failure		503	{object}	api.errResponse
+//	@Router			/archive/bookings [get]
+//
+// @Security Bearer
+func (i *Implementation) GetArchivedBookings(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.archive.GetArchivedBookings"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		userID := auth.UserIDFromContext(ctx)
+		if userID == 0 {
+			span.RecordError(api.ErrNoUserID)
+			span.SetStatus(codes.Error, api.ErrNoUserID.Error())
+			log.Error("no user id in context", sl.Err(api.ErrNoUserID))
+			api.WriteWithError(w, http.StatusUnauthorized, api.ErrNoAuth.Error())
+			return
+		}
+
+		filter, err := parseFilter(r.URL.Query())
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("invalid request", sl.Err(err))
+			api.WriteWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		span.AddEvent("filter parsed")
+
+		bookings, err := i.archive.GetArchivedBookings(ctx, userID, filter)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to get archived bookings", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("archived bookings acquired", trace.WithAttributes(attribute.Int("quantity", len(bookings))))
+
+		api.WriteWithStatus(w, http.StatusOK, api.GetArchivedBookingsResponse{Bookings: convert.ToApiArchivedBookings(bookings)})
+	}
+}
+
+func parseFilter(query url.Values) (*model.ArchiveFilter, error) {
+	filter := &model.ArchiveFilter{Limit: defaultLimit}
+
+	var err error
+	for _, param := range []struct {
+		name string
+		dest *int64
+	}{
+		{"user_id", &filter.UserID},
+		{"suite_id", &filter.SuiteID},
+	} {
+		if v := query.Get(param.name); v != "" {
+			*param.dest, err = strconv.ParseInt(v, 10, 64)
+			if err != nil || *param.dest <= 0 {
+				return nil, api.ErrParse
+			}
+		}
+	}
+
+	for _, param := range []struct {
+		name string
+		dest *time.Time
+	}{
+		{"start", &filter.Start},
+		{"end", &filter.End},
+	} {
+		if v := query.Get(param.name); v != "" {
+			*param.dest, err = time.Parse("2006-01-02T15:04:05", v)
+			if err != nil {
+				return nil, api.ErrInvalidDateFormat
+			}
+		}
+	}
+
+	if !filter.Start.IsZero() && !filter.End.IsZero() && !filter.End.After(filter.Start) {
+		return nil, api.ErrInvalidInterval
+	}
+
+	if v := query.Get("limit"); v != "" {
+		filter.Limit, err = strconv.ParseUint(v, 10, 64)
+		if err != nil || filter.Limit == 0 || filter.Limit > maxLimit {
+			return nil, errInvalidLimit
+		}
+	}
+
+	if v := query.Get("offset"); v != "" {
+		filter.Offset, err = strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, api.ErrParse
+		}
+	}
+
+	return filter, nil
+}
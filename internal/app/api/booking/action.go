@@ -0,0 +1,193 @@
+package booking
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/app/convert"
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/logger/sl"
+	"html/template"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// actionPage asks the user to confirm the action, so that link previews and mail scanners fetching the link
+// do not take it. The form posts back to the same address.
+var actionPage = template.Must(template.New("action").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>Booking {{.BookingID}}</p>
+<form method="post">
+<button type="submit">{{.Button}}</button>
+</form>
+</body>
+</html>
+`))
+
+type actionView struct {
+	Title     string
+	Button    string
+	BookingID string
+}
+
+var actionViews = map[string]actionView{
+	model.ActionConfirm: {Title: "Confirm that you will attend the booking?", Button: "Confirm"},
+	model.ActionCancel:  {Title: "Cancel the booking?", Button: "Cancel booking"},
+	model.ActionExtend:  {Title: "Extend the booking by 30 minutes?", Button: "Extend"},
+}
+
+// ShowBookingAction godoc
+//
+//	@Summary		Shows the confirmation page of an action on a booking
+//	@Description	Renders a page with a button that posts the action token back, the action itself is only taken by the POST request. Following a link from a notification therefore never changes the booking by itself.
+//	@ID				showBookingAction
+//	@Tags			bookings
+//	@Produce		html
+//
+//	@Param			token	path		string	true	"action token"
+//	@Success		200		{string}	string	"confirmation page"
+//	@Failure		400		{object}	api.errResponse
+//	@Failure		401		{object}	api.errResponse
+//	@Router			/actions/{token} [get]
+func (i *Implementation) ShowBookingAction(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.booking.ShowBookingAction"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		token := chi.URLParam(r, "token")
+		if token == "" {
+			span.RecordError(errNoToken)
+			span.SetStatus(codes.Error, errNoToken.Error())
+			log.Error("invalid request", sl.Err(errNoToken))
+			api.WriteWithError(w, http.StatusBadRequest, errNoToken.Error())
+			return
+		}
+
+		action, err := i.action.VerifyToken(ctx, token)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("invalid action token", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		view := actionViews[action.Action]
+		view.BookingID = action.BookingID.String()
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Set("Referrer-Policy", "no-referrer")
+		w.WriteHeader(http.StatusOK)
+
+		err = actionPage.Execute(w, view)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to render the action page", sl.Err(err))
+			return
+		}
+
+		span.AddEvent("action page rendered", trace.WithAttributes(attribute.String("action", action.Action)))
+	}
+}
+
+// HandleBookingAction godoc
+//
+//	@Summary		Takes an action on a booking from a notification
+//	@Description	Confirms attendance, cancels the booking or extends it by 30 minutes on behalf of the user the action token was issued to. Tokens are signed, expiring and sent as links and buttons in booking reminders that open the confirmation page, no other authorization is required. Every token is accepted once, replaying it fails with 409.
+//	@ID				handleBookingAction
+//	@Tags			bookings
+//	@Produce		json
+//
+//	@Param			token	path		string	true	"action token"
+//	@Success		200		{object}	api.BookingActionResponse
+//	@Failure		400		{object}	api.errResponse
+//	@Failure		401		{object}	api.errResponse
+//	@Failure		404		{object}	api.errResponse
+//	@Failure		409		{object}	api.errResponse
+//	@Failure		503		{object}	api.errResponse
+//	@Router			/actions/{token} [post]
+func (i *Implementation) HandleBookingAction(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.booking.HandleBookingAction"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		token := chi.URLParam(r, "token")
+		if token == "" {
+			span.RecordError(errNoToken)
+			span.SetStatus(codes.Error, errNoToken.Error())
+			log.Error("invalid request", sl.Err(errNoToken))
+			api.WriteWithError(w, http.StatusBadRequest, errNoToken.Error())
+			return
+		}
+
+		action, err := i.action.VerifyToken(ctx, token)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("invalid action token", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("action token verified", trace.WithAttributes(
+			attribute.String("action", action.Action),
+			attribute.String("booking_id", action.BookingID.String()),
+			attribute.Int64("user_id", action.UserID),
+		))
+
+		resp := &api.BookingActionResponse{
+			Action:    action.Action,
+			BookingID: action.BookingID,
+		}
+
+		booking, err := i.booking.TakeAction(ctx, action)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("internal error", sl.Err(err), slog.String("action", action.Action))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		if booking != nil {
+			resp.BookingInfo = convert.ToApiBookingInfo(booking)
+		}
+
+		span.AddEvent("action taken")
+		log.Info("action taken", slog.String("action", action.Action), slog.Any("bookingID", action.BookingID))
+
+		api.WriteWithStatus(w, http.StatusOK, resp)
+	}
+}
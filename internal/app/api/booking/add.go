@@ -22,7 +22,7 @@ import (
 // AddBooking godoc
 //
 //	@Summary		Adds booking
-//	@Description	Adds an  associated with user with given parameters. NotificationPeriod is optional and must look like {number}s,{number}m or {number}h. Implemented with the use of transaction: first rooms availibility is checked. In case one's new booking request intersects with and old one(even if belongs to him), the request is considered erratic. startDate is to be before endDate and both should not be expired.
+//	@Description	Adds an  associated with user with given parameters. NotifyAt is optional and may be a single lead time or a list of them, each must look like {number}s,{number}m or {number}h. If it is omitted, lead times from the user notification preferences are used. Implemented with the use of transaction: first rooms availibility is checked. In case one's new booking request intersects with and old one(even if belongs to him), the request is considered erratic. startDate is to be before endDate and both should not be expired.
 //	@ID				addByBookingJSON
 //	@Tags			bookings
 //	@Accept			json
@@ -85,11 +85,12 @@ func (i *Implementation) AddBooking(logger *slog.Logger) http.HandlerFunc {
 		log.Info("request body decoded", slog.Any("req", req))
 		//TODO: getters
 		mod, err := convert.ToBookingInfo(&api.Booking{
-			UserID:    userID,
-			SuiteID:   req.SuiteID,
-			StartDate: req.StartDate,
-			EndDate:   req.EndDate,
-			NotifyAt:  req.NotifyAt,
+			UserID:          userID,
+			SuiteID:         req.SuiteID,
+			StartDate:       req.StartDate,
+			EndDate:         req.EndDate,
+			NotifyAt:        req.NotifyAt,
+			NotifyBeforeEnd: req.NotifyBeforeEnd,
 		})
 
 		if err != nil {
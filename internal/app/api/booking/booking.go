@@ -2,6 +2,7 @@ package booking
 
 import (
 	bookingRepo "booking-schedule/internal/app/repository/booking"
+	"booking-schedule/internal/app/service/action"
 	"booking-schedule/internal/app/service/booking"
 	"errors"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 
 type Implementation struct {
 	booking *booking.Service
+	action  action.Service
 	tracer  trace.Tracer
 }
 
@@ -18,12 +20,14 @@ var (
 	errNoBookingID = errors.New("received no booking id")
 	errNoInterval  = errors.New("received no time period")
 	errNoSuiteID   = errors.New("received no suite id")
+	errNoToken     = errors.New("received no action token")
 	//ErrBookingNotFound = errors.New("no booking with this id")
 )
 
-func NewImplementation(booking *booking.Service, tracer trace.Tracer) *Implementation {
+func NewImplementation(booking *booking.Service, action action.Service, tracer trace.Tracer) *Implementation {
 	return &Implementation{
 		booking: booking,
+		action:  action,
 		tracer:  tracer,
 	}
 }
@@ -36,8 +40,16 @@ func GetErrorCode(err error) int {
 		return http.StatusNotFound
 	case bookingRepo.ErrUnauthorized:
 		return http.StatusUnauthorized
+	case bookingRepo.ErrTokenUsed:
+		return http.StatusConflict
 	case booking.ErrNotAvailible:
 		return http.StatusNotFound
+	case booking.ErrTooManyReminders:
+		return http.StatusBadRequest
+	case action.ErrInvalidToken:
+		return http.StatusUnauthorized
+	case action.ErrUnknownAction:
+		return http.StatusBadRequest
 	default:
 		return http.StatusInternalServerError
 	}
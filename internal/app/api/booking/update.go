@@ -115,12 +115,13 @@ func (i *Implementation) UpdateBooking(logger *slog.Logger) http.HandlerFunc {
 		span.AddEvent("booking uuid decoded")
 		//TODO: getters
 		mod, err := convert.ToBookingInfo(&api.Booking{
-			BookingID: bookingUUID,
-			UserID:    userID,
-			SuiteID:   req.SuiteID,
-			StartDate: req.StartDate,
-			EndDate:   req.EndDate,
-			NotifyAt:  req.NotifyAt,
+			BookingID:       bookingUUID,
+			UserID:          userID,
+			SuiteID:         req.SuiteID,
+			StartDate:       req.StartDate,
+			EndDate:         req.EndDate,
+			NotifyAt:        req.NotifyAt,
+			NotifyBeforeEnd: req.NotifyBeforeEnd,
 		})
 		if err != nil {
 			span.RecordError(err)
@@ -1,17 +1,31 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	"booking-schedule/internal/app/message"
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/app/service/webhook"
+
 	"gopkg.in/guregu/null.v3"
 
 	validator "github.com/go-playground/validator/v10"
 	"github.com/gofrs/uuid"
 )
 
+const (
+	clockLayout = "15:04"
+	// MaxLeadTime limits how long before the booking start a reminder may be sent
+	MaxLeadTime = 30 * 24 * time.Hour
+)
+
 type Booking struct {
 	BookingID uuid.UUID
 	// Идентификатор пользователя
@@ -22,8 +36,39 @@ type Booking struct {
 	StartDate time.Time
 	// Дата и время окончания бронировании
 	EndDate time.Time
-	// Интервал времени для уведомления о бронировании
-	NotifyAt null.String
+	// Интервалы времени для уведомлений о бронировании
+	NotifyAt LeadTimes
+	// Интервалы времени для уведомлений об окончании бронирования
+	NotifyBeforeEnd LeadTimes
+}
+
+// LeadTimes is a list of intervals before the booking start to send reminders at.
+// A single interval may also be sent as a plain string, as it was before lists were supported.
+type LeadTimes []string
+
+func (l *LeadTimes) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*l = nil
+		return nil
+	}
+
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = LeadTimes{}
+		if single != "" {
+			*l = append(*l, single)
+		}
+		return nil
+	}
+
+	var list []string
+	err := json.Unmarshal(data, &list)
+	if err != nil {
+		return ErrInvalidLeadTime
+	}
+
+	*l = list
+	return nil
 }
 
 type AddBookingRequest struct {
@@ -33,8 +78,10 @@ type AddBookingRequest struct {
 	StartDate time.Time `json:"startDate" validate:"required" example:"2024-03-28T17:43:00Z"`
 	// Дата и время окончания бронировании
 	EndDate time.Time `json:"endDate" validate:"required" example:"2024-03-29T17:43:00Z"`
-	// Интервал времени для предварительного уведомления о бронировании
-	NotifyAt null.String `json:"notifyAt,omitempty" swaggertype:"primitive,string" example:"24h"`
+	// Интервалы времени для предварительных уведомлений о бронировании, строка или список строк
+	NotifyAt LeadTimes `json:"notifyAt,omitempty" swaggertype:"array,string" example:"24h,15m"`
+	// Интервалы времени до окончания бронирования для напоминаний о выезде, строка или список строк
+	NotifyBeforeEnd LeadTimes `json:"notifyBeforeEnd,omitempty" swaggertype:"array,string" example:"1h"`
 } //@name AddBookingRequest
 
 type AddBookingResponse struct {
@@ -50,12 +97,16 @@ type BookingInfo struct {
 	StartDate time.Time `json:"startDate" example:"2024-03-28T17:43:00Z"`
 	// Дата и время окончания бронировании
 	EndDate time.Time `json:"endDate" example:"2024-03-29T17:43:00Z"`
-	// Интервал времени для уведомления о бронировании
-	NotifyAt *string `json:"notifyAt,omitempty" example:"24h00m00s"`
+	// Интервалы времени для уведомлений о бронировании
+	NotifyAt []string `json:"notifyAt,omitempty" example:"24h0m0s,15m0s"`
+	// Интервалы времени до окончания бронирования для напоминаний о выезде
+	NotifyBeforeEnd []string `json:"notifyBeforeEnd,omitempty" example:"1h0m0s"`
 	// Дата и время создания
 	CreatedAt time.Time `json:"createdAt" example:"2024-03-27T17:43:00Z"`
 	// Дата и время обновления
 	UpdatedAt *time.Time `json:"updatedAt,omitempty" example:"2024-03-27T18:43:00Z"`
+	// Дата и время подтверждения того, что пользователь приедет
+	ConfirmedAt *time.Time `json:"confirmedAt,omitempty" example:"2024-03-28T09:00:00Z"`
 	// Идентификатор владельца бронирования
 	UserID int64 `json:"userID,omitempty" example:"1"`
 } //@name BookingInfo
@@ -68,6 +119,15 @@ type GetBookingsResponse struct {
 	BookingsInfo []*BookingInfo `json:"bookings"`
 } //@name GetBookingsResponse
 
+type BookingActionResponse struct {
+	// Выполненное действие: confirm, cancel или extend
+	Action string `json:"action" example:"extend"`
+	// Идентификатор бронирования, к которому применено действие
+	BookingID uuid.UUID `json:"bookingID" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	// Бронирование после выполнения действия, отсутствует для отмененного бронирования
+	BookingInfo *BookingInfo `json:"booking,omitempty"`
+} //@name BookingActionResponse
+
 type UpdateBookingRequest struct {
 	// Номер апаратаментов
 	SuiteID int64 `json:"suiteID" validate:"required" example:"1"`
@@ -75,8 +135,12 @@ type UpdateBookingRequest struct {
 	StartDate time.Time `json:"startDate" validate:"required" example:"2024-03-28T17:43:00Z"`
 	// Дата и время окончания бронировании
 	EndDate time.Time `json:"endDate" validate:"required" example:"2024-03-29T17:43:00Z"`
-	// Интервал времени для предварительного уведомления о бронировании
-	NotifyAt null.String `json:"notifyAt,omitempty" swaggertype:"primitive,string" example:"24h"`
+	// Интервалы времени для предварительных уведомлений о бронировании, строка или список строк.
+	// Если не переданы, уведомления не меняются, пустой список отключает их
+	NotifyAt LeadTimes `json:"notifyAt,omitempty" swaggertype:"array,string" example:"24h,15m"`
+	// Интервалы времени до окончания бронирования для напоминаний о выезде, строка или список строк.
+	// Если не переданы, уведомления не меняются, пустой список отключает их
+	NotifyBeforeEnd LeadTimes `json:"notifyBeforeEnd,omitempty" swaggertype:"array,string" example:"1h"`
 } //@name UpdateBookingRequest
 
 type Interval struct {
@@ -117,6 +181,10 @@ type SignUpRequest struct {
 	Name string `json:"name" validate:"required,notblank" example:"Pavel Durov"`
 	// Пароль
 	Password string `json:"password" validate:"required,notblank" example:"12345"`
+	// Язык уведомлений: ru или en, по умолчанию ru
+	Language string `json:"language,omitempty" validate:"omitempty,oneof=ru en" example:"en"`
+	// Часовой пояс из базы IANA для отображения времени в уведомлениях, по умолчанию UTC
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,timezone" example:"Europe/Moscow"`
 } //@name SignUpRequest
 
 type UserInfo struct {
@@ -128,6 +196,10 @@ type UserInfo struct {
 	Nickname string `json:"telegramNickname"`
 	// Имя пользователя
 	Name string `json:"name"`
+	// Язык уведомлений
+	Language string `json:"language"`
+	// Часовой пояс пользователя
+	Timezone string `json:"timezone"`
 	// Дата и время регистрации
 	CreatedAt time.Time `json:"createdAt"`
 	// Дата и время обновления профиля
@@ -148,8 +220,176 @@ type EditMyProfileRequest struct {
 	Nickname null.String `json:"telegramNickname" swaggertype:"primitive,string" validate:"notblank" example:"kolya_durov"`
 	// Пароль
 	Password null.String `json:"password" swaggertype:"primitive,string" validate:"notblank" example:"123456"`
+	// Язык уведомлений: ru или en
+	Language null.String `json:"language" swaggertype:"primitive,string" example:"ru"`
+	// Часовой пояс из базы IANA
+	Timezone null.String `json:"timezone" swaggertype:"primitive,string" example:"Asia/Yekaterinburg"`
 } // @name EditMyProfileRequest
 
+type QuietHours struct {
+	// Начало тихих часов в часовом поясе пользователя
+	Start string `json:"start" validate:"required" example:"22:00"`
+	// Окончание тихих часов в часовом поясе пользователя
+	End string `json:"end" validate:"required" example:"08:00"`
+} //@name QuietHours
+
+type Preferences struct {
+	// Интервалы до начала бронирования для напоминаний, если у бронирования не задан notifyAt
+	LeadTimes []string `json:"leadTimes" example:"24h,1h"`
+	// Интервалы до окончания бронирования для напоминаний о выезде, если у бронирования не задан notifyBeforeEnd
+	EndLeadTimes []string `json:"endLeadTimes" example:"1h"`
+	// Тихие часы, в которые несрочные уведомления откладываются до их окончания
+	QuietHours *QuietHours `json:"quietHours,omitempty"`
+	// Типы уведомлений, от которых пользователь отказался
+	DisabledTypes []string `json:"disabledTypes" example:"booking.reminder"`
+	// Время ежедневной сводки бронирований в часовом поясе пользователя, отсутствует, если сводка отключена
+	DigestTime *string `json:"digestTime,omitempty" example:"08:00"`
+} //@name Preferences
+
+type GetPreferencesResponse struct {
+	Preferences *Preferences `json:"preferences"`
+} //@name GetPreferencesResponse
+
+type SetPreferencesRequest struct {
+	// Интервалы до начала бронирования для напоминаний, если у бронирования не задан notifyAt
+	LeadTimes []string `json:"leadTimes" validate:"max=5" example:"24h,1h"`
+	// Интервалы до окончания бронирования для напоминаний о выезде, если у бронирования не задан notifyBeforeEnd
+	EndLeadTimes []string `json:"endLeadTimes" validate:"max=5" example:"1h"`
+	// Тихие часы, в которые несрочные уведомления откладываются до их окончания
+	QuietHours *QuietHours `json:"quietHours,omitempty"`
+	// Типы уведомлений, от которых пользователь отказался
+	DisabledTypes []string `json:"disabledTypes" example:"booking.reminder"`
+	// Время ежедневной сводки бронирований в часовом поясе пользователя, если не задано, сводка не отправляется
+	DigestTime null.String `json:"digestTime,omitempty" swaggertype:"primitive,string" example:"08:00"`
+} //@name SetPreferencesRequest
+
+type AddWebhookRequest struct {
+	// Адрес, на который отправляются события
+	URL string `json:"url" validate:"required,url" example:"https://example.com/hooks/bookings"`
+	// События, на которые подписан вебхук: booking.created, booking.updated, booking.cancelled
+	Events []string `json:"events" validate:"required,min=1" example:"booking.created,booking.cancelled"`
+	// Получать события обо всех бронированиях, доступно только администраторам
+	AllBookings bool `json:"allBookings,omitempty" example:"false"`
+	// Ключ подписи доставок, если не задан, генерируется автоматически
+	Secret string `json:"secret,omitempty" validate:"omitempty,min=16" example:"verysecretivewebhook"`
+} //@name AddWebhookRequest
+
+type Webhook struct {
+	// Идентификатор вебхука
+	ID int64 `json:"id" example:"1"`
+	// Адрес, на который отправляются события
+	URL string `json:"url" example:"https://example.com/hooks/bookings"`
+	// События, на которые подписан вебхук
+	Events []string `json:"events" example:"booking.created,booking.cancelled"`
+	// Получать события обо всех бронированиях
+	AllBookings bool `json:"allBookings" example:"false"`
+	// Дата и время создания
+	CreatedAt time.Time `json:"createdAt" example:"2024-03-27T17:43:00Z"`
+} //@name Webhook
+
+type AddWebhookResponse struct {
+	Webhook *Webhook `json:"webhook"`
+	// Ключ подписи доставок, возвращается только при создании вебхука
+	Secret string `json:"secret" example:"verysecretivewebhook"`
+} //@name AddWebhookResponse
+
+type GetWebhooksResponse struct {
+	Webhooks []*Webhook `json:"webhooks"`
+} //@name GetWebhooksResponse
+
+type WebhookDelivery struct {
+	// Идентификатор доставки, передается в заголовке X-Webhook-Delivery
+	ID int64 `json:"id" example:"1"`
+	// Событие
+	Event string `json:"event" example:"booking.created"`
+	// Тело запроса, отправляемое получателю
+	Payload json.RawMessage `json:"payload" swaggertype:"object"`
+	// Состояние доставки: pending, delivered или failed
+	Status string `json:"status" example:"delivered"`
+	// Количество сделанных попыток
+	Attempts int `json:"attempts" example:"1"`
+	// Дата и время следующей попытки, только для ожидающих доставки
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty" example:"2024-03-27T17:44:00Z"`
+	// HTTP статус последнего ответа получателя
+	ResponseStatus *int64 `json:"responseStatus,omitempty" example:"200"`
+	// Ошибка последней попытки
+	Error *string `json:"error,omitempty" example:"unexpected response status 502"`
+	// Дата и время создания
+	CreatedAt time.Time `json:"createdAt" example:"2024-03-27T17:43:00Z"`
+	// Дата и время успешной доставки
+	DeliveredAt *time.Time `json:"deliveredAt,omitempty" example:"2024-03-27T17:43:01Z"`
+} //@name WebhookDelivery
+
+type GetDeliveriesResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+} //@name GetDeliveriesResponse
+
+type ReplayDeliveryResponse struct {
+	// Идентификатор новой доставки
+	DeliveryID int64 `json:"deliveryID" example:"2"`
+} //@name ReplayDeliveryResponse
+
+type Job struct {
+	// Название задачи
+	Name string `json:"name" example:"reminders"`
+	// Расписание: интервал вида "@every 1m" или cron-выражение
+	Schedule string `json:"schedule" example:"@every 1m"`
+	// Время, после которого запуск прерывается
+	Timeout string `json:"timeout" example:"50s"`
+	// Задача выполняется сейчас
+	Running bool `json:"running" example:"false"`
+	// Дата и время следующего запуска по расписанию
+	NextRunAt *time.Time `json:"nextRunAt,omitempty" example:"2024-03-27T17:44:00Z"`
+	// Последний запуск, отсутствует, если задача еще не запускалась
+	LastRun *JobRun `json:"lastRun,omitempty"`
+	// Количество запусков с момента старта планировщика
+	Runs int64 `json:"runs" example:"42"`
+	// Количество неудачных запусков с момента старта планировщика
+	Failures int64 `json:"failures" example:"0"`
+} //@name Job
+
+type JobRun struct {
+	// Причина запуска: schedule или manual
+	Trigger string `json:"trigger" example:"schedule"`
+	// Дата и время начала
+	StartedAt time.Time `json:"startedAt" example:"2024-03-27T17:43:00Z"`
+	// Дата и время завершения, отсутствует, пока запуск не завершен
+	FinishedAt *time.Time `json:"finishedAt,omitempty" example:"2024-03-27T17:43:01Z"`
+	// Длительность
+	Duration string `json:"duration,omitempty" example:"1.2s"`
+	// Ошибка, отсутствует, если запуск завершился успешно
+	Error *string `json:"error,omitempty" example:"failed to send some of the notifications: 1 failed"`
+} //@name JobRun
+
+type GetJobsResponse struct {
+	Jobs []*Job `json:"jobs"`
+} //@name GetJobsResponse
+
+type ArchivedBooking struct {
+	// Уникальный идентификатор бронирования
+	ID uuid.UUID `json:"BookingID" example:"550e8400-e29b-41d4-a716-446655440000" format:"uuid"`
+	// Номер апартаментов
+	SuiteID int64 `json:"suiteID" example:"1"`
+	// Идентификатор владельца бронирования
+	UserID int64 `json:"userID" example:"1"`
+	// Дата и время начала бронирования
+	StartDate time.Time `json:"startDate" example:"2023-03-28T17:43:00Z"`
+	// Дата и время окончания бронирования
+	EndDate time.Time `json:"endDate" example:"2023-03-29T17:43:00Z"`
+	// Дата и время создания
+	CreatedAt time.Time `json:"createdAt" example:"2023-03-27T17:43:00Z"`
+	// Дата и время обновления
+	UpdatedAt *time.Time `json:"updatedAt,omitempty" example:"2023-03-27T18:43:00Z"`
+	// Дата и время подтверждения того, что пользователь приедет
+	ConfirmedAt *time.Time `json:"confirmedAt,omitempty" example:"2023-03-28T09:00:00Z"`
+	// Дата и время переноса в архив
+	ArchivedAt time.Time `json:"archivedAt" example:"2024-03-30T03:00:00Z"`
+} //@name ArchivedBooking
+
+type GetArchivedBookingsResponse struct {
+	Bookings []*ArchivedBooking `json:"bookings"`
+} //@name GetArchivedBookingsResponse
+
 func (arq *AddBookingRequest) Bind(req *http.Request) error {
 	err := validator.New().Struct(arq)
 	if err != nil {
@@ -199,7 +439,7 @@ func (empr *EditMyProfileRequest) Bind(req *http.Request) error {
 		return ErrIncompleteRequest
 	}
 
-	return nil
+	return CheckLocale(empr.Language, empr.Timezone)
 }
 
 func NotBlank(fl validator.FieldLevel) bool {
@@ -230,3 +470,95 @@ func CheckDates(start time.Time, end time.Time) error {
 
 	return nil
 }
+
+func (spr *SetPreferencesRequest) Bind(req *http.Request) error {
+	err := validator.New().Struct(spr)
+	if err != nil {
+		return err
+	}
+
+	for _, leadTimes := range [][]string{spr.LeadTimes, spr.EndLeadTimes} {
+		for _, leadTime := range leadTimes {
+			dur, err := time.ParseDuration(leadTime)
+			if err != nil || dur <= 0 || dur > MaxLeadTime {
+				return ErrInvalidLeadTime
+			}
+		}
+	}
+
+	if spr.DigestTime.Valid {
+		_, err = time.Parse(clockLayout, spr.DigestTime.String)
+		if err != nil {
+			return ErrInvalidDigestTime
+		}
+	}
+
+	if spr.QuietHours != nil {
+		_, err = time.Parse(clockLayout, spr.QuietHours.Start)
+		if err != nil {
+			return ErrInvalidQuietHours
+		}
+
+		_, err = time.Parse(clockLayout, spr.QuietHours.End)
+		if err != nil || spr.QuietHours.Start == spr.QuietHours.End {
+			return ErrInvalidQuietHours
+		}
+	}
+
+	for _, msgType := range spr.DisabledTypes {
+		if !slices.Contains(message.OptionalTypes, msgType) {
+			return ErrUnknownNotificationType
+		}
+	}
+
+	return nil
+}
+
+func (awr *AddWebhookRequest) Bind(req *http.Request) error {
+	err := validator.New().Struct(awr)
+	if err != nil {
+		return err
+	}
+
+	u, err := url.Parse(awr.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	// адреса, в которые разрешается имя хоста, проверяются при каждой доставке, здесь отсекаются очевидные
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrInvalidWebhookURL
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !webhook.IsPublicAddr(addr) {
+		return ErrInvalidWebhookURL
+	}
+
+	for _, event := range awr.Events {
+		if !slices.Contains(model.WebhookEvents, event) {
+			return ErrUnknownWebhookEvent
+		}
+	}
+
+	return nil
+}
+
+// CheckLocale validates the notification language and time zone if they are set.
+func CheckLocale(language null.String, timezone null.String) error {
+	if language.Valid && language.String != "ru" && language.String != "en" {
+		return ErrUnsupportedLanguage
+	}
+
+	if timezone.Valid {
+		if strings.TrimSpace(timezone.String) == "" || timezone.String == "Local" {
+			return ErrInvalidTimezone
+		}
+
+		_, err := time.LoadLocation(timezone.String)
+		if err != nil {
+			return ErrInvalidTimezone
+		}
+	}
+
+	return nil
+}
@@ -17,17 +17,25 @@ type errResponse struct {
 } //@name Error
 
 var (
-	ErrBadRequest         = errors.New("bad request")
-	ErrNoAuth             = errors.New("received no auth info")
-	ErrEmptyRequest       = errors.New("received empty request")
-	ErrParse              = errors.New("failed to parse parameter")
-	ErrAuthFailed         = errors.New("failed to authenticate")
-	ErrInvalidDateFormat  = errors.New("received invalid date")
-	ErrInvalidInterval    = errors.New("end date is beforehand the start date or matches it")
-	ErrExpiredDate        = errors.New("date is expired")
-	ErrIncompleteInterval = errors.New("received no start date or no end date")
-	ErrNoUserID           = errors.New("received no user id")
-	ErrIncompleteRequest  = errors.New("in case telegram account is changed, both id and nickname should be set")
+	ErrBadRequest              = errors.New("bad request")
+	ErrNoAuth                  = errors.New("received no auth info")
+	ErrEmptyRequest            = errors.New("received empty request")
+	ErrParse                   = errors.New("failed to parse parameter")
+	ErrAuthFailed              = errors.New("failed to authenticate")
+	ErrInvalidDateFormat       = errors.New("received invalid date")
+	ErrInvalidInterval         = errors.New("end date is beforehand the start date or matches it")
+	ErrExpiredDate             = errors.New("date is expired")
+	ErrIncompleteInterval      = errors.New("received no start date or no end date")
+	ErrNoUserID                = errors.New("received no user id")
+	ErrIncompleteRequest       = errors.New("in case telegram account is changed, both id and nickname should be set")
+	ErrUnsupportedLanguage     = errors.New("unsupported language, expected ru or en")
+	ErrInvalidTimezone         = errors.New("unknown time zone, expected IANA name like Europe/Moscow")
+	ErrInvalidLeadTime         = errors.New("lead time should be a positive duration up to 720h, e.g. 24h")
+	ErrInvalidQuietHours       = errors.New("quiet hours should be set as distinct start and end in 15:04 format")
+	ErrInvalidDigestTime       = errors.New("digest time should be set in 15:04 format")
+	ErrUnknownNotificationType = errors.New("unknown notification type")
+	ErrInvalidWebhookURL       = errors.New("webhook url should be an absolute http or https url of a public host")
+	ErrUnknownWebhookEvent     = errors.New("unknown webhook event, expected booking.created, booking.updated or booking.cancelled")
 
 	ValidateErr = new(validator.ValidationErrors)
 )
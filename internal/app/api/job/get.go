@@ -0,0 +1,68 @@
+package job
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/app/convert"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/middleware/auth"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetJobs godoc
+//
+//	@Summary		Lists scheduler jobs
+//	@Description	Responds with the jobs of the scheduler, their schedules and latest runs. Available to admins only.
+//	@ID				getJobs
+//	@Tags			jobs
+//	@Produce		json
+//
+//	@Success		200	{object}	api.GetJobsResponse
+//	@Failure		401	{object}	api.errResponse
+//	@Failure		403	{object}	api.errResponse
+//	@Failure		503	{object}	api.errResponse
+//	@Router			/jobs [get]
+//
+// @Security Bearer
+func (i *Implementation) GetJobs(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.job.GetJobs"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		userID := auth.UserIDFromContext(ctx)
+		if userID == 0 {
+			span.RecordError(api.ErrNoUserID)
+			span.SetStatus(codes.Error, api.ErrNoUserID.Error())
+			log.Error("no user id in context", sl.Err(api.ErrNoUserID))
+			api.WriteWithError(w, http.StatusUnauthorized, api.ErrNoAuth.Error())
+			return
+		}
+
+		statuses, err := i.job.GetJobs(ctx, userID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to get jobs", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("jobs acquired", trace.WithAttributes(attribute.Int("quantity", len(statuses))))
+
+		api.WriteWithStatus(w, http.StatusOK, api.GetJobsResponse{Jobs: convert.ToApiJobs(statuses)})
+	}
+}
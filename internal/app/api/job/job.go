@@ -0,0 +1,44 @@
+package job
+
+import (
+	userRepo "booking-schedule/internal/app/repository/user"
+	"booking-schedule/internal/app/service/job"
+	"booking-schedule/internal/pkg/jobs"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Implementation struct {
+	job    *job.Service
+	tracer trace.Tracer
+}
+
+var errNoJobName = errors.New("received no job name")
+
+func NewImplementation(job *job.Service, tracer trace.Tracer) *Implementation {
+	return &Implementation{
+		job:    job,
+		tracer: tracer,
+	}
+}
+
+func GetErrorCode(err error) int {
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, userRepo.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, job.ErrNotAdmin):
+		return http.StatusForbidden
+	case errors.Is(err, jobs.ErrJobRunning):
+		return http.StatusConflict
+	case errors.Is(err, jobs.ErrNotStarted):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, userRepo.ErrNoConnection):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,81 @@
+package job
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/middleware/auth"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TriggerJob godoc
+//
+//	@Summary		Runs a scheduler job
+//	@Description	Starts the job out of its schedule and responds without waiting for it to finish, the outcome is shown in the list of jobs. A job that is already running is not started again. Available to admins only.
+//	@ID				triggerJob
+//	@Tags			jobs
+//	@Produce		json
+//
+//	@Param			job_name path	string	true	"job_name"	Enums(reminders, digests, cleanup, partitions)
+//	@Success		202
+//	@Failure		400	{object}	api.errResponse
+//	@Failure		401	{object}	api.errResponse
+//	@Failure		403	{object}	api.errResponse
+//	@Failure		404	{object}	api.errResponse
+//	@Failure		409	{object}	api.errResponse
+//	@Failure		503	{object}	api.errResponse
+//	@Router			/jobs/{job_name}/run [post]
+//
+// @Security Bearer
+func (i *Implementation) TriggerJob(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.job.TriggerJob"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		userID := auth.UserIDFromContext(ctx)
+		if userID == 0 {
+			span.RecordError(api.ErrNoUserID)
+			span.SetStatus(codes.Error, api.ErrNoUserID.Error())
+			log.Error("no user id in context", sl.Err(api.ErrNoUserID))
+			api.WriteWithError(w, http.StatusUnauthorized, api.ErrNoAuth.Error())
+			return
+		}
+
+		name := chi.URLParam(r, "job_name")
+		if name == "" {
+			span.RecordError(errNoJobName)
+			span.SetStatus(codes.Error, errNoJobName.Error())
+			log.Error("invalid request", sl.Err(errNoJobName))
+			api.WriteWithError(w, http.StatusBadRequest, errNoJobName.Error())
+			return
+		}
+
+		err := i.job.TriggerJob(ctx, userID, name)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to trigger job", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("job triggered")
+
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
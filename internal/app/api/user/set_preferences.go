@@ -0,0 +1,106 @@
+package user
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/app/convert"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/middleware/auth"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	validator "github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SetMyPreferences godoc
+//
+//	@Summary		Set notification preferences
+//	@Description	Replaces notification preferences of signed in user. Lead times are used for bookings without their own notifyAt, up to 5 positive durations not longer than 720h. Reminders due in quiet hours are deferred till they end unless the booking starts earlier. Quiet hours are set in the time zone of the user profile. The daily digest of bookings is opt-in and is sent at digestTime in the time zone of the user profile.
+//	@ID				setMyPreferences
+//	@Tags			users
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param          preferences body		api.SetPreferencesRequest	true	"SetPreferencesRequest"
+//	@Success		200
+//	@Failure		400	{object}	api.errResponse
+//	@Failure		401	{object}	api.errResponse
+//	@Failure		404	{object}	api.errResponse
+//	@Failure		503	{object}	api.errResponse
+//	@Router			/user/preferences [put]
+//
+// @Security Bearer
+func (i *Implementation) SetMyPreferences(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.user.SetMyPreferences"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		userID := auth.UserIDFromContext(ctx)
+		if userID == 0 {
+			span.RecordError(api.ErrNoUserID)
+			span.SetStatus(codes.Error, api.ErrNoUserID.Error())
+			log.Error("no user id in context", sl.Err(api.ErrNoUserID))
+			api.WriteWithError(w, http.StatusUnauthorized, api.ErrNoAuth.Error())
+			return
+		}
+
+		req := &api.SetPreferencesRequest{}
+		err := render.Bind(r, req)
+		if err != nil {
+			if errors.As(err, api.ValidateErr) {
+				validateErr := err.(validator.ValidationErrors)
+				span.RecordError(validateErr)
+				span.SetStatus(codes.Error, err.Error())
+				log.Error("some of the values were not valid", sl.Err(validateErr))
+				api.WriteValidationError(w, validateErr)
+				return
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to decode request body", sl.Err(err))
+			api.WriteWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		span.AddEvent("request body decoded")
+
+		mod, err := convert.ToPreferences(req, userID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("invalid request", sl.Err(err))
+			api.WriteWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		span.AddEvent("converted to preferences model")
+
+		err = i.preferences.SetPreferences(ctx, mod)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to set preferences", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("preferences updated")
+		log.Info("preferences updated", slog.Int64("id", userID))
+
+		api.WriteWithStatus(w, http.StatusOK, nil)
+	}
+}
@@ -1,7 +1,9 @@
 package user
 
 import (
+	preferencesRepo "booking-schedule/internal/app/repository/preferences"
 	userRepo "booking-schedule/internal/app/repository/user"
+	"booking-schedule/internal/app/service/preferences"
 	"booking-schedule/internal/app/service/user"
 	"net/http"
 
@@ -9,14 +11,16 @@ import (
 )
 
 type Implementation struct {
-	user   *user.Service
-	tracer trace.Tracer
+	user        *user.Service
+	preferences *preferences.Service
+	tracer      trace.Tracer
 }
 
-func NewImplementation(user *user.Service, tracer trace.Tracer) *Implementation {
+func NewImplementation(user *user.Service, preferences *preferences.Service, tracer trace.Tracer) *Implementation {
 	return &Implementation{
-		user:   user,
-		tracer: tracer,
+		user:        user,
+		preferences: preferences,
+		tracer:      tracer,
 	}
 }
 
@@ -30,6 +34,8 @@ func GetErrorCode(err error) int {
 		return http.StatusBadRequest
 	case userRepo.ErrDuplicate:
 		return http.StatusUnauthorized
+	case preferencesRepo.ErrNoSuchUser:
+		return http.StatusNotFound
 	default:
 		return http.StatusInternalServerError
 	}
@@ -0,0 +1,107 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/app/convert"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/middleware/auth"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/render"
+	validator "github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AddWebhook godoc
+//
+//	@Summary		Registers a webhook
+//	@Description	Registers an endpoint booking.created, booking.updated and booking.cancelled events about bookings of signed in user are posted to. Admins may subscribe to events of all bookings. Deliveries are signed with HMAC-SHA256 of the X-Webhook-Timestamp header value, a dot and the body, sent as X-Webhook-Signature. The secret is generated unless given and is only returned in this response. Failed deliveries are retried with exponential backoff.
+//	@ID				addWebhook
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//
+//	@Param          webhook body		api.AddWebhookRequest	true	"AddWebhookRequest"
+//	@Success		200	{object}	api.AddWebhookResponse
+//	@Failure		400	{object}	api.errResponse
+//	@Failure		401	{object}	api.errResponse
+//	@Failure		403	{object}	api.errResponse
+//	@Failure		503	{object}	api.errResponse
+//	@Router			/webhooks [post]
+//
+// @Security Bearer
+func (i *Implementation) AddWebhook(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.webhook.AddWebhook"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		userID := auth.UserIDFromContext(ctx)
+		if userID == 0 {
+			span.RecordError(api.ErrNoUserID)
+			span.SetStatus(codes.Error, api.ErrNoUserID.Error())
+			log.Error("no user id in context", sl.Err(api.ErrNoUserID))
+			api.WriteWithError(w, http.StatusUnauthorized, api.ErrNoAuth.Error())
+			return
+		}
+
+		req := &api.AddWebhookRequest{}
+		err := render.Bind(r, req)
+		if err != nil {
+			if errors.As(err, api.ValidateErr) {
+				validateErr := err.(validator.ValidationErrors)
+				span.RecordError(validateErr)
+				span.SetStatus(codes.Error, err.Error())
+				log.Error("some of the values were not valid", sl.Err(validateErr))
+				api.WriteValidationError(w, validateErr)
+				return
+			}
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to decode request body", sl.Err(err))
+			api.WriteWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		span.AddEvent("request body decoded")
+
+		mod, err := convert.ToWebhook(req, userID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("invalid request", sl.Err(err))
+			api.WriteWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		mod, err = i.webhook.AddWebhook(ctx, mod)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to add webhook", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("webhook added", trace.WithAttributes(attribute.Int64("id", mod.ID)))
+		log.Info("webhook added", slog.Int64("id", mod.ID))
+
+		api.WriteWithStatus(w, http.StatusOK, api.AddWebhookResponse{
+			Webhook: convert.ToApiWebhook(mod),
+			Secret:  mod.Secret,
+		})
+	}
+}
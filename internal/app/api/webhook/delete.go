@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/middleware/auth"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DeleteWebhook godoc
+//
+//	@Summary		Deletes a webhook
+//	@Description	Deletes the webhook of signed in user together with its delivery log, pending deliveries are dropped.
+//	@ID				deleteWebhook
+//	@Tags			webhooks
+//	@Produce		json
+//
+//	@Param			webhook_id path	int	true	"webhook_id"
+//	@Success		200
+//	@Failure		400	{object}	api.errResponse
+//	@Failure		401	{object}	api.errResponse
+//	@Failure		404	{object}	api.errResponse
+//	@Failure		503	{object}	api.errResponse
+//	@Router			/webhooks/{webhook_id} [delete]
+//
+// @Security Bearer
+func (i *Implementation) DeleteWebhook(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.webhook.DeleteWebhook"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		userID := auth.UserIDFromContext(ctx)
+		if userID == 0 {
+			span.RecordError(api.ErrNoUserID)
+			span.SetStatus(codes.Error, api.ErrNoUserID.Error())
+			log.Error("no user id in context", sl.Err(api.ErrNoUserID))
+			api.WriteWithError(w, http.StatusUnauthorized, api.ErrNoAuth.Error())
+			return
+		}
+
+		webhookID, err := pathID(r, "webhook_id", errNoWebhookID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("invalid request", sl.Err(err))
+			api.WriteWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		err = i.webhook.DeleteWebhook(ctx, webhookID, userID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to delete webhook", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("webhook deleted")
+		log.Info("webhook deleted", slog.Int64("id", webhookID))
+
+		api.WriteWithStatus(w, http.StatusOK, nil)
+	}
+}
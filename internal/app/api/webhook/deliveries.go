@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/app/convert"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/middleware/auth"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetDeliveries godoc
+//
+//	@Summary		Lists deliveries of a webhook
+//	@Description	Responds with the delivery log of the webhook of signed in user, up to 100 latest deliveries, newest first.
+//	@ID				getWebhookDeliveries
+//	@Tags			webhooks
+//	@Produce		json
+//
+//	@Param			webhook_id path	int	true	"webhook_id"
+//	@Success		200	{object}	api.GetDeliveriesResponse
+//	@Failure		400	{object}	api.errResponse
+//	@Failure		401	{object}	api.errResponse
+//	@Failure		404	{object}	api.errResponse
+//	@Failure		503	{object}	api.errResponse
+//	@Router			/webhooks/{webhook_id}/deliveries [get]
+//
+// @Security Bearer
+func (i *Implementation) GetDeliveries(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.webhook.GetDeliveries"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		userID := auth.UserIDFromContext(ctx)
+		if userID == 0 {
+			span.RecordError(api.ErrNoUserID)
+			span.SetStatus(codes.Error, api.ErrNoUserID.Error())
+			log.Error("no user id in context", sl.Err(api.ErrNoUserID))
+			api.WriteWithError(w, http.StatusUnauthorized, api.ErrNoAuth.Error())
+			return
+		}
+
+		webhookID, err := pathID(r, "webhook_id", errNoWebhookID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("invalid request", sl.Err(err))
+			api.WriteWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		deliveries, err := i.webhook.GetDeliveries(ctx, webhookID, userID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to get deliveries", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("deliveries acquired", trace.WithAttributes(attribute.Int("quantity", len(deliveries))))
+
+		api.WriteWithStatus(w, http.StatusOK, api.GetDeliveriesResponse{Deliveries: convert.ToApiWebhookDeliveries(deliveries)})
+	}
+}
+
+// ReplayDelivery godoc
+//
+//	@Summary		Replays a delivery
+//	@Description	Enqueues the payload of a delivery of the webhook of signed in user once again, e.g. after the receiver was fixed. The replay is a new delivery with its own id and attempts.
+//	@ID				replayWebhookDelivery
+//	@Tags			webhooks
+//	@Produce		json
+//
+//	@Param			webhook_id path	int	true	"webhook_id"
+//	@Param			delivery_id path	int	true	"delivery_id"
+//	@Success		200	{object}	api.ReplayDeliveryResponse
+//	@Failure		400	{object}	api.errResponse
+//	@Failure		401	{object}	api.errResponse
+//	@Failure		404	{object}	api.errResponse
+//	@Failure		503	{object}	api.errResponse
+//	@Router			/webhooks/{webhook_id}/deliveries/{delivery_id}/replay [post]
+//
+// @Security Bearer
+func (i *Implementation) ReplayDelivery(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.webhook.ReplayDelivery"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		userID := auth.UserIDFromContext(ctx)
+		if userID == 0 {
+			span.RecordError(api.ErrNoUserID)
+			span.SetStatus(codes.Error, api.ErrNoUserID.Error())
+			log.Error("no user id in context", sl.Err(api.ErrNoUserID))
+			api.WriteWithError(w, http.StatusUnauthorized, api.ErrNoAuth.Error())
+			return
+		}
+
+		webhookID, err := pathID(r, "webhook_id", errNoWebhookID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("invalid request", sl.Err(err))
+			api.WriteWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		deliveryID, err := pathID(r, "delivery_id", errNoDeliveryID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("invalid request", sl.Err(err))
+			api.WriteWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		id, err := i.webhook.ReplayDelivery(ctx, webhookID, deliveryID, userID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to replay delivery", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("delivery replayed", trace.WithAttributes(attribute.Int64("id", id)))
+		log.Info("delivery replayed", slog.Int64("delivery_id", deliveryID), slog.Int64("id", id))
+
+		api.WriteWithStatus(w, http.StatusOK, api.ReplayDeliveryResponse{DeliveryID: id})
+	}
+}
+
+// pathID parses a positive id from the URL param, errNoID is returned if there is none.
+func pathID(r *http.Request, param string, errNoID error) (int64, error) {
+	value := chi.URLParam(r, param)
+	if value == "" {
+		return 0, errNoID
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, api.ErrParse
+	}
+
+	if id <= 0 {
+		return 0, errNoID
+	}
+
+	return id, nil
+}
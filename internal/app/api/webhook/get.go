@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/app/convert"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/middleware/auth"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetWebhooks godoc
+//
+//	@Summary		Lists webhooks
+//	@Description	Responds with the webhooks registered by signed in user, secrets are not included.
+//	@ID				getWebhooks
+//	@Tags			webhooks
+//	@Produce		json
+//
+//	@Success		200	{object}	api.GetWebhooksResponse
+//	@Failure		401	{object}	api.errResponse
+//	@Failure		503	{object}	api.errResponse
+//	@Router			/webhooks [get]
+//
+// @Security Bearer
+func (i *Implementation) GetWebhooks(logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "api.webhook.GetWebhooks"
+
+		ctx := r.Context()
+		requestID := middleware.GetReqID(ctx)
+
+		log := logger.With(
+			slog.String("op", op),
+			slog.String("request_id", requestID),
+		)
+		ctx, span := i.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+		defer span.End()
+
+		userID := auth.UserIDFromContext(ctx)
+		if userID == 0 {
+			span.RecordError(api.ErrNoUserID)
+			span.SetStatus(codes.Error, api.ErrNoUserID.Error())
+			log.Error("no user id in context", sl.Err(api.ErrNoUserID))
+			api.WriteWithError(w, http.StatusUnauthorized, api.ErrNoAuth.Error())
+			return
+		}
+
+		webhooks, err := i.webhook.GetWebhooks(ctx, userID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to get webhooks", sl.Err(err))
+			api.WriteWithError(w, GetErrorCode(err), err.Error())
+			return
+		}
+
+		span.AddEvent("webhooks acquired", trace.WithAttributes(attribute.Int("quantity", len(webhooks))))
+
+		api.WriteWithStatus(w, http.StatusOK, api.GetWebhooksResponse{Webhooks: convert.ToApiWebhooks(webhooks)})
+	}
+}
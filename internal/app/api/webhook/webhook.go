@@ -0,0 +1,45 @@
+package webhook
+
+import (
+	userRepo "booking-schedule/internal/app/repository/user"
+	webhookRepo "booking-schedule/internal/app/repository/webhook"
+	"booking-schedule/internal/app/service/webhook"
+	"errors"
+	"net/http"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Implementation struct {
+	webhook *webhook.Service
+	tracer  trace.Tracer
+}
+
+var (
+	errNoWebhookID  = errors.New("received no webhook id")
+	errNoDeliveryID = errors.New("received no delivery id")
+)
+
+func NewImplementation(webhook *webhook.Service, tracer trace.Tracer) *Implementation {
+	return &Implementation{
+		webhook: webhook,
+		tracer:  tracer,
+	}
+}
+
+func GetErrorCode(err error) int {
+	switch err {
+	case webhookRepo.ErrNotFound:
+		return http.StatusNotFound
+	case userRepo.ErrNotFound:
+		return http.StatusNotFound
+	case webhook.ErrUnknownEvent:
+		return http.StatusBadRequest
+	case webhook.ErrNotAdmin:
+		return http.StatusForbidden
+	case webhookRepo.ErrNoConnection:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -3,7 +3,11 @@ package convert
 import (
 	"booking-schedule/internal/app/api"
 	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/pkg/jobs"
+	"slices"
 	"time"
+
+	"gopkg.in/guregu/null.v3"
 )
 
 func ToBookingInfo(req *api.Booking) (*model.BookingInfo, error) {
@@ -19,12 +23,38 @@ func ToBookingInfo(req *api.Booking) (*model.BookingInfo, error) {
 		EndDate:   req.EndDate,
 	}
 
-	if req.NotifyAt.Valid {
-		dur, err := time.ParseDuration(req.NotifyAt.String)
+	var err error
+	res.NotifyAt, err = toLeadTimes(req.NotifyAt)
+	if err != nil {
+		return nil, err
+	}
+
+	res.NotifyBeforeEnd, err = toLeadTimes(req.NotifyBeforeEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// toLeadTimes parses and deduplicates lead times, keeping nil apart from an empty list.
+func toLeadTimes(leadTimes api.LeadTimes) ([]time.Duration, error) {
+	if leadTimes == nil {
+		return nil, nil
+	}
+
+	res := make([]time.Duration, 0, len(leadTimes))
+	for _, leadTime := range leadTimes {
+		dur, err := time.ParseDuration(leadTime)
 		if err != nil {
 			return nil, err
 		}
-		res.NotifyAt = dur
+		if dur <= 0 || dur > api.MaxLeadTime {
+			return nil, api.ErrInvalidLeadTime
+		}
+		if !slices.Contains(res, dur) {
+			res = append(res, dur)
+		}
 	}
 
 	return res, nil
@@ -41,15 +71,22 @@ func ToApiBookingInfo(mod *model.BookingInfo) *api.BookingInfo {
 		UserID:    mod.UserID,
 	}
 
-	if mod.NotifyAt != 0 {
-		notifyAt := mod.NotifyAt.String()
-		res.NotifyAt = &notifyAt
+	for _, leadTime := range mod.NotifyAt {
+		res.NotifyAt = append(res.NotifyAt, leadTime.String())
+	}
+
+	for _, leadTime := range mod.NotifyBeforeEnd {
+		res.NotifyBeforeEnd = append(res.NotifyBeforeEnd, leadTime.String())
 	}
 
 	if mod.UpdatedAt.Valid {
 		res.UpdatedAt = &mod.UpdatedAt.Time
 	}
 
+	if mod.ConfirmedAt.Valid {
+		res.ConfirmedAt = &mod.ConfirmedAt.Time
+	}
+
 	return res
 }
 
@@ -148,6 +185,8 @@ func ToUserInfo(user *api.SignUpRequest) (*model.User, error) {
 		Nickname:   user.Nickname,
 		Name:       user.Name,
 		Password:   user.Password,
+		Language:   user.Language,
+		Timezone:   user.Timezone,
 		CreatedAt:  time.Now(),
 	}
 	return mod, nil
@@ -159,6 +198,8 @@ func ToApiUserInfo(user *model.User) *api.UserInfo {
 		TelegramID: user.TelegramID,
 		Nickname:   user.Nickname,
 		Name:       user.Name,
+		Language:   user.Language,
+		Timezone:   user.Timezone,
 		CreatedAt:  time.Now(),
 	}
 
@@ -176,7 +217,220 @@ func ToUpdateUserInfo(user *api.EditMyProfileRequest, userID int64) *model.Updat
 		Nickname:   user.Nickname,
 		Name:       user.Name,
 		Password:   user.Password,
+		Language:   user.Language,
+		Timezone:   user.Timezone,
 	}
 
 	return mod
 }
+
+func ToPreferences(req *api.SetPreferencesRequest, userID int64) (*model.Preferences, error) {
+	if req == nil {
+		return nil, api.ErrEmptyRequest
+	}
+
+	res := &model.Preferences{
+		UserID:        userID,
+		LeadTimes:     make([]time.Duration, 0, len(req.LeadTimes)),
+		EndLeadTimes:  make([]time.Duration, 0, len(req.EndLeadTimes)),
+		DisabledTypes: req.DisabledTypes,
+		DigestTime:    req.DigestTime,
+	}
+
+	for _, leadTime := range req.LeadTimes {
+		dur, err := time.ParseDuration(leadTime)
+		if err != nil {
+			return nil, err
+		}
+		res.LeadTimes = append(res.LeadTimes, dur)
+	}
+
+	for _, leadTime := range req.EndLeadTimes {
+		dur, err := time.ParseDuration(leadTime)
+		if err != nil {
+			return nil, err
+		}
+		res.EndLeadTimes = append(res.EndLeadTimes, dur)
+	}
+
+	if req.QuietHours != nil {
+		res.QuietHoursStart = null.StringFrom(req.QuietHours.Start)
+		res.QuietHoursEnd = null.StringFrom(req.QuietHours.End)
+	}
+
+	return res, nil
+}
+
+func ToApiPreferences(mod *model.Preferences) *api.Preferences {
+	res := &api.Preferences{
+		LeadTimes:     make([]string, 0, len(mod.LeadTimes)),
+		EndLeadTimes:  make([]string, 0, len(mod.EndLeadTimes)),
+		DisabledTypes: mod.DisabledTypes,
+	}
+
+	if res.DisabledTypes == nil {
+		res.DisabledTypes = []string{}
+	}
+
+	for _, leadTime := range mod.LeadTimes {
+		res.LeadTimes = append(res.LeadTimes, leadTime.String())
+	}
+
+	for _, leadTime := range mod.EndLeadTimes {
+		res.EndLeadTimes = append(res.EndLeadTimes, leadTime.String())
+	}
+
+	if mod.DigestTime.Valid {
+		res.DigestTime = &mod.DigestTime.String
+	}
+
+	if mod.QuietHoursStart.Valid && mod.QuietHoursEnd.Valid {
+		res.QuietHours = &api.QuietHours{
+			Start: mod.QuietHoursStart.String,
+			End:   mod.QuietHoursEnd.String,
+		}
+	}
+
+	return res
+}
+
+func ToWebhook(req *api.AddWebhookRequest, userID int64) (*model.Webhook, error) {
+	if req == nil {
+		return nil, api.ErrEmptyRequest
+	}
+
+	res := &model.Webhook{
+		UserID:      userID,
+		URL:         req.URL,
+		Secret:      req.Secret,
+		AllBookings: req.AllBookings,
+		CreatedAt:   time.Now(),
+	}
+
+	// события без повторов, чтобы одно изменение не доставлялось дважды
+	for _, event := range req.Events {
+		if !slices.Contains(res.Events, event) {
+			res.Events = append(res.Events, event)
+		}
+	}
+
+	return res, nil
+}
+
+func ToApiWebhook(mod *model.Webhook) *api.Webhook {
+	return &api.Webhook{
+		ID:          mod.ID,
+		URL:         mod.URL,
+		Events:      mod.Events,
+		AllBookings: mod.AllBookings,
+		CreatedAt:   mod.CreatedAt,
+	}
+}
+
+func ToApiWebhooks(mod []*model.Webhook) []*api.Webhook {
+	res := make([]*api.Webhook, 0, len(mod))
+	for _, elem := range mod {
+		res = append(res, ToApiWebhook(elem))
+	}
+
+	return res
+}
+
+func ToApiWebhookDeliveries(mod []*model.WebhookDelivery) []*api.WebhookDelivery {
+	res := make([]*api.WebhookDelivery, 0, len(mod))
+	for _, elem := range mod {
+		delivery := &api.WebhookDelivery{
+			ID:        elem.ID,
+			Event:     elem.Event,
+			Payload:   elem.Payload,
+			Status:    elem.Status,
+			Attempts:  elem.Attempts,
+			CreatedAt: elem.CreatedAt,
+		}
+
+		if elem.Status == model.DeliveryPending {
+			delivery.NextAttemptAt = &elem.NextAttemptAt
+		}
+
+		if elem.ResponseStatus.Valid {
+			delivery.ResponseStatus = &elem.ResponseStatus.Int64
+		}
+
+		if elem.Error.Valid {
+			delivery.Error = &elem.Error.String
+		}
+
+		if elem.DeliveredAt.Valid {
+			delivery.DeliveredAt = &elem.DeliveredAt.Time
+		}
+
+		res = append(res, delivery)
+	}
+
+	return res
+}
+
+func ToApiJobs(statuses []jobs.Status) []*api.Job {
+	res := make([]*api.Job, 0, len(statuses))
+	for _, elem := range statuses {
+		job := &api.Job{
+			Name:     elem.Name,
+			Schedule: elem.Schedule,
+			Timeout:  elem.Timeout.String(),
+			Running:  elem.Running,
+			Runs:     elem.Runs,
+			Failures: elem.Failures,
+		}
+
+		if !elem.NextRunAt.IsZero() {
+			job.NextRunAt = &elem.NextRunAt
+		}
+
+		if !elem.LastStartedAt.IsZero() {
+			job.LastRun = &api.JobRun{
+				Trigger:   elem.LastTrigger,
+				StartedAt: elem.LastStartedAt,
+			}
+
+			// время завершения предыдущего запуска не показывается, пока идет следующий
+			if !elem.Running && !elem.LastFinishedAt.IsZero() {
+				job.LastRun.FinishedAt = &elem.LastFinishedAt
+				job.LastRun.Duration = elem.LastDuration.String()
+				if elem.LastError != "" {
+					job.LastRun.Error = &elem.LastError
+				}
+			}
+		}
+
+		res = append(res, job)
+	}
+
+	return res
+}
+
+func ToApiArchivedBookings(bookings []*model.ArchivedBooking) []*api.ArchivedBooking {
+	res := make([]*api.ArchivedBooking, 0, len(bookings))
+	for _, elem := range bookings {
+		booking := &api.ArchivedBooking{
+			ID:         elem.ID,
+			SuiteID:    elem.SuiteID,
+			UserID:     elem.UserID,
+			StartDate:  elem.StartDate,
+			EndDate:    elem.EndDate,
+			CreatedAt:  elem.CreatedAt,
+			ArchivedAt: elem.ArchivedAt,
+		}
+
+		if elem.UpdatedAt.Valid {
+			booking.UpdatedAt = &elem.UpdatedAt.Time
+		}
+
+		if elem.ConfirmedAt.Valid {
+			booking.ConfirmedAt = &elem.ConfirmedAt.Time
+		}
+
+		res = append(res, booking)
+	}
+
+	return res
+}
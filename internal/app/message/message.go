@@ -0,0 +1,295 @@
+package message
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-schedule/internal/app/model"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	ContentTypeJSON = "application/json"
+	// ContentTypeLegacy is sent by schedulers that publish bare model.BookingInfo instead of an envelope.
+	ContentTypeLegacy = "text/plain"
+
+	TypeBookingReminder = "booking.reminder"
+	// TypeBookingEnding reminds that the booking ends soon, e.g. to check out in time.
+	TypeBookingEnding = "booking.ending"
+	// TypeBookingDigest lists the bookings of the user for the day.
+	TypeBookingDigest = "booking.digest"
+
+	// VersionLegacy denotes bodies that were published before envelopes were introduced.
+	VersionLegacy = 0
+	// BookingReminderVersion is the current schema version of BookingReminder.
+	BookingReminderVersion = 1
+	// BookingDigestVersion is the current schema version of BookingDigest.
+	BookingDigestVersion = 1
+	// BookingEventVersion is the current schema version of BookingEvent.
+	BookingEventVersion = 1
+)
+
+// OptionalTypes lists the notification types users may opt out of.
+var OptionalTypes = []string{TypeBookingReminder, TypeBookingEnding}
+
+var (
+	ErrMalformed          = errors.New("malformed message")
+	ErrUnknownType        = errors.New("unknown message type")
+	ErrUnsupportedVersion = errors.New("unsupported message schema version")
+)
+
+// Envelope wraps every notification sent from the scheduler to the sender.
+type Envelope struct {
+	// Тип сообщения, по которому отправитель выбирает обработчик
+	Type string `json:"type"`
+	// Версия схемы полезной нагрузки
+	Version int `json:"version"`
+	// Ключ идемпотентности, одинаковый для повторных публикаций одного и того же уведомления
+	IdempotencyKey string `json:"idempotencyKey"`
+	// Дата и время формирования сообщения
+	ProducedAt time.Time `json:"producedAt"`
+	// Полезная нагрузка, схема которой определяется типом и версией
+	Payload json.RawMessage `json:"payload"`
+}
+
+// BookingReminder is the payload of booking.reminder and booking.ending messages.
+type BookingReminder struct {
+	BookingID uuid.UUID `json:"bookingID"`
+	SuiteID   int64     `json:"suiteID"`
+	UserID    int64     `json:"userID"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	// Вид напоминания: "start" или "end", отсутствует в сообщениях, отправленных до появления напоминаний об окончании
+	Kind string `json:"kind,omitempty"`
+	// Интервал до начала или окончания бронирования, за который отправляется напоминание, например "24h0m0s"
+	LeadTime string `json:"leadTime,omitempty"`
+	// Момент, на который было запланировано напоминание
+	DueAt time.Time `json:"dueAt"`
+	// Получатель уведомления, отсутствует в сообщениях устаревших версий
+	Recipient *Recipient `json:"recipient,omitempty"`
+}
+
+// Recipient describes whom and how to notify.
+type Recipient struct {
+	Name       string `json:"name"`
+	TelegramID int64  `json:"telegramID"`
+	// Язык уведомления, например "ru"
+	Language string `json:"language"`
+	// Часовой пояс из базы IANA, в котором отображается время
+	Timezone string `json:"timezone"`
+	// Типы уведомлений, от которых получатель отказался на момент отправки
+	DisabledTypes []string `json:"disabledTypes,omitempty"`
+}
+
+// BookingDigest is the payload of booking.digest messages.
+type BookingDigest struct {
+	UserID int64 `json:"userID"`
+	// Дата сводки в часовом поясе получателя, например "2024-03-28"
+	Date string `json:"date"`
+	// Бронирования, которые хотя бы частично приходятся на эту дату, в порядке начала
+	Bookings []DigestBooking `json:"bookings"`
+	// Получатель уведомления
+	Recipient *Recipient `json:"recipient"`
+}
+
+// DigestBooking is one of the bookings listed in a digest.
+type DigestBooking struct {
+	BookingID uuid.UUID `json:"bookingID"`
+	SuiteID   int64     `json:"suiteID"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+// BookingEvent describes a change of a booking, e.g. posted to webhooks subscribed to the event.
+type BookingEvent struct {
+	// Событие: booking.created, booking.updated или booking.cancelled
+	Event string `json:"event"`
+	// Версия схемы события
+	Version int `json:"version"`
+	// Дата и время изменения бронирования
+	OccurredAt time.Time `json:"occurredAt"`
+	// Бронирование после изменения, для отмененных - на момент отмены
+	Booking EventBooking `json:"booking"`
+}
+
+// EventBooking is the booking a BookingEvent is about.
+type EventBooking struct {
+	BookingID uuid.UUID `json:"bookingID"`
+	SuiteID   int64     `json:"suiteID"`
+	UserID    int64     `json:"userID"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+// legacyBooking is the body schedulers published before envelopes were introduced,
+// i.e. model.BookingInfo of that time marshalled with default field names.
+type legacyBooking struct {
+	ID        uuid.UUID
+	SuiteID   int64
+	StartDate time.Time
+	EndDate   time.Time
+	NotifyAt  time.Duration
+	UserID    int64
+}
+
+// NewEnvelope marshals payload and wraps it into an envelope of the given type and version.
+func NewEnvelope(msgType string, version int, idempotencyKey string, payload interface{}) (*Envelope, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		Type:           msgType,
+		Version:        version,
+		IdempotencyKey: idempotencyKey,
+		ProducedAt:     time.Now(),
+		Payload:        data,
+	}, nil
+}
+
+// NewBookingReminder builds the reminder that is due lead time before the start or the end of the booking,
+// depending on the kind.
+func NewBookingReminder(booking *model.BookingInfo, kind string, leadTime time.Duration) *BookingReminder {
+	anchor := booking.StartDate
+	if kind == model.ReminderKindEnd {
+		anchor = booking.EndDate
+	}
+
+	res := &BookingReminder{
+		BookingID: booking.ID,
+		SuiteID:   booking.SuiteID,
+		UserID:    booking.UserID,
+		StartDate: booking.StartDate,
+		EndDate:   booking.EndDate,
+		Kind:      kind,
+		DueAt:     anchor.Add(-leadTime),
+	}
+	if leadTime != 0 {
+		res.LeadTime = leadTime.String()
+	}
+
+	return res
+}
+
+// ReminderKey identifies a reminder of a booking that is due at the given time.
+func ReminderKey(bookingID uuid.UUID, kind string, dueAt time.Time) string {
+	return fmt.Sprintf("%s:%s:%d", bookingID, kind, dueAt.Unix())
+}
+
+// NewBookingDigest builds the digest of the bookings for the date in the recipient's time zone.
+func NewBookingDigest(userID int64, date time.Time, bookings []*model.BookingInfo) *BookingDigest {
+	res := &BookingDigest{
+		UserID:   userID,
+		Date:     date.Format(time.DateOnly),
+		Bookings: make([]DigestBooking, 0, len(bookings)),
+	}
+
+	for _, booking := range bookings {
+		res.Bookings = append(res.Bookings, DigestBooking{
+			BookingID: booking.ID,
+			SuiteID:   booking.SuiteID,
+			StartDate: booking.StartDate,
+			EndDate:   booking.EndDate,
+		})
+	}
+
+	return res
+}
+
+// NewBookingEvent builds the event about the booking that has just been changed.
+func NewBookingEvent(event string, booking *model.BookingInfo) *BookingEvent {
+	return &BookingEvent{
+		Event:      event,
+		Version:    BookingEventVersion,
+		OccurredAt: time.Now(),
+		Booking: EventBooking{
+			BookingID: booking.ID,
+			SuiteID:   booking.SuiteID,
+			UserID:    booking.UserID,
+			StartDate: booking.StartDate,
+			EndDate:   booking.EndDate,
+		},
+	}
+}
+
+// EventRoutingKey returns the routing key of the event about a booking of the suite, e.g. booking.created.suite.1,
+// so that consumers may bind to events of certain types or suites only.
+func EventRoutingKey(event string, suiteID int64) string {
+	return fmt.Sprintf("%s.suite.%d", event, suiteID)
+}
+
+// DigestKey identifies the digest of the user for the date.
+func DigestKey(userID int64, date time.Time) string {
+	return fmt.Sprintf("digest:%d:%s", userID, date.Format(time.DateOnly))
+}
+
+// Decode parses a message body. Bodies without an envelope are treated as legacy booking reminders.
+func Decode(body []byte, contentType string) (*Envelope, error) {
+	if contentType != ContentTypeLegacy {
+		env := new(Envelope)
+		err := json.Unmarshal(body, env)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+
+		if env.Type != "" {
+			return env, nil
+		}
+	}
+
+	return &Envelope{
+		Type:    TypeBookingReminder,
+		Version: VersionLegacy,
+		Payload: body,
+	}, nil
+}
+
+// DecodeBookingReminder extracts a booking reminder from the envelope, upgrading legacy payloads.
+func DecodeBookingReminder(env *Envelope) (*BookingReminder, error) {
+	switch env.Version {
+	case VersionLegacy:
+		booking := new(legacyBooking)
+		err := json.Unmarshal(env.Payload, booking)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+
+		return NewBookingReminder(&model.BookingInfo{
+			ID:        booking.ID,
+			SuiteID:   booking.SuiteID,
+			StartDate: booking.StartDate,
+			EndDate:   booking.EndDate,
+			UserID:    booking.UserID,
+		}, model.ReminderKindStart, booking.NotifyAt), nil
+	case BookingReminderVersion:
+		res := new(BookingReminder)
+		err := json.Unmarshal(env.Payload, res)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+
+		return res, nil
+	default:
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnsupportedVersion, env.Type, env.Version)
+	}
+}
+
+// DecodeBookingDigest extracts a booking digest from the envelope.
+func DecodeBookingDigest(env *Envelope) (*BookingDigest, error) {
+	switch env.Version {
+	case BookingDigestVersion:
+		res := new(BookingDigest)
+		err := json.Unmarshal(env.Payload, res)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrMalformed, err)
+		}
+
+		return res, nil
+	default:
+		return nil, fmt.Errorf("%w: %s v%d", ErrUnsupportedVersion, env.Type, env.Version)
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	// ActionConfirm confirms that the user will attend the booking
+	ActionConfirm = "confirm"
+	// ActionCancel cancels the booking
+	ActionCancel = "cancel"
+	// ActionExtend extends the booking by BookingExtension
+	ActionExtend = "extend"
+
+	// BookingExtension is how much a booking is extended by ActionExtend
+	BookingExtension = 30 * time.Minute
+)
+
+// BookingAction is an operation on a booking the user can take right from a notification,
+// authorized by a signed action token instead of a session.
+type BookingAction struct {
+	Action    string
+	BookingID uuid.UUID
+	UserID    int64
+	// Идентификатор токена: токен действия принимается один раз
+	TokenID uuid.UUID
+	// Срок действия токена, до которого хранится отметка о его использовании
+	ExpiresAt time.Time
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+	"gopkg.in/guregu/null.v3"
+)
+
+// ArchivedBooking is a booking moved out of the bookings table once it got older than the booking TTL.
+type ArchivedBooking struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	SuiteID     int64     `db:"suite_id" json:"suite_id"`
+	UserID      int64     `db:"user_id" json:"user_id"`
+	StartDate   time.Time `db:"start_date" json:"start_date"`
+	EndDate     time.Time `db:"end_date" json:"end_date"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   null.Time `db:"updated_at" json:"updated_at"`
+	ConfirmedAt null.Time `db:"confirmed_at" json:"confirmed_at"`
+	// Дата и время переноса в архив, нулевые, пока бронирование не перенесено
+	ArchivedAt time.Time `db:"archived_at" json:"archived_at"`
+}
+
+// ArchiveFilter selects archived bookings, zero fields are not filtered on.
+type ArchiveFilter struct {
+	UserID  int64
+	SuiteID int64
+	// Бронирования, пересекающиеся с периодом [Start, End]
+	Start  time.Time
+	End    time.Time
+	Limit  uint64
+	Offset uint64
+}
@@ -7,15 +7,44 @@ import (
 	"gopkg.in/guregu/null.v3"
 )
 
+const (
+	// ReminderKindStart reminders are due lead time before the booking start
+	ReminderKindStart = "start"
+	// ReminderKindEnd reminders are due lead time before the booking end, e.g. to check out
+	ReminderKindEnd = "end"
+)
+
 type BookingInfo struct {
-	ID        uuid.UUID     `db:"id"`
-	SuiteID   int64         `db:"suite_id"`
-	StartDate time.Time     `db:"start_date"`
-	EndDate   time.Time     `db:"end_date"`
-	NotifyAt  time.Duration `db:"notify_at"`
-	CreatedAt time.Time     `db:"created_at"`
-	UpdatedAt null.Time     `db:"updated_at"`
-	UserID    int64         `db:"user_id"`
+	ID        uuid.UUID `db:"id"`
+	SuiteID   int64     `db:"suite_id"`
+	StartDate time.Time `db:"start_date"`
+	EndDate   time.Time `db:"end_date"`
+	// Интервалы до начала бронирования для напоминаний, nil при обновлении означает "не менять"
+	NotifyAt []time.Duration `db:"notify_at"`
+	// Интервалы до окончания бронирования для напоминаний о выезде, nil при обновлении означает "не менять"
+	NotifyBeforeEnd []time.Duration `db:"notify_before_end"`
+	CreatedAt       time.Time       `db:"created_at"`
+	UpdatedAt       null.Time       `db:"updated_at"`
+	// Дата и время, когда пользователь подтвердил, что приедет
+	ConfirmedAt null.Time `db:"confirmed_at"`
+	UserID      int64     `db:"user_id"`
+}
+
+// BookingNotification is a booking joined with the user who is to be notified about it.
+type BookingNotification struct {
+	BookingInfo
+	Recipient
+	// Вид напоминания: о начале или об окончании бронирования
+	Kind string `db:"kind"`
+	// Интервал до начала или окончания бронирования, за который отправляется это напоминание
+	LeadTime time.Duration `db:"lead_time"`
+}
+
+// Reminder identifies one of the reminders of a booking.
+type Reminder struct {
+	BookingID uuid.UUID     `db:"booking_id"`
+	Kind      string        `db:"kind"`
+	LeadTime  time.Duration `db:"lead_time"`
 }
 
 type Interval struct {
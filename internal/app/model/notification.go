@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// DeferredNotification is a published-ready message held back until the recipient's quiet hours end.
+type DeferredNotification struct {
+	IdempotencyKey string    `db:"idempotency_key"`
+	UserID         int64     `db:"user_id"`
+	Type           string    `db:"type"`
+	Body           []byte    `db:"body"`
+	SendAt         time.Time `db:"send_at"`
+	// Пользователь отказался от уведомлений этого типа после того, как оно было отложено
+	Disabled bool `db:"disabled"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import (
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// OutboxEvent is a domain event stored in the transaction that caused it and relayed to the broker afterwards.
+type OutboxEvent struct {
+	ID int64 `db:"id"`
+	// Идентификатор сообщения, одинаковый для повторных публикаций события
+	MessageID uuid.UUID `db:"message_id"`
+	Type      string    `db:"type"`
+	// Ключ маршрутизации в обменнике событий, например booking.created.suite.1
+	RoutingKey string    `db:"routing_key"`
+	Body       []byte    `db:"body"`
+	CreatedAt  time.Time `db:"created_at"`
+}
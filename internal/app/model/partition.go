@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+// Partition is a monthly partition of the bookings table.
+type Partition struct {
+	Name string
+	// Первое число месяца, бронирования которого начинаются в секции
+	Month time.Time
+}
@@ -6,14 +6,26 @@ import (
 	"gopkg.in/guregu/null.v3"
 )
 
+const (
+	LanguageRU = "ru"
+	LanguageEN = "en"
+
+	DefaultLanguage = LanguageRU
+	DefaultTimezone = "UTC"
+)
+
 type User struct {
 	ID         int64      `db:"id"`
 	TelegramID int64      `db:"telegram_id"`
 	Nickname   string     `db:"telegram_nickname"`
 	Name       string     `db:"name"`
 	Password   string     `db:"password"`
+	Language   string     `db:"language"`
+	Timezone   string     `db:"timezone"`
 	CreatedAt  time.Time  `db:"created_at"`
 	UpdatedAt  *time.Time `db:"updated_at"`
+	// Администратор может подписываться на события всех бронирований
+	IsAdmin bool `db:"is_admin"`
 }
 
 type UpdateUserInfo struct {
@@ -22,4 +34,41 @@ type UpdateUserInfo struct {
 	Nickname   null.String `db:"telegram_nickname"`
 	Name       null.String `db:"name"`
 	Password   null.String `db:"password"`
+	Language   null.String `db:"language"`
+	Timezone   null.String `db:"timezone"`
+}
+
+// Recipient is the user a notification is addressed to.
+type Recipient struct {
+	Name            string      `db:"name"`
+	TelegramID      int64       `db:"telegram_id"`
+	Language        string      `db:"language"`
+	Timezone        string      `db:"timezone"`
+	QuietHoursStart null.String `db:"quiet_hours_start"`
+	QuietHoursEnd   null.String `db:"quiet_hours_end"`
+	DisabledTypes   []string    `db:"disabled_types"`
+}
+
+// Preferences control when and which notifications the user receives.
+type Preferences struct {
+	UserID int64 `db:"user_id"`
+	// Интервалы до начала бронирования для напоминаний, если у бронирования не задан свой
+	LeadTimes []time.Duration `db:"lead_times"`
+	// Интервалы до окончания бронирования для напоминаний о выезде, если у бронирования не задан свой
+	EndLeadTimes []time.Duration `db:"end_lead_times"`
+	// Начало и конец тихих часов в формате 15:04 в часовом поясе пользователя
+	QuietHoursStart null.String `db:"quiet_hours_start"`
+	QuietHoursEnd   null.String `db:"quiet_hours_end"`
+	// Типы уведомлений, от которых пользователь отказался
+	DisabledTypes []string `db:"disabled_types"`
+	// Время ежедневной сводки бронирований в формате 15:04 в часовом поясе пользователя, сводка отключена, если не задано
+	DigestTime null.String `db:"digest_time"`
+	CreatedAt  time.Time   `db:"created_at"`
+	UpdatedAt  *time.Time  `db:"updated_at"`
+}
+
+// DigestRecipient is the user who is due to receive the daily digest of their bookings.
+type DigestRecipient struct {
+	UserID int64 `db:"user_id"`
+	Recipient
 }
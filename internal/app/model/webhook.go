@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	EventBookingCreated   = "booking.created"
+	EventBookingUpdated   = "booking.updated"
+	EventBookingCancelled = "booking.cancelled"
+
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+// WebhookEvents lists the booking lifecycle events webhooks may subscribe to.
+var WebhookEvents = []string{EventBookingCreated, EventBookingUpdated, EventBookingCancelled}
+
+// Webhook is an endpoint events about the bookings of its owner, or of every booking for admins, are posted to.
+type Webhook struct {
+	ID     int64  `db:"id"`
+	UserID int64  `db:"user_id"`
+	URL    string `db:"url"`
+	// Ключ, которым подписываются доставки
+	Secret string   `db:"secret"`
+	Events []string `db:"events"`
+	// Получать события обо всех бронированиях, а не только о своих; доступно администраторам
+	AllBookings bool      `db:"all_bookings"`
+	CreatedAt   time.Time `db:"created_at"`
+}
+
+// WebhookDelivery is an attempt to post an event to a webhook, kept in the delivery log.
+type WebhookDelivery struct {
+	ID        int64  `db:"id"`
+	WebhookID int64  `db:"webhook_id"`
+	Event     string `db:"event"`
+	Payload   []byte `db:"payload"`
+	// Состояние доставки: pending, delivered или failed
+	Status   string `db:"status"`
+	Attempts int    `db:"attempts"`
+	// Время следующей попытки для ожидающих доставки
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+	// HTTP статус последнего ответа получателя
+	ResponseStatus null.Int `db:"response_status"`
+	// Ошибка последней попытки
+	Error       null.String `db:"error"`
+	CreatedAt   time.Time   `db:"created_at"`
+	DeliveredAt null.Time   `db:"delivered_at"`
+}
+
+// PendingDelivery is a delivery claimed for an attempt together with where and how to post it.
+type PendingDelivery struct {
+	ID        int64  `db:"id"`
+	WebhookID int64  `db:"webhook_id"`
+	Event     string `db:"event"`
+	Payload   []byte `db:"payload"`
+	// Номер текущей попытки
+	Attempts int    `db:"attempts"`
+	URL      string `db:"url"`
+	Secret   string `db:"secret"`
+}
@@ -0,0 +1,48 @@
+package archive
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Repository moves the bookings that got older than the booking TTL out of the bookings table
+// and reads the archive they are moved to.
+type Repository interface {
+	GetExpiredBookings(ctx context.Context, before time.Time, limit uint64) ([]*model.ArchivedBooking, error)
+	ArchiveBookings(ctx context.Context, bookings []*model.ArchivedBooking, before time.Time, archivedAt time.Time) (int64, error)
+	DeleteBookings(ctx context.Context, bookings []*model.ArchivedBooking, before time.Time) (int64, error)
+	GetArchivedBookings(ctx context.Context, filter *model.ArchiveFilter) ([]*model.ArchivedBooking, error)
+}
+
+var (
+	ErrQuery        = errors.New("failed to execute query")
+	ErrQueryBuild   = errors.New("failed to build query")
+	ErrNoConnection = errors.New("could not connect to database")
+
+	pgNoConnection = new(*pgconn.ConnectError)
+)
+
+// columns are the columns of a booking kept in the archive, except for the time it was archived at.
+var columns = []string{t.ID, t.SuiteID, t.UserID, t.StartDate, t.EndDate, t.CreatedAt, t.UpdatedAt, t.ConfirmedAt}
+
+type repository struct {
+	client db.Client
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewArchiveRepository(client db.Client, log *slog.Logger, tracer trace.Tracer) Repository {
+	return &repository{
+		client: client,
+		log:    log,
+		tracer: tracer,
+	}
+}
@@ -0,0 +1,86 @@
+package archive
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetArchivedBookings returns the archived bookings matching the filter, the latest first.
+func (r *repository) GetArchivedBookings(ctx context.Context, filter *model.ArchiveFilter) ([]*model.ArchivedBooking, error) {
+	const op = "repository.archive.GetArchivedBookings"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	where := sq.And{}
+	if filter.UserID != 0 {
+		where = append(where, sq.Eq{t.UserID: filter.UserID})
+	}
+	if filter.SuiteID != 0 {
+		where = append(where, sq.Eq{t.SuiteID: filter.SuiteID})
+	}
+	// условие на дату окончания позволяет не читать секции архива за пределами периода
+	if !filter.Start.IsZero() {
+		where = append(where, sq.GtOrEq{t.EndDate: filter.Start})
+	}
+	if !filter.End.IsZero() {
+		where = append(where, sq.LtOrEq{t.StartDate: filter.End})
+	}
+
+	builder := sq.Select(append([]string{t.ArchivedAt}, columns...)...).
+		From(t.ArchiveTable).
+		Where(where).
+		OrderBy(t.EndDate+" desc", t.ID).
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var res []*model.ArchivedBooking
+	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int("quantity", len(res))))
+
+	return res, nil
+}
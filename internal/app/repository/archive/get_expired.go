@@ -1,4 +1,4 @@
-package booking
+package archive
 
 import (
 	"booking-schedule/internal/app/model"
@@ -11,29 +11,27 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func (r *repository) GetBookingListByDate(ctx context.Context, startDate time.Time, endDate time.Time) ([]*model.BookingInfo, error) {
-	op := "repository.booking.GetBookingListByDate"
-
-	log := r.log.With(slog.String("op", op))
+// GetExpiredBookings returns up to limit bookings that ended before the date, the earliest first.
+func (r *repository) GetExpiredBookings(ctx context.Context, before time.Time, limit uint64) ([]*model.ArchivedBooking, error) {
+	const op = "repository.archive.GetExpiredBookings"
 
+	log := r.log.With(
+		slog.String("op", op),
+	)
 	ctx, span := r.tracer.Start(ctx, op)
 	defer span.End()
 
-	builder := sq.Select(t.ID, t.SuiteID, t.StartDate, t.EndDate, t.NotifyAt, t.CreatedAt, t.UpdatedAt, t.UserID).
+	builder := sq.Select(columns...).
 		From(t.BookingTable).
-		Where(sq.Or{
-			sq.And{
-				sq.Gt{t.StartDate: startDate},
-				sq.LtOrEq{t.StartDate: endDate},
-			},
-			sq.And{
-				sq.Gt{t.StartDate + "-" + t.NotifyAt: startDate},
-				sq.LtOrEq{t.StartDate + "-" + t.NotifyAt: endDate},
-			},
-		}).PlaceholderFormat(sq.Dollar)
+		Where(sq.Lt{t.EndDate: before}).
+		OrderBy(t.EndDate, t.ID).
+		Limit(limit).
+		PlaceholderFormat(sq.Dollar)
 
 	query, args, err := builder.ToSql()
 	if err != nil {
@@ -50,7 +48,7 @@ func (r *repository) GetBookingListByDate(ctx context.Context, startDate time.Ti
 		QueryRaw: query,
 	}
 
-	var res []*model.BookingInfo
+	var res []*model.ArchivedBooking
 	err = r.client.DB().SelectContext(ctx, &res, q, args...)
 	if err != nil {
 		span.RecordError(err)
@@ -63,7 +61,7 @@ func (r *repository) GetBookingListByDate(ctx context.Context, startDate time.Ti
 		return nil, ErrQuery
 	}
 
-	span.AddEvent("query successfully executed")
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int("quantity", len(res))))
 
 	return res, nil
 }
@@ -0,0 +1,195 @@
+package archive
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ArchiveBookings moves the bookings into the archive in one statement, so that a booking is either
+// archived or kept. Bookings that no longer end before the date are kept. Returns the number of bookings moved.
+func (r *repository) ArchiveBookings(ctx context.Context, bookings []*model.ArchivedBooking, before time.Time, archivedAt time.Time) (int64, error) {
+	const op = "repository.archive.ArchiveBookings"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.Int("quantity", len(bookings))))
+	defer span.End()
+
+	if len(bookings) == 0 {
+		return 0, nil
+	}
+
+	err := r.createPartitions(ctx, bookings)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	span.AddEvent("partitions created")
+
+	archived := make([]string, 0, len(columns)+1)
+	archived = append(archived, columns...)
+	archived = append(archived, t.ArchivedAt)
+
+	moved := sq.Delete(t.BookingTable).
+		Where(sq.And{
+			sq.Eq{t.ID: ids(bookings)},
+			sq.Lt{t.EndDate: before},
+		}).
+		Suffix("returning " + strings.Join(columns, ", "))
+
+	builder := sq.Insert(t.ArchiveTable).
+		PrefixExpr(sq.Expr("with moved as (?)", moved)).
+		Columns(archived...).
+		Select(sq.Select(columns...).Column("?::timestamp", archivedAt).From("moved")).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return 0, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	tag, err := r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return 0, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return 0, ErrQuery
+	}
+
+	span.AddEvent("bookings archived", trace.WithAttributes(attribute.Int64("archived", tag.RowsAffected())))
+
+	return tag.RowsAffected(), nil
+}
+
+// DeleteBookings deletes the bookings without archiving them, bookings that no longer end before the date are kept.
+// Returns the number of bookings deleted.
+func (r *repository) DeleteBookings(ctx context.Context, bookings []*model.ArchivedBooking, before time.Time) (int64, error) {
+	const op = "repository.archive.DeleteBookings"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.Int("quantity", len(bookings))))
+	defer span.End()
+
+	if len(bookings) == 0 {
+		return 0, nil
+	}
+
+	builder := sq.Delete(t.BookingTable).
+		Where(sq.And{
+			sq.Eq{t.ID: ids(bookings)},
+			sq.Lt{t.EndDate: before},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return 0, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	tag, err := r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return 0, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return 0, ErrQuery
+	}
+
+	span.AddEvent("bookings deleted", trace.WithAttributes(attribute.Int64("deleted", tag.RowsAffected())))
+
+	return tag.RowsAffected(), nil
+}
+
+// createPartitions creates the monthly partitions of the archive the bookings fall into, if they do not exist yet.
+func (r *repository) createPartitions(ctx context.Context, bookings []*model.ArchivedBooking) error {
+	const op = "repository.archive.createPartitions"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	months := make(map[time.Time]struct{})
+	for _, b := range bookings {
+		months[time.Date(b.EndDate.Year(), b.EndDate.Month(), 1, 0, 0, 0, 0, time.UTC)] = struct{}{}
+	}
+
+	for month := range months {
+		// границы секции формируются из дат, поэтому подставляются в запрос напрямую: DDL не принимает параметры
+		q := db.Query{
+			Name: op,
+			QueryRaw: fmt.Sprintf("create table if not exists %s partition of %s for values from ('%s') to ('%s')",
+				partitionName(month), t.ArchiveTable, month.Format(time.DateOnly), month.AddDate(0, 1, 0).Format(time.DateOnly)),
+		}
+
+		_, err := r.client.DB().ExecContext(ctx, q)
+		if err != nil {
+			if errors.As(err, pgNoConnection) {
+				log.Error("no connection to database host", sl.Err(err))
+				return ErrNoConnection
+			}
+			log.Error("failed to create archive partition", sl.Err(err), slog.String("partition", partitionName(month)))
+			return ErrQuery
+		}
+	}
+
+	return nil
+}
+
+func partitionName(month time.Time) string {
+	return fmt.Sprintf("%s_y%04dm%02d", t.ArchiveTable, month.Year(), month.Month())
+}
+
+func ids(bookings []*model.ArchivedBooking) []uuid.UUID {
+	res := make([]uuid.UUID, 0, len(bookings))
+	for _, b := range bookings {
+		res = append(res, b.ID)
+	}
+
+	return res
+}
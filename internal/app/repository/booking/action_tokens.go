@@ -0,0 +1,124 @@
+package booking
+
+import (
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/middleware"
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UseActionToken marks the action token as used, ErrTokenUsed is returned if it already was. Called in the
+// transaction taking the action, so that the token stays valid when the action fails.
+func (r *repository) UseActionToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) error {
+	const op = "repository.booking.UseActionToken"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	builder := sq.Insert(t.UsedTokensTable).
+		Columns(t.TokenID, t.ExpiresAt, t.UsedAt).
+		Values(tokenID, expiresAt, time.Now()).
+		Suffix("on conflict (" + t.TokenID + ") do nothing").
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	result, err := r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	if result.RowsAffected() == 0 {
+		span.RecordError(ErrTokenUsed)
+		span.SetStatus(codes.Error, ErrTokenUsed.Error())
+		log.Error("action token replayed", sl.Err(ErrTokenUsed), slog.String("token_id", tokenID.String()))
+		return ErrTokenUsed
+	}
+
+	span.AddEvent("action token used")
+
+	return nil
+}
+
+// PurgeActionTokens forgets the used action tokens that expired before the date: expired tokens are rejected anyway.
+func (r *repository) PurgeActionTokens(ctx context.Context, before time.Time) (int64, error) {
+	const op = "repository.booking.PurgeActionTokens"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	builder := sq.Delete(t.UsedTokensTable).
+		Where(sq.Lt{t.ExpiresAt: before}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return 0, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	result, err := r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return 0, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return 0, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int64("purged", result.RowsAffected())))
+
+	return result.RowsAffected(), nil
+}
@@ -32,8 +32,6 @@ func (r *repository) AddBooking(ctx context.Context, mod *model.BookingInfo) (uu
 	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
 	defer span.End()
 
-	var builder sq.InsertBuilder
-
 	newID, err := uuid.NewV4()
 	if err != nil {
 		span.RecordError(err)
@@ -44,15 +42,9 @@ func (r *repository) AddBooking(ctx context.Context, mod *model.BookingInfo) (uu
 
 	span.AddEvent("uuid generated")
 
-	if mod.NotifyAt != 0 {
-		builder = sq.Insert(t.BookingTable).
-			Columns(t.ID, t.UserID, t.SuiteID, t.StartDate, t.EndDate, t.CreatedAt, t.NotifyAt).
-			Values(newID, mod.UserID, mod.SuiteID, mod.StartDate, mod.EndDate, time.Now(), mod.NotifyAt)
-	} else {
-		builder = sq.Insert(t.BookingTable).
-			Columns(t.ID, t.UserID, t.SuiteID, t.StartDate, t.EndDate, t.CreatedAt).
-			Values(newID, mod.UserID, mod.SuiteID, mod.StartDate, mod.EndDate, time.Now())
-	}
+	builder := sq.Insert(t.BookingTable).
+		Columns(t.ID, t.UserID, t.SuiteID, t.StartDate, t.EndDate, t.CreatedAt).
+		Values(newID, mod.UserID, mod.SuiteID, mod.StartDate, mod.EndDate, time.Now())
 
 	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
@@ -2,6 +2,7 @@ package booking
 
 import (
 	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
 	"booking-schedule/internal/pkg/db"
 	"context"
 	"errors"
@@ -19,17 +20,24 @@ type Repository interface {
 	GetBookings(ctx context.Context, startDate time.Time, endDate time.Time, userID int64) ([]*model.BookingInfo, error)
 	UpdateBooking(ctx context.Context, mod *model.BookingInfo) error
 	DeleteBooking(ctx context.Context, bookingID uuid.UUID, userID int64) error
+	ConfirmBooking(ctx context.Context, bookingID uuid.UUID, userID int64) error
 	GetVacantRooms(ctx context.Context, startDate time.Time, endDate time.Time) ([]*model.Suite, error)
 	GetBusyDates(ctx context.Context, suiteID int64) ([]*model.Interval, error)
-	GetBookingListByDate(ctx context.Context, start time.Time, end time.Time) ([]*model.BookingInfo, error)
-	DeleteBookingsBeforeDate(ctx context.Context, end time.Time) error
+	GetOverlappingBookings(ctx context.Context, userID int64, start time.Time, end time.Time) ([]*model.BookingInfo, error)
+	SetReminders(ctx context.Context, bookingID uuid.UUID, kind string, leadTimes []time.Duration) error
+	RescheduleReminders(ctx context.Context, bookingID uuid.UUID) error
+	GetDueReminders(ctx context.Context, date time.Time, since time.Time) ([]*model.BookingNotification, error)
+	MarkRemindersSent(ctx context.Context, reminders []*model.Reminder) error
 	CheckAvailibility(ctx context.Context, mod *model.BookingInfo) (*model.Availibility, error)
+	UseActionToken(ctx context.Context, tokenID uuid.UUID, expiresAt time.Time) error
+	PurgeActionTokens(ctx context.Context, before time.Time) (int64, error)
 }
 
 var (
 	ErrNotFound       = errors.New("no booking with this id")
 	ErrNoRowsAffected = errors.New("no database entries affected by this operation")
 	ErrUnauthorized   = errors.New("no user associated with this token")
+	ErrTokenUsed      = errors.New("this action link has already been used")
 
 	ErrQuery        = errors.New("failed to execute query")
 	ErrQueryBuild   = errors.New("failed to build query")
@@ -45,6 +53,20 @@ var (
 		ConstraintName: "fk_users"}
 )
 
+var (
+	// notifyAt collects the lead times of the start reminders, except for the one sent at the start itself.
+	notifyAt = leadTimesOf(model.ReminderKindStart, t.NotifyAt)
+	// notifyBeforeEnd collects the lead times of the end reminders.
+	notifyBeforeEnd = leadTimesOf(model.ReminderKindEnd, t.NotifyBeforeEnd)
+)
+
+// leadTimesOf builds a column that aggregates the positive lead times of the booking reminders of the kind.
+func leadTimesOf(kind string, column string) string {
+	return "array(select " + t.LeadTime + " from " + t.ReminderTable +
+		" where " + t.ReminderTable + "." + t.BookingID + " = " + t.BookingTable + "." + t.ID +
+		" and " + t.Kind + " = '" + kind + "' and " + t.LeadTime + " > '0s' order by " + t.LeadTime + " desc) as " + column
+}
+
 type repository struct {
 	client db.Client
 	log    *slog.Logger
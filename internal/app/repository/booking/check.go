@@ -39,6 +39,8 @@ func (r *repository) CheckAvailibility(ctx context.Context, mod *model.BookingIn
 				},
 				sq.Eq{t.ID: mod.ID},
 			},
+			// следует из условий ниже, но позволяет не читать секции месяцев после периода
+			sq.LtOrEq{t.StartDate: mod.EndDate},
 			sq.Or{
 				sq.And{
 					sq.GtOrEq{t.StartDate: mod.StartDate},
@@ -58,6 +60,7 @@ func (r *repository) CheckAvailibility(ctx context.Context, mod *model.BookingIn
 	query, args, err := sq.Select("1").From(t.BookingTable).Where(sq.And{
 		sq.And{
 			sq.Eq{t.SuiteID: mod.SuiteID},
+			sq.LtOrEq{t.StartDate: mod.EndDate},
 			sq.Or{
 				sq.And{
 					sq.GtOrEq{t.StartDate: mod.StartDate},
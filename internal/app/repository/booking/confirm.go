@@ -0,0 +1,80 @@
+package booking
+
+import (
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/middleware"
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ConfirmBooking records that the user confirmed they will attend the booking. Repeated confirmations
+// keep the time of the first one.
+func (r *repository) ConfirmBooking(ctx context.Context, bookingID uuid.UUID, userID int64) error {
+	const op = "repository.booking.ConfirmBooking"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	builder := sq.Update(t.BookingTable).
+		Set(t.ConfirmedAt, sq.Expr("coalesce("+t.ConfirmedAt+", ?)", time.Now())).
+		Where(sq.And{
+			sq.Eq{t.ID: bookingID},
+			sq.Eq{t.UserID: userID},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	result, err := r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	if result.RowsAffected() == 0 {
+		span.RecordError(ErrNoRowsAffected)
+		span.SetStatus(codes.Error, ErrNoRowsAffected.Error())
+		log.Error("confirmation unsuccessful", sl.Err(ErrNoRowsAffected))
+		return ErrNotFound
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
@@ -31,10 +31,12 @@ func (r *repository) GetBookings(ctx context.Context, startDate time.Time, endDa
 	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
 	defer span.End()
 
-	builder := sq.Select(t.ID, t.SuiteID, t.StartDate, t.EndDate, t.NotifyAt, t.CreatedAt, t.UpdatedAt, t.UserID).
+	builder := sq.Select(t.ID, t.SuiteID, t.StartDate, t.EndDate, notifyAt, notifyBeforeEnd, t.CreatedAt, t.UpdatedAt, t.ConfirmedAt, t.UserID).
 		From(t.BookingTable).
 		Where(sq.And{
 			sq.Eq{t.UserID: userID},
+			// следует из условий ниже, но позволяет не читать секции месяцев после периода
+			sq.LtOrEq{t.StartDate: endDate},
 			sq.Or{
 				sq.And{
 					sq.GtOrEq{t.StartDate: startDate},
@@ -64,7 +66,7 @@ func (r *repository) GetBookings(ctx context.Context, startDate time.Time, endDa
 	}
 
 	var res []*model.BookingInfo
-	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	err = r.client.DB().SelectContext(db.WithReplica(ctx), &res, q, args...)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -61,7 +61,7 @@ func (r *repository) GetBusyDates(ctx context.Context, suiteID int64) ([]*model.
 	}
 
 	var res []*model.Interval
-	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	err = r.client.DB().SelectContext(db.WithReplica(ctx), &res, q, args...)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -0,0 +1,70 @@
+package booking
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetOverlappingBookings returns the bookings of the user that take at least part of the interval,
+// including the ones that span it entirely, ordered by start date.
+func (r *repository) GetOverlappingBookings(ctx context.Context, userID int64, start time.Time, end time.Time) ([]*model.BookingInfo, error) {
+	const op = "repository.booking.GetOverlappingBookings"
+
+	log := r.log.With(slog.String("op", op))
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.Int64("user_id", userID)))
+	defer span.End()
+
+	builder := sq.Select(t.ID, t.SuiteID, t.StartDate, t.EndDate, notifyAt, notifyBeforeEnd, t.CreatedAt, t.UpdatedAt, t.UserID).
+		From(t.BookingTable).
+		Where(sq.And{
+			sq.Eq{t.UserID: userID},
+			sq.Lt{t.StartDate: end},
+			sq.Gt{t.EndDate: start},
+		}).
+		OrderBy(t.StartDate).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var res []*model.BookingInfo
+	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return res, nil
+}
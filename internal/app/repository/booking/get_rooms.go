@@ -39,6 +39,8 @@ func (r *repository) GetVacantRooms(ctx context.Context, startDate time.Time, en
 		From(t.BookingTable + " AS e").
 		Where(sq.And{
 			sq.ConcatExpr("e."+t.SuiteID+"=", t.SuiteTable+".id"),
+			// следует из условий ниже, но позволяет не читать секции месяцев после периода
+			sq.Lt{"e." + t.StartDate: endDate},
 			sq.Or{sq.And{
 				sq.Lt{"e." + t.StartDate: startDate},
 				sq.Gt{"e." + t.EndDate: endDate},
@@ -73,7 +75,7 @@ func (r *repository) GetVacantRooms(ctx context.Context, startDate time.Time, en
 	}
 
 	var res []*model.Suite
-	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	err = r.client.DB().SelectContext(db.WithReplica(ctx), &res, q, args...)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -0,0 +1,299 @@
+package booking
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/middleware"
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// anchor is the booking date the reminder is tied to, depending on its kind.
+	anchor = "(case when " + t.ReminderTable + "." + t.Kind + " = '" + model.ReminderKindEnd + "' then " +
+		t.BookingTable + "." + t.EndDate + " else " + t.BookingTable + "." + t.StartDate + " end)"
+	// dueAt is the moment the reminder is to be sent at.
+	dueAt = anchor + " - " + t.ReminderTable + "." + t.LeadTime
+)
+
+// SetReminders replaces the lead times of the booking reminders of the kind. The reminder at the booking start
+// itself is always kept among the start ones, reminders that are left unchanged keep their sent state.
+// If leadTimes is nil, the default lead times of the kind from the preferences of the booking owner are used.
+func (r *repository) SetReminders(ctx context.Context, bookingID uuid.UUID, kind string, leadTimes []time.Duration) error {
+	const op = "repository.booking.SetReminders"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+		slog.String("kind", kind),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID), attribute.String("kind", kind)))
+	defer span.End()
+
+	var builders []sq.Sqlizer
+	if leadTimes == nil {
+		b, p := t.BookingTable+".", t.PreferencesTable+"."
+		defaults := "coalesce(" + p + t.EndLeadTimes + ", '{}')"
+		if kind == model.ReminderKindStart {
+			defaults = "array['0s'::interval] || coalesce(" + p + t.LeadTimes + ", '{}')"
+		}
+
+		builders = append(builders, sq.Insert(t.ReminderTable).
+			Columns(t.BookingID, t.Kind, t.LeadTime).
+			Select(sq.Select(b+t.ID, "'"+kind+"'", "unnest("+defaults+")").
+				From(t.BookingTable).
+				LeftJoin(t.PreferencesTable+" on "+p+t.UserID+" = "+b+t.UserID).
+				Where(sq.Eq{b + t.ID: bookingID})).
+			Suffix("on conflict do nothing").
+			PlaceholderFormat(sq.Dollar))
+	} else {
+		if kind == model.ReminderKindStart {
+			leadTimes = append([]time.Duration{0}, leadTimes...)
+		}
+
+		builders = append(builders, sq.Delete(t.ReminderTable).
+			Where(sq.And{
+				sq.Eq{t.BookingID: bookingID},
+				sq.Eq{t.Kind: kind},
+				sq.NotEq{t.LeadTime: leadTimes},
+			}).
+			PlaceholderFormat(sq.Dollar))
+
+		if len(leadTimes) != 0 {
+			insertBuilder := sq.Insert(t.ReminderTable).
+				Columns(t.BookingID, t.Kind, t.LeadTime).
+				Suffix("on conflict do nothing").
+				PlaceholderFormat(sq.Dollar)
+			for _, leadTime := range leadTimes {
+				insertBuilder = insertBuilder.Values(bookingID, kind, leadTime)
+			}
+
+			builders = append(builders, insertBuilder)
+		}
+	}
+
+	for _, builder := range builders {
+		query, args, err := builder.ToSql()
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to build a query", sl.Err(err))
+			return ErrQueryBuild
+		}
+
+		q := db.Query{
+			Name:     op,
+			QueryRaw: query,
+		}
+
+		_, err = r.client.DB().ExecContext(ctx, q, args...)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			if errors.As(err, pgNoConnection) {
+				log.Error("no connection to database host", sl.Err(err))
+				return ErrNoConnection
+			}
+			log.Error("query execution error", sl.Err(err))
+			return ErrQuery
+		}
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
+
+// RescheduleReminders recalculates the sent state of the booking reminders after the booking has changed:
+// reminders that are due in the future are to be sent again, the ones already overdue are never sent.
+func (r *repository) RescheduleReminders(ctx context.Context, bookingID uuid.UUID) error {
+	const op = "repository.booking.RescheduleReminders"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	builder := sq.Update(t.ReminderTable).
+		Set(t.SentAt, sq.Expr("case when "+dueAt+" > ? then null else coalesce("+t.SentAt+", ?) end", now, now)).
+		From(t.BookingTable).
+		Where(sq.And{
+			sq.Expr(t.BookingTable + "." + t.ID + " = " + t.ReminderTable + "." + t.BookingID),
+			sq.Eq{t.ReminderTable + "." + t.BookingID: bookingID},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err = r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
+
+// GetDueReminders returns the unsent reminders due by date, joined with the locale and preferences
+// of the booking owners. Reminders that became due before since are only returned while their booking
+// has not started (or ended, for end reminders) yet, so that the ones missed while the scheduler was down
+// are still delivered.
+func (r *repository) GetDueReminders(ctx context.Context, date time.Time, since time.Time) ([]*model.BookingNotification, error) {
+	const op = "repository.booking.GetDueReminders"
+
+	log := r.log.With(slog.String("op", op))
+
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	b, u, p, rm := t.BookingTable+".", t.UserTable+".", t.PreferencesTable+".", t.ReminderTable+"."
+
+	builder := sq.Select(
+		b+t.ID, b+t.SuiteID, b+t.StartDate, b+t.EndDate, b+t.CreatedAt, b+t.UpdatedAt, b+t.UserID,
+		u+t.Name, u+t.TelegramID, u+t.Language, u+t.Timezone,
+		"to_char("+p+t.QuietHoursStart+", 'HH24:MI') as "+t.QuietHoursStart,
+		"to_char("+p+t.QuietHoursEnd+", 'HH24:MI') as "+t.QuietHoursEnd,
+		"coalesce("+p+t.DisabledTypes+", '{}') as "+t.DisabledTypes,
+		rm+t.Kind, rm+t.LeadTime,
+	).
+		From(t.ReminderTable).
+		Join(t.BookingTable + " on " + b + t.ID + " = " + rm + t.BookingID).
+		Join(t.UserTable + " on " + u + t.ID + " = " + b + t.UserID).
+		LeftJoin(t.PreferencesTable + " on " + p + t.UserID + " = " + b + t.UserID).
+		Where(sq.And{
+			sq.Eq{rm + t.SentAt: nil},
+			sq.LtOrEq{dueAt: date},
+			sq.Or{
+				sq.Gt{anchor: date},
+				sq.Gt{dueAt: since},
+			},
+		}).PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var res []*model.BookingNotification
+	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return res, nil
+}
+
+// MarkRemindersSent records that the reminders were handled so that they are never sent twice.
+func (r *repository) MarkRemindersSent(ctx context.Context, reminders []*model.Reminder) error {
+	const op = "repository.booking.MarkRemindersSent"
+
+	log := r.log.With(slog.String("op", op))
+
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	if len(reminders) == 0 {
+		return nil
+	}
+
+	keys := make(sq.Or, 0, len(reminders))
+	for _, reminder := range reminders {
+		keys = append(keys, sq.Eq{t.BookingID: reminder.BookingID, t.Kind: reminder.Kind, t.LeadTime: reminder.LeadTime})
+	}
+
+	builder := sq.Update(t.ReminderTable).
+		Set(t.SentAt, time.Now().UTC()).
+		Where(keys).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err = r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
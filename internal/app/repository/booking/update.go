@@ -41,10 +41,6 @@ func (r *repository) UpdateBooking(ctx context.Context, mod *model.BookingInfo)
 		}).
 		PlaceholderFormat(sq.Dollar)
 
-	if mod.NotifyAt != 0 {
-		builder = builder.Set(t.NotifyAt, mod.NotifyAt)
-	}
-
 	query, args, err := builder.ToSql()
 	if err != nil {
 		span.RecordError(err)
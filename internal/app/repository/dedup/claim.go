@@ -0,0 +1,128 @@
+package dedup
+
+import (
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Claim leases the notification with the key to the caller for the time of sending it. The lease of a worker
+// that stopped without sending the notification or releasing it expires, and the notification can be claimed again.
+// ErrAlreadySent is returned for the notifications that have been sent and ErrClaimed for the ones leased by others.
+func (r *repository) Claim(ctx context.Context, key string, now time.Time, lease time.Duration) error {
+	const op = "repository.dedup.Claim"
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("idempotency_key", key),
+	)
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("idempotency_key", key)))
+	defer span.End()
+
+	builder := sq.Insert(t.SentTable).
+		Columns(t.IdempotencyKey, t.LockedUntil, t.CreatedAt).
+		Values(key, now.Add(lease), now).
+		Suffix("on conflict ("+t.IdempotencyKey+") do update set "+t.LockedUntil+" = excluded."+t.LockedUntil+
+			" where "+t.SentTable+"."+t.SentAt+" is null and "+t.SentTable+"."+t.LockedUntil+" <= ?", now).
+		Suffix("returning " + t.IdempotencyKey).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var claimed string
+	err = r.client.DB().QueryRowContext(ctx, q, args...).Scan(&claimed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// ключ уже есть и не был перезаписан: уведомление отправлено или отправляется другим обработчиком
+		return r.checkSent(ctx, key)
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("notification claimed")
+
+	return nil
+}
+
+// checkSent tells the notifications that have been sent from the ones being sent.
+func (r *repository) checkSent(ctx context.Context, key string) error {
+	const op = "repository.dedup.checkSent"
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("idempotency_key", key),
+	)
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	builder := sq.Select(t.SentAt + " is not null").
+		From(t.SentTable).
+		Where(sq.Eq{t.IdempotencyKey: key}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var sent bool
+	err = r.client.DB().QueryRowContext(ctx, q, args...).Scan(&sent)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Bool("sent", sent)))
+
+	if sent {
+		return ErrAlreadySent
+	}
+
+	return ErrClaimed
+}
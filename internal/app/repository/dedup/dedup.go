@@ -0,0 +1,45 @@
+package dedup
+
+import (
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Repository keeps the idempotency keys of the notifications being sent and of the sent ones,
+// so that a notification received twice is delivered once.
+type Repository interface {
+	Claim(ctx context.Context, key string, now time.Time, lease time.Duration) error
+	MarkSent(ctx context.Context, key string, now time.Time) error
+	Release(ctx context.Context, key string) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+var (
+	ErrQuery        = errors.New("failed to execute query")
+	ErrQueryBuild   = errors.New("failed to build query")
+	ErrNoConnection = errors.New("could not connect to database")
+	ErrAlreadySent  = errors.New("notification has already been sent")
+	ErrClaimed      = errors.New("notification is being sent by another worker")
+
+	pgNoConnection = new(*pgconn.ConnectError)
+)
+
+type repository struct {
+	client db.Client
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewDedupRepository(client db.Client, log *slog.Logger, tracer trace.Tracer) Repository {
+	return &repository{
+		client: client,
+		log:    log,
+		tracer: tracer,
+	}
+}
@@ -0,0 +1,111 @@
+package dedup
+
+import (
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// MarkSent records that the notification with the key has been sent, so it is never claimed again.
+func (r *repository) MarkSent(ctx context.Context, key string, now time.Time) error {
+	const op = "repository.dedup.MarkSent"
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("idempotency_key", key),
+	)
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	builder := sq.Update(t.SentTable).
+		Set(t.SentAt, now).
+		Set(t.LockedUntil, nil).
+		Where(sq.Eq{t.IdempotencyKey: key}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err = r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
+
+// Release drops the lease of the notification that could not be sent, so that a retry can claim it at once.
+func (r *repository) Release(ctx context.Context, key string) error {
+	const op = "repository.dedup.Release"
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("idempotency_key", key),
+	)
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	builder := sq.Delete(t.SentTable).
+		Where(sq.Eq{t.IdempotencyKey: key}).
+		Where(sq.Eq{t.SentAt: nil}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err = r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
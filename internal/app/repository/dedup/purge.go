@@ -0,0 +1,69 @@
+package dedup
+
+import (
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Purge removes the keys of the notifications sent before the date and the expired leases taken before it.
+// A copy of a notification received after its key is purged is delivered again.
+func (r *repository) Purge(ctx context.Context, before time.Time) (int64, error) {
+	const op = "repository.dedup.Purge"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	builder := sq.Delete(t.SentTable).
+		Where(sq.Or{
+			sq.Lt{t.SentAt: before},
+			sq.And{
+				sq.Eq{t.SentAt: nil},
+				sq.Lt{t.LockedUntil: before},
+			},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return 0, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	tag, err := r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return 0, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return 0, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int64("purged", tag.RowsAffected())))
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DeferNotifications stores notifications to be sent later. A notification deferred twice is stored once.
+func (r *repository) DeferNotifications(ctx context.Context, mods []*model.DeferredNotification) error {
+	const op = "repository.notification.DeferNotifications"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.Int("quantity", len(mods))))
+	defer span.End()
+
+	if len(mods) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	builder := sq.Insert(t.DeferredTable).
+		Columns(t.IdempotencyKey, t.UserID, t.Type, t.Body, t.SendAt, t.CreatedAt).
+		Suffix("on conflict (" + t.IdempotencyKey + ") do nothing").
+		PlaceholderFormat(sq.Dollar)
+
+	for _, mod := range mods {
+		builder = builder.Values(mod.IdempotencyKey, mod.UserID, mod.Type, mod.Body, mod.SendAt, now)
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err = r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
@@ -1,4 +1,4 @@
-package booking
+package notification
 
 import (
 	t "booking-schedule/internal/app/repository/table"
@@ -7,14 +7,14 @@ import (
 	"context"
 	"errors"
 	"log/slog"
-	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"go.opentelemetry.io/otel/codes"
 )
 
-func (r *repository) DeleteBookingsBeforeDate(ctx context.Context, date time.Time) error {
-	const op = "repository.booking.DeleteBookingsBeforeDate"
+// DeleteNotifications removes deferred notifications once they are sent or dropped.
+func (r *repository) DeleteNotifications(ctx context.Context, keys []string) error {
+	const op = "repository.notification.DeleteNotifications"
 
 	log := r.log.With(
 		slog.String("op", op),
@@ -22,8 +22,12 @@ func (r *repository) DeleteBookingsBeforeDate(ctx context.Context, date time.Tim
 	ctx, span := r.tracer.Start(ctx, op)
 	defer span.End()
 
-	builder := sq.Delete(t.BookingTable).
-		Where(sq.Lt{t.EndDate: date}).
+	if len(keys) == 0 {
+		return nil
+	}
+
+	builder := sq.Delete(t.DeferredTable).
+		Where(sq.Eq{t.IdempotencyKey: keys}).
 		PlaceholderFormat(sq.Dollar)
 
 	query, args, err := builder.ToSql()
@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// GetDueNotifications returns deferred notifications that are to be sent by the date. Notifications
+// of types the recipient has opted out of since they were deferred are marked as disabled.
+func (r *repository) GetDueNotifications(ctx context.Context, date time.Time) ([]*model.DeferredNotification, error) {
+	const op = "repository.notification.GetDueNotifications"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	d := t.DeferredTable + "."
+	builder := sq.Select(
+		d+t.IdempotencyKey, d+t.UserID, d+t.Type, d+t.Body, d+t.SendAt,
+		"coalesce("+d+t.Type+" = any("+t.PreferencesTable+"."+t.DisabledTypes+"), false) as disabled",
+	).
+		From(t.DeferredTable).
+		LeftJoin(t.PreferencesTable + " using (" + t.UserID + ")").
+		Where(sq.LtOrEq{d + t.SendAt: date}).
+		OrderBy(d + t.SendAt).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var res []*model.DeferredNotification
+	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return res, nil
+}
@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Repository interface {
+	DeferNotifications(ctx context.Context, mods []*model.DeferredNotification) error
+	GetDueNotifications(ctx context.Context, date time.Time) ([]*model.DeferredNotification, error)
+	DeleteNotifications(ctx context.Context, keys []string) error
+}
+
+var (
+	ErrQuery        = errors.New("failed to execute query")
+	ErrQueryBuild   = errors.New("failed to build query")
+	ErrNoConnection = errors.New("could not connect to database")
+
+	pgNoConnection = new(*pgconn.ConnectError)
+)
+
+type repository struct {
+	client db.Client
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewNotificationRepository(client db.Client, log *slog.Logger, tracer trace.Tracer) Repository {
+	return &repository{
+		client: client,
+		log:    log,
+		tracer: tracer,
+	}
+}
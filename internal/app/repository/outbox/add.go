@@ -0,0 +1,69 @@
+package outbox
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AddEvent stores the event to be relayed to the broker. It is meant to be called in the transaction
+// that changes the data the event is about.
+func (r *repository) AddEvent(ctx context.Context, mod *model.OutboxEvent) error {
+	const op = "repository.outbox.AddEvent"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	builder := sq.Insert(t.OutboxTable).
+		Columns(t.MessageID, t.Type, t.RoutingKey, t.Body, t.CreatedAt).
+		Values(mod.MessageID, mod.Type, mod.RoutingKey, string(mod.Body), mod.CreatedAt).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err = r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.String("routing_key", mod.RoutingKey)))
+
+	return nil
+}
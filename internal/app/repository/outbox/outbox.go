@@ -0,0 +1,41 @@
+package outbox
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Repository interface {
+	AddEvent(ctx context.Context, mod *model.OutboxEvent) error
+	ClaimEvents(ctx context.Context, now time.Time, lease time.Duration, limit uint64) ([]*model.OutboxEvent, error)
+	DeleteEvents(ctx context.Context, ids []int64) error
+}
+
+var (
+	ErrQuery        = errors.New("failed to execute query")
+	ErrQueryBuild   = errors.New("failed to build query")
+	ErrNoConnection = errors.New("could not connect to database")
+
+	pgNoConnection = new(*pgconn.ConnectError)
+)
+
+type repository struct {
+	client db.Client
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewOutboxRepository(client db.Client, log *slog.Logger, tracer trace.Tracer) Repository {
+	return &repository{
+		client: client,
+		log:    log,
+		tracer: tracer,
+	}
+}
@@ -0,0 +1,126 @@
+package outbox
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ClaimEvents leases up to limit of the oldest events that are not leased by another relay, so that
+// several instances of the service never publish the same event at once. Events that are not deleted
+// within lease, e.g. because the broker was down, are claimed again.
+func (r *repository) ClaimEvents(ctx context.Context, now time.Time, lease time.Duration, limit uint64) ([]*model.OutboxEvent, error) {
+	const op = "repository.outbox.ClaimEvents"
+
+	log := r.log.With(slog.String("op", op))
+
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	due := sq.Select(t.ID).
+		From(t.OutboxTable).
+		Where(sq.Or{
+			sq.Eq{t.LockedUntil: nil},
+			sq.LtOrEq{t.LockedUntil: now},
+		}).
+		OrderBy(t.ID).
+		Limit(limit).
+		Suffix("for update skip locked")
+
+	builder := sq.Update(t.OutboxTable).
+		Set(t.LockedUntil, now.Add(lease)).
+		Where(sq.Expr(t.ID+" in (?)", due)).
+		Suffix("returning " + t.ID + ", " + t.MessageID + ", " + t.Type + ", " + t.RoutingKey + ", " + t.Body + ", " + t.CreatedAt).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var res []*model.OutboxEvent
+	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int("claimed", len(res))))
+
+	return res, nil
+}
+
+// DeleteEvents removes the events that have been relayed.
+func (r *repository) DeleteEvents(ctx context.Context, ids []int64) error {
+	const op = "repository.outbox.DeleteEvents"
+
+	log := r.log.With(slog.String("op", op))
+
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	builder := sq.Delete(t.OutboxTable).
+		Where(sq.Eq{t.ID: ids}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err = r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int("quantity", len(ids))))
+
+	return nil
+}
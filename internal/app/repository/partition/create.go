@@ -0,0 +1,65 @@
+package partition
+
+import (
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CreatePartition creates the partition for the month of the date unless it exists. Bookings of the month
+// that got into the default partition are moved into the new one. Returns whether the partition was created.
+func (r *repository) CreatePartition(ctx context.Context, month time.Time) (bool, error) {
+	const op = "repository.partition.CreatePartition"
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("month", month.Format("2006-01")),
+	)
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("month", month.Format("2006-01"))))
+	defer span.End()
+
+	// секция создается функцией из миграции, чтобы перенос строк из секции по умолчанию и присоединение были атомарны
+	builder := sq.Select().
+		Column(sq.Expr("create_bookings_partition(?::timestamp)", month)).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return false, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var created bool
+	err = r.client.DB().QueryRowContext(ctx, q, args...).Scan(&created)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return false, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return false, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Bool("created", created)))
+
+	return created, nil
+}
@@ -0,0 +1,122 @@
+package partition
+
+import (
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HasBookings tells whether the partition holds bookings ending at the date or later, zero date matches any booking.
+func (r *repository) HasBookings(ctx context.Context, partition string, endingAfter time.Time) (bool, error) {
+	const op = "repository.partition.HasBookings"
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("partition", partition),
+	)
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("partition", partition)))
+	defer span.End()
+
+	builder := sq.Select("1").
+		From(pgx.Identifier{partition}.Sanitize()).
+		Prefix("SELECT EXISTS (").
+		Suffix(")").
+		PlaceholderFormat(sq.Dollar)
+	if !endingAfter.IsZero() {
+		builder = builder.Where(sq.GtOrEq{t.EndDate: endingAfter})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return false, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var exists bool
+	err = r.client.DB().QueryRowContext(ctx, q, args...).Scan(&exists)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return false, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return false, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return exists, nil
+}
+
+// DetachPartition detaches the partition from the bookings table, the partition is kept as a table of its own.
+func (r *repository) DetachPartition(ctx context.Context, partition string) error {
+	const op = "repository.partition.DetachPartition"
+
+	return r.exec(ctx, op, partition, "ALTER TABLE "+t.BookingTable+" DETACH PARTITION "+pgx.Identifier{partition}.Sanitize())
+}
+
+// DropPartition drops the partition together with its bookings.
+func (r *repository) DropPartition(ctx context.Context, partition string) error {
+	const op = "repository.partition.DropPartition"
+
+	return r.exec(ctx, op, partition, "DROP TABLE "+pgx.Identifier{partition}.Sanitize())
+}
+
+// DeleteReminders deletes the reminders of the bookings in the partition. Detaching or dropping the partition
+// does not fire the delete trigger of the bookings table, so it has to be called in the same transaction.
+func (r *repository) DeleteReminders(ctx context.Context, partition string) error {
+	const op = "repository.partition.DeleteReminders"
+
+	return r.exec(ctx, op, partition, "DELETE FROM "+t.ReminderTable+" WHERE "+t.BookingID+" IN (SELECT "+t.ID+" FROM "+pgx.Identifier{partition}.Sanitize()+")")
+}
+
+func (r *repository) exec(ctx context.Context, op string, partition string, query string) error {
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("partition", partition),
+	)
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("partition", partition)))
+	defer span.End()
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err := r.client.DB().ExecContext(ctx, q)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
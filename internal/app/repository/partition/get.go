@@ -0,0 +1,94 @@
+package partition
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetPartitions returns the monthly partitions attached to the bookings table, the earliest first.
+// The default partition is not returned.
+func (r *repository) GetPartitions(ctx context.Context) ([]*model.Partition, error) {
+	const op = "repository.partition.GetPartitions"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	builder := sq.Select("c.relname").
+		From("pg_inherits i").
+		Join("pg_class c on c.oid = i.inhrelid").
+		Where(sq.Expr("i.inhparent = ?::regclass", t.BookingTable)).
+		OrderBy("c.relname").
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var names []string
+	err = r.client.DB().SelectContext(ctx, &names, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	res := make([]*model.Partition, 0, len(names))
+	for _, name := range names {
+		month, ok := parseMonth(name)
+		if !ok {
+			continue
+		}
+
+		res = append(res, &model.Partition{Name: name, Month: month})
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int("quantity", len(res))))
+
+	return res, nil
+}
+
+// parseMonth reads the month from the name of a partition, e.g. bookings_y2024m03.
+func parseMonth(name string) (time.Time, bool) {
+	suffix, ok := strings.CutPrefix(name, t.BookingTable+"_y")
+	if !ok {
+		return time.Time{}, false
+	}
+
+	month, err := time.Parse("2006m01", suffix)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return month, true
+}
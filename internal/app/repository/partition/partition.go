@@ -0,0 +1,45 @@
+package partition
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Repository maintains the monthly partitions of the bookings table.
+type Repository interface {
+	CreatePartition(ctx context.Context, month time.Time) (bool, error)
+	GetPartitions(ctx context.Context) ([]*model.Partition, error)
+	HasBookings(ctx context.Context, partition string, endingAfter time.Time) (bool, error)
+	DetachPartition(ctx context.Context, partition string) error
+	DropPartition(ctx context.Context, partition string) error
+	DeleteReminders(ctx context.Context, partition string) error
+}
+
+var (
+	ErrQuery        = errors.New("failed to execute query")
+	ErrQueryBuild   = errors.New("failed to build query")
+	ErrNoConnection = errors.New("could not connect to database")
+
+	pgNoConnection = new(*pgconn.ConnectError)
+)
+
+type repository struct {
+	client db.Client
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewPartitionRepository(client db.Client, log *slog.Logger, tracer trace.Tracer) Repository {
+	return &repository{
+		client: client,
+		log:    log,
+		tracer: tracer,
+	}
+}
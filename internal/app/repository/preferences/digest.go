@@ -0,0 +1,126 @@
+package preferences
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// GetDueDigests returns the users whose digest time has come in their time zone
+// and who have not received the digest for their local date yet.
+func (r *repository) GetDueDigests(ctx context.Context, now time.Time) ([]*model.DigestRecipient, error) {
+	const op = "repository.preferences.GetDueDigests"
+
+	log := r.log.With(slog.String("op", op))
+
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	u, p := t.UserTable+".", t.PreferencesTable+"."
+	local := "((?::timestamp at time zone 'UTC') at time zone " + u + t.Timezone + ")"
+
+	builder := sq.Select(
+		p+t.UserID,
+		u+t.Name, u+t.TelegramID, u+t.Language, u+t.Timezone,
+		"to_char("+p+t.QuietHoursStart+", 'HH24:MI') as "+t.QuietHoursStart,
+		"to_char("+p+t.QuietHoursEnd+", 'HH24:MI') as "+t.QuietHoursEnd,
+		p+t.DisabledTypes,
+	).
+		From(t.PreferencesTable).
+		Join(t.UserTable + " on " + u + t.ID + " = " + p + t.UserID).
+		Where(sq.And{
+			sq.NotEq{p + t.DigestTime: nil},
+			sq.Expr(local+"::time >= "+p+t.DigestTime, now),
+			sq.Or{
+				sq.Eq{p + t.DigestSentOn: nil},
+				sq.Expr(p+t.DigestSentOn+" < "+local+"::date", now),
+			},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var res []*model.DigestRecipient
+	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return res, nil
+}
+
+// MarkDigestSent records that the user has received the digest for the local date.
+func (r *repository) MarkDigestSent(ctx context.Context, userID int64, date time.Time) error {
+	const op = "repository.preferences.MarkDigestSent"
+
+	log := r.log.With(slog.String("op", op))
+
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	builder := sq.Update(t.PreferencesTable).
+		Set(t.DigestSentOn, date.Format(time.DateOnly)).
+		Where(sq.Eq{t.UserID: userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err = r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package preferences
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/middleware"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetPreferences returns the preferences of the user. Users who never set them get empty preferences.
+func (r *repository) GetPreferences(ctx context.Context, userID int64) (*model.Preferences, error) {
+	const op = "repository.preferences.GetPreferences"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	builder := sq.Select(
+		t.UserID, t.LeadTimes, t.EndLeadTimes,
+		"to_char("+t.QuietHoursStart+", 'HH24:MI') as "+t.QuietHoursStart,
+		"to_char("+t.QuietHoursEnd+", 'HH24:MI') as "+t.QuietHoursEnd,
+		t.DisabledTypes,
+		"to_char("+t.DigestTime+", 'HH24:MI') as "+t.DigestTime,
+		t.CreatedAt, t.UpdatedAt,
+	).
+		From(t.PreferencesTable).
+		Where(sq.Eq{t.UserID: userID}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var res = new(model.Preferences)
+	err = r.client.DB().GetContext(ctx, res, q, args...)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			span.AddEvent("no preferences set")
+			return &model.Preferences{UserID: userID}, nil
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return res, nil
+}
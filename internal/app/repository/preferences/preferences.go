@@ -0,0 +1,48 @@
+package preferences
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Repository interface {
+	GetPreferences(ctx context.Context, userID int64) (*model.Preferences, error)
+	SetPreferences(ctx context.Context, mod *model.Preferences) error
+	GetDueDigests(ctx context.Context, now time.Time) ([]*model.DigestRecipient, error)
+	MarkDigestSent(ctx context.Context, userID int64, date time.Time) error
+}
+
+var (
+	ErrQuery        = errors.New("failed to execute query")
+	ErrQueryBuild   = errors.New("failed to build query")
+	ErrNoConnection = errors.New("could not connect to database")
+	ErrNoSuchUser   = errors.New("no user with this id")
+
+	pgNoConnection = new(*pgconn.ConnectError)
+	pgNoSuchUser   = &pgconn.PgError{
+		Severity:       "ERROR",
+		Code:           "23503",
+		Message:        "violates foreign key constraint",
+		ConstraintName: "fk_users"}
+)
+
+type repository struct {
+	client db.Client
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewPreferencesRepository(client db.Client, log *slog.Logger, tracer trace.Tracer) Repository {
+	return &repository{
+		client: client,
+		log:    log,
+		tracer: tracer,
+	}
+}
@@ -0,0 +1,99 @@
+package preferences
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/middleware"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SetPreferences replaces the preferences of the user.
+func (r *repository) SetPreferences(ctx context.Context, mod *model.Preferences) error {
+	const op = "repository.preferences.SetPreferences"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	leadTimes := mod.LeadTimes
+	if leadTimes == nil {
+		leadTimes = []time.Duration{}
+	}
+
+	endLeadTimes := mod.EndLeadTimes
+	if endLeadTimes == nil {
+		endLeadTimes = []time.Duration{}
+	}
+
+	disabledTypes := mod.DisabledTypes
+	if disabledTypes == nil {
+		disabledTypes = []string{}
+	}
+
+	now := time.Now()
+	builder := sq.Insert(t.PreferencesTable).
+		Columns(t.UserID, t.LeadTimes, t.EndLeadTimes, t.QuietHoursStart, t.QuietHoursEnd, t.DisabledTypes, t.DigestTime, t.CreatedAt).
+		Values(mod.UserID, leadTimes, endLeadTimes, mod.QuietHoursStart, mod.QuietHoursEnd, disabledTypes, mod.DigestTime, now).
+		Suffix("on conflict ("+t.UserID+") do update set "+
+			t.LeadTimes+" = excluded."+t.LeadTimes+", "+
+			t.EndLeadTimes+" = excluded."+t.EndLeadTimes+", "+
+			t.QuietHoursStart+" = excluded."+t.QuietHoursStart+", "+
+			t.QuietHoursEnd+" = excluded."+t.QuietHoursEnd+", "+
+			t.DisabledTypes+" = excluded."+t.DisabledTypes+", "+
+			t.DigestTime+" = excluded."+t.DigestTime+", "+
+			t.UpdatedAt+" = ?", now).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	_, err = r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgNoSuchUser.Code {
+			log.Error("user does not exist", sl.Err(err))
+			return ErrNoSuchUser
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
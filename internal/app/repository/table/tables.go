@@ -10,11 +10,68 @@ const (
 	StartDate        = `start_date`
 	EndDate          = `end_date`
 	NotifyAt         = `notify_at`
+	NotifyBeforeEnd  = `notify_before_end`
 	CreatedAt        = `created_at`
 	UpdatedAt        = `updated_at`
+	ConfirmedAt      = `confirmed_at`
 	Name             = `name`
 	Capacity         = `capacity`
 	TelegramNickname = `telegram_nickname`
 	TelegramID       = `telegram_id`
 	Password         = `password`
+	Language         = `language`
+	Timezone         = `timezone`
+
+	ReminderTable = `booking_reminders`
+	BookingID     = `booking_id`
+	Kind          = `kind`
+	LeadTime      = `lead_time`
+	SentAt        = `sent_at`
+
+	PreferencesTable = `user_preferences`
+	LeadTimes        = `lead_times`
+	EndLeadTimes     = `end_lead_times`
+	QuietHoursStart  = `quiet_hours_start`
+	QuietHoursEnd    = `quiet_hours_end`
+	DisabledTypes    = `disabled_types`
+	DigestTime       = `digest_time`
+	DigestSentOn     = `digest_sent_on`
+
+	DeferredTable  = `deferred_notifications`
+	IdempotencyKey = `idempotency_key`
+	Type           = `type`
+	Body           = `body`
+	SendAt         = `send_at`
+
+	WebhookTable  = `webhooks`
+	URL           = `url`
+	Secret        = `secret`
+	Events        = `events`
+	AllBookings   = `all_bookings`
+	IsAdmin       = `is_admin`
+	DeliveryTable = `webhook_deliveries`
+	WebhookID     = `webhook_id`
+	Event         = `event`
+	Payload       = `payload`
+	Status        = `status`
+	Attempts      = `attempts`
+	NextAttemptAt = `next_attempt_at`
+	ResponseCode  = `response_status`
+	Error         = `error`
+	DeliveredAt   = `delivered_at`
+
+	OutboxTable = `event_outbox`
+	MessageID   = `message_id`
+	RoutingKey  = `routing_key`
+	LockedUntil = `locked_until`
+
+	SentTable = `sent_notifications`
+
+	ArchiveTable = `bookings_archive`
+	ArchivedAt   = `archived_at`
+
+	UsedTokensTable = `used_action_tokens`
+	TokenID         = `token_id`
+	ExpiresAt       = `expires_at`
+	UsedAt          = `used_at`
 )
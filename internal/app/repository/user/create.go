@@ -32,9 +32,23 @@ func (r *repository) CreateUser(ctx context.Context, user *model.User) (int64, e
 	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
 	defer span.End()
 
+	columns := []string{t.TelegramID, t.TelegramNickname, t.Name, t.Password, t.CreatedAt}
+	values := []interface{}{user.TelegramID, user.Nickname, user.Name, user.Password, time.Now()}
+
+	// при отсутствии значений используются значения по умолчанию из схемы
+	if user.Language != "" {
+		columns = append(columns, t.Language)
+		values = append(values, user.Language)
+	}
+
+	if user.Timezone != "" {
+		columns = append(columns, t.Timezone)
+		values = append(values, user.Timezone)
+	}
+
 	builder := sq.Insert(t.UserTable).
-		Columns(t.TelegramID, t.TelegramNickname, t.Name, t.Password, t.CreatedAt).
-		Values(user.TelegramID, user.Nickname, user.Name, user.Password, time.Now())
+		Columns(columns...).
+		Values(values...)
 
 	query, args, err := builder.PlaceholderFormat(sq.Dollar).Suffix("returning id").ToSql()
 	if err != nil {
@@ -47,6 +47,14 @@ func (r *repository) EditUser(ctx context.Context, user *model.UpdateUserInfo) e
 		builder = builder.Set(t.Password, user.Password.String)
 	}
 
+	if user.Language.Valid {
+		builder = builder.Set(t.Language, user.Language.String)
+	}
+
+	if user.Timezone.Valid {
+		builder = builder.Set(t.Timezone, user.Timezone.String)
+	}
+
 	query, args, err := builder.PlaceholderFormat(sq.Dollar).ToSql()
 	if err != nil {
 		span.RecordError(err)
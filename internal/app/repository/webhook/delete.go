@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DeleteWebhook removes the webhook of the user together with its delivery log.
+func (r *repository) DeleteWebhook(ctx context.Context, webhookID int64, userID int64) error {
+	const op = "repository.webhook.DeleteWebhook"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	builder := sq.Delete(t.WebhookTable).
+		Where(sq.And{
+			sq.Eq{t.ID: webhookID},
+			sq.Eq{t.UserID: userID},
+		}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	result, err := r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	if result.RowsAffected() == 0 {
+		span.RecordError(ErrNotFound)
+		span.SetStatus(codes.Error, ErrNotFound.Error())
+		log.Error("webhook not found", sl.Err(ErrNotFound))
+		return ErrNotFound
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
@@ -0,0 +1,222 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/go-chi/chi/middleware"
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// EnqueueDeliveries adds a pending delivery of the event to every webhook subscribed to it, that is either
+// owned by the user the booking belongs to or receives events about all bookings.
+func (r *repository) EnqueueDeliveries(ctx context.Context, event string, userID int64, payload []byte) error {
+	const op = "repository.webhook.EnqueueDeliveries"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	now := time.Now()
+
+	subscribers := sq.Select(t.ID).
+		Column(sq.Expr("?::text", event)).
+		Column(sq.Expr("?::jsonb", string(payload))).
+		Column(sq.Expr("?::timestamp", now)).
+		Column(sq.Expr("?::timestamp", now)).
+		From(t.WebhookTable).
+		Where(sq.And{
+			sq.Expr("?::text = any("+t.Events+")", event),
+			sq.Or{
+				sq.Eq{t.UserID: userID},
+				sq.Eq{t.AllBookings: true},
+			},
+		})
+
+	builder := sq.Insert(t.DeliveryTable).
+		Columns(t.WebhookID, t.Event, t.Payload, t.NextAttemptAt, t.CreatedAt).
+		Select(subscribers).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	result, err := r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int64("enqueued", result.RowsAffected())))
+
+	return nil
+}
+
+// GetDeliveries returns the latest deliveries of the webhook owned by the user, newest first.
+func (r *repository) GetDeliveries(ctx context.Context, webhookID int64, userID int64, limit uint64) ([]*model.WebhookDelivery, error) {
+	const op = "repository.webhook.GetDeliveries"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	d, w := t.DeliveryTable+".", t.WebhookTable+"."
+
+	builder := sq.Select(
+		d+t.ID, d+t.WebhookID, d+t.Event, d+t.Payload, d+t.Status, d+t.Attempts, d+t.NextAttemptAt,
+		d+t.ResponseCode, d+t.Error, d+t.CreatedAt, d+t.DeliveredAt,
+	).
+		From(t.DeliveryTable).
+		Join(t.WebhookTable + " on " + w + t.ID + " = " + d + t.WebhookID).
+		Where(sq.And{
+			sq.Eq{d + t.WebhookID: webhookID},
+			sq.Eq{w + t.UserID: userID},
+		}).
+		OrderBy(d + t.ID + " desc").
+		Limit(limit).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var res []*model.WebhookDelivery
+	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return res, nil
+}
+
+// ReplayDelivery enqueues a copy of the delivery of the webhook owned by the user and returns its id.
+// The original delivery is kept in the log as it is.
+func (r *repository) ReplayDelivery(ctx context.Context, webhookID int64, deliveryID int64, userID int64) (int64, error) {
+	const op = "repository.webhook.ReplayDelivery"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := r.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	d, w := t.DeliveryTable+".", t.WebhookTable+"."
+	now := time.Now()
+
+	original := sq.Select(d+t.WebhookID, d+t.Event, d+t.Payload).
+		Column(sq.Expr("?::timestamp", now)).
+		Column(sq.Expr("?::timestamp", now)).
+		From(t.DeliveryTable).
+		Join(t.WebhookTable + " on " + w + t.ID + " = " + d + t.WebhookID).
+		Where(sq.And{
+			sq.Eq{d + t.ID: deliveryID},
+			sq.Eq{d + t.WebhookID: webhookID},
+			sq.Eq{w + t.UserID: userID},
+		})
+
+	builder := sq.Insert(t.DeliveryTable).
+		Columns(t.WebhookID, t.Event, t.Payload, t.NextAttemptAt, t.CreatedAt).
+		Select(original).
+		Suffix("returning " + t.ID).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return 0, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var id int64
+	err = r.client.DB().GetContext(ctx, &id, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return 0, ErrNoConnection
+		}
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Error("delivery with this id not found", sl.Err(err))
+			return 0, ErrNotFound
+		}
+		log.Error("query execution error", sl.Err(err))
+		return 0, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int64("id", id)))
+
+	return id, nil
+}
@@ -0,0 +1,143 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/model"
+	t "booking-schedule/internal/app/repository/table"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ClaimDeliveries picks up to limit pending deliveries due by now and leases them for an attempt: their attempt
+// counter is incremented and the next attempt is postponed by lease, so that a delivery is retried if the
+// dispatcher dies before completing it. Rows claimed concurrently by other dispatchers are skipped.
+func (r *repository) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit uint64) ([]*model.PendingDelivery, error) {
+	const op = "repository.webhook.ClaimDeliveries"
+
+	log := r.log.With(slog.String("op", op))
+
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	d, w := t.DeliveryTable+".", t.WebhookTable+"."
+
+	due := sq.Select(t.ID).
+		From(t.DeliveryTable).
+		Where(sq.And{
+			sq.Eq{t.Status: model.DeliveryPending},
+			sq.LtOrEq{t.NextAttemptAt: now},
+		}).
+		OrderBy(t.NextAttemptAt).
+		Limit(limit).
+		Suffix("for update skip locked")
+
+	builder := sq.Update(t.DeliveryTable).
+		Set(t.Attempts, sq.Expr(d+t.Attempts+" + 1")).
+		Set(t.NextAttemptAt, now.Add(lease)).
+		From(t.WebhookTable).
+		Where(sq.And{
+			sq.Expr(w + t.ID + " = " + d + t.WebhookID),
+			sq.Expr(d+t.ID+" in (?)", due),
+		}).
+		Suffix("returning " + d + t.ID + ", " + d + t.WebhookID + ", " + d + t.Event + ", " + d + t.Payload + ", " +
+			d + t.Attempts + ", " + w + t.URL + ", " + w + t.Secret).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return nil, ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	var res []*model.PendingDelivery
+	err = r.client.DB().SelectContext(ctx, &res, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return nil, ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return nil, ErrQuery
+	}
+
+	span.AddEvent("query successfully executed", trace.WithAttributes(attribute.Int("claimed", len(res))))
+
+	return res, nil
+}
+
+// CompleteDelivery records the outcome of an attempt: the status, the response of the receiver and,
+// for deliveries to be retried, the time of the next attempt.
+func (r *repository) CompleteDelivery(ctx context.Context, mod *model.WebhookDelivery) error {
+	const op = "repository.webhook.CompleteDelivery"
+
+	log := r.log.With(slog.String("op", op))
+
+	ctx, span := r.tracer.Start(ctx, op)
+	defer span.End()
+
+	builder := sq.Update(t.DeliveryTable).
+		Set(t.Status, mod.Status).
+		Set(t.NextAttemptAt, mod.NextAttemptAt).
+		Set(t.ResponseCode, mod.ResponseStatus).
+		Set(t.Error, mod.Error).
+		Set(t.DeliveredAt, mod.DeliveredAt).
+		Where(sq.Eq{t.ID: mod.ID}).
+		PlaceholderFormat(sq.Dollar)
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to build a query", sl.Err(err))
+		return ErrQueryBuild
+	}
+
+	span.AddEvent("query built")
+
+	q := db.Query{
+		Name:     op,
+		QueryRaw: query,
+	}
+
+	result, err := r.client.DB().ExecContext(ctx, q, args...)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.As(err, pgNoConnection) {
+			log.Error("no connection to database host", sl.Err(err))
+			return ErrNoConnection
+		}
+		log.Error("query execution error", sl.Err(err))
+		return ErrQuery
+	}
+
+	if result.RowsAffected() == 0 {
+		span.RecordError(ErrNotFound)
+		span.SetStatus(codes.Error, ErrNotFound.Error())
+		log.Error("delivery not found", sl.Err(ErrNotFound))
+		return ErrNotFound
+	}
+
+	span.AddEvent("query successfully executed")
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Repository interface {
+	AddWebhook(ctx context.Context, mod *model.Webhook) (int64, error)
+	GetWebhooks(ctx context.Context, userID int64) ([]*model.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID int64, userID int64) error
+	EnqueueDeliveries(ctx context.Context, event string, userID int64, payload []byte) error
+	GetDeliveries(ctx context.Context, webhookID int64, userID int64, limit uint64) ([]*model.WebhookDelivery, error)
+	ReplayDelivery(ctx context.Context, webhookID int64, deliveryID int64, userID int64) (int64, error)
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit uint64) ([]*model.PendingDelivery, error)
+	CompleteDelivery(ctx context.Context, mod *model.WebhookDelivery) error
+}
+
+var (
+	ErrNotFound = errors.New("no webhook or delivery with this id")
+
+	ErrQuery        = errors.New("failed to execute query")
+	ErrQueryBuild   = errors.New("failed to build query")
+	ErrNoConnection = errors.New("could not connect to database")
+
+	pgNoConnection = new(*pgconn.ConnectError)
+)
+
+type repository struct {
+	client db.Client
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+func NewWebhookRepository(client db.Client, log *slog.Logger, tracer trace.Tracer) Repository {
+	return &repository{
+		client: client,
+		log:    log,
+		tracer: tracer,
+	}
+}
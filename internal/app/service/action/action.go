@@ -0,0 +1,170 @@
+package action
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/gofrs/uuid"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Service issues and verifies the signed, expiring tokens of actions users take from notifications.
+type Service interface {
+	GenerateToken(ctx context.Context, action *model.BookingAction, expiresAt time.Time) (string, error)
+	VerifyToken(ctx context.Context, token string) (*model.BookingAction, error)
+}
+
+// audience keeps action tokens from being accepted anywhere else, e.g. as session tokens.
+const audience = "booking-action"
+
+var actions = []string{model.ActionConfirm, model.ActionCancel, model.ActionExtend}
+
+var (
+	ErrUnsupportedSign = errors.New("unexpected signing method")
+	ErrInvalidToken    = errors.New("invalid or expired action token")
+	ErrUnknownAction   = errors.New("unknown booking action")
+)
+
+type claims struct {
+	Action    string    `json:"action"`
+	BookingID uuid.UUID `json:"bookingID"`
+	UserID    int64     `json:"userID"`
+	jwt.RegisteredClaims
+}
+
+type service struct {
+	secret string
+	log    *slog.Logger
+	tracer trace.Tracer
+}
+
+// NewActionService creates a service that signs action tokens with the secret. The secret must differ from
+// the one session tokens are signed with.
+func NewActionService(secret string, log *slog.Logger, tracer trace.Tracer) Service {
+	return &service{secret, log, tracer}
+}
+
+// GenerateToken signs the action on behalf of its user, the token is valid until expiresAt.
+func (s *service) GenerateToken(ctx context.Context, action *model.BookingAction, expiresAt time.Time) (string, error) {
+	const op = "service.action.GenerateToken"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	_, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	if !slices.Contains(actions, action.Action) {
+		span.RecordError(ErrUnknownAction)
+		span.SetStatus(codes.Error, ErrUnknownAction.Error())
+		log.Error("invalid action", sl.Err(ErrUnknownAction), slog.String("action", action.Action))
+		return "", ErrUnknownAction
+	}
+
+	// идентификатор токена запоминается при использовании, так что ссылка срабатывает один раз
+	tokenID, err := uuid.NewV4()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to generate token id", sl.Err(err))
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims{
+		Action:    action.Action,
+		BookingID: action.BookingID,
+		UserID:    action.UserID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID.String(),
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	span.AddEvent("action token generated", trace.WithAttributes(
+		attribute.String("action", action.Action),
+		attribute.String("booking_id", action.BookingID.String()),
+	))
+
+	return token.SignedString([]byte(s.secret))
+}
+
+// VerifyToken checks the signature, audience and expiration of the token and returns the action it authorizes.
+// Whether the token has been used is up to the caller taking the action, see booking.Service.TakeAction.
+func (s *service) VerifyToken(ctx context.Context, tokenString string) (*model.BookingAction, error) {
+	const op = "service.action.VerifyToken"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	_, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	res := new(claims)
+	_, err := jwt.ParseWithClaims(tokenString, res, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, ErrUnsupportedSign
+		}
+		return []byte(s.secret), nil
+	}, jwt.WithAudience(audience), jwt.WithExpirationRequired())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("parsing action token failed", sl.Err(err))
+		return nil, ErrInvalidToken
+	}
+
+	if res.UserID == 0 || res.BookingID == uuid.Nil {
+		span.RecordError(ErrInvalidToken)
+		span.SetStatus(codes.Error, ErrInvalidToken.Error())
+		log.Error("action token without booking or user", sl.Err(ErrInvalidToken))
+		return nil, ErrInvalidToken
+	}
+
+	// токены без идентификатора нельзя пометить использованными
+	tokenID, err := uuid.FromString(res.ID)
+	if err != nil || tokenID == uuid.Nil {
+		span.RecordError(ErrInvalidToken)
+		span.SetStatus(codes.Error, ErrInvalidToken.Error())
+		log.Error("action token without id", sl.Err(ErrInvalidToken))
+		return nil, ErrInvalidToken
+	}
+
+	if !slices.Contains(actions, res.Action) {
+		span.RecordError(ErrUnknownAction)
+		span.SetStatus(codes.Error, ErrUnknownAction.Error())
+		log.Error("invalid action", sl.Err(ErrUnknownAction), slog.String("action", res.Action))
+		return nil, ErrUnknownAction
+	}
+
+	span.AddEvent("action token verified", trace.WithAttributes(
+		attribute.String("action", res.Action),
+		attribute.String("booking_id", res.BookingID.String()),
+	))
+
+	return &model.BookingAction{
+		Action:    res.Action,
+		BookingID: res.BookingID,
+		UserID:    res.UserID,
+		TokenID:   tokenID,
+		ExpiresAt: res.ExpiresAt.Time,
+	}, nil
+}
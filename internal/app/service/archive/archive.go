@@ -0,0 +1,76 @@
+package archive
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/app/repository/archive"
+	"booking-schedule/internal/app/repository/user"
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Service lets admins look into the bookings moved to the archive.
+type Service struct {
+	archiveRepository archive.Repository
+	userRepository    user.Repository
+	log               *slog.Logger
+	tracer            trace.Tracer
+}
+
+var ErrNotAdmin = errors.New("only admins may query the bookings archive")
+
+func NewArchiveService(archiveRepository archive.Repository, userRepository user.Repository, log *slog.Logger, tracer trace.Tracer) *Service {
+	return &Service{
+		archiveRepository: archiveRepository,
+		userRepository:    userRepository,
+		log:               log,
+		tracer:            tracer,
+	}
+}
+
+// GetArchivedBookings returns the archived bookings matching the filter.
+func (s *Service) GetArchivedBookings(ctx context.Context, userID int64, filter *model.ArchiveFilter) ([]*model.ArchivedBooking, error) {
+	const op = "service.archive.GetArchivedBookings"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	user, err := s.userRepository.GetUser(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to get user", sl.Err(err))
+		return nil, err
+	}
+
+	if !user.IsAdmin {
+		span.RecordError(ErrNotAdmin)
+		span.SetStatus(codes.Error, ErrNotAdmin.Error())
+		log.Error("access denied", sl.Err(ErrNotAdmin))
+		return nil, ErrNotAdmin
+	}
+
+	bookings, err := s.archiveRepository.GetArchivedBookings(ctx, filter)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to get archived bookings", sl.Err(err))
+		return nil, err
+	}
+
+	span.AddEvent("archived bookings acquired", trace.WithAttributes(attribute.Int("quantity", len(bookings))))
+
+	return bookings, nil
+}
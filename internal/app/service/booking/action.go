@@ -0,0 +1,73 @@
+package booking
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TakeAction takes the action authorized by a verified action token and returns the booking after it,
+// nil for cancellations. The token is marked as used in the same transaction, so that a replayed link
+// fails with booking.ErrTokenUsed instead of extending the booking once more, while a failed action
+// leaves the token valid.
+func (s *Service) TakeAction(ctx context.Context, action *model.BookingAction) (*model.BookingInfo, error) {
+	const op = "service.booking.TakeAction"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+		slog.String("action", action.Action),
+	)
+
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(
+		attribute.String("request_id", requestID),
+		attribute.String("action", action.Action),
+		attribute.String("booking_id", action.BookingID.String()),
+	))
+	defer span.End()
+
+	var booking *model.BookingInfo
+	err := s.txManager.Serializable(ctx, func(ctx context.Context) error {
+		errTx := s.bookingRepository.UseActionToken(ctx, action.TokenID, action.ExpiresAt)
+		if errTx != nil {
+			return errTx
+		}
+
+		booking = nil
+		switch action.Action {
+		case model.ActionConfirm:
+			errTx = s.ConfirmBooking(ctx, action.BookingID, action.UserID)
+			if errTx == nil {
+				booking, errTx = s.bookingRepository.GetBooking(ctx, action.BookingID, action.UserID)
+			}
+		case model.ActionCancel:
+			errTx = s.DeleteBooking(ctx, action.BookingID, action.UserID)
+		case model.ActionExtend:
+			booking, errTx = s.ExtendBooking(ctx, action.BookingID, action.UserID, model.BookingExtension)
+		}
+
+		return errTx
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("transaction failed", sl.Err(err))
+		if errors.As(err, pgNoConnection) {
+			return nil, ErrNoConnection
+		}
+		return nil, err
+	}
+
+	span.AddEvent("action taken")
+
+	return booking, nil
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"github.com/gofrs/uuid"
@@ -27,9 +28,17 @@ func (s *Service) AddBooking(ctx context.Context, mod *model.BookingInfo) (uuid.
 	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
 	defer span.End()
 
+	if len(mod.NotifyAt) > s.maxReminders || len(mod.NotifyBeforeEnd) > s.maxReminders {
+		span.RecordError(ErrTooManyReminders)
+		span.SetStatus(codes.Error, ErrTooManyReminders.Error())
+		log.Error("invalid request", sl.Err(ErrTooManyReminders))
+		return uuid.Nil, ErrTooManyReminders
+	}
+
 	var id uuid.UUID
 
-	err := s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+	// параллельное бронирование тех же дат приводит к ошибке сериализации, и проверка доступности повторяется
+	err := s.txManager.Serializable(ctx, func(ctx context.Context) error {
 		availibility, errTx := s.bookingRepository.CheckAvailibility(ctx, mod)
 		if errTx != nil {
 			span.RecordError(errTx)
@@ -54,6 +63,28 @@ func (s *Service) AddBooking(ctx context.Context, mod *model.BookingInfo) (uuid.
 			return errTx
 		}
 
+		for kind, leadTimes := range map[string][]time.Duration{
+			model.ReminderKindStart: mod.NotifyAt,
+			model.ReminderKindEnd:   mod.NotifyBeforeEnd,
+		} {
+			errTx = s.bookingRepository.SetReminders(ctx, id, kind, leadTimes)
+			if errTx != nil {
+				span.RecordError(errTx)
+				span.SetStatus(codes.Error, errTx.Error())
+				log.Error("failed to set reminders", sl.Err(errTx), slog.String("kind", kind))
+				return errTx
+			}
+		}
+
+		mod.ID = id
+		errTx = s.enqueueEvent(ctx, model.EventBookingCreated, mod)
+		if errTx != nil {
+			span.RecordError(errTx)
+			span.SetStatus(codes.Error, errTx.Error())
+			log.Error("failed to enqueue booking event", sl.Err(errTx))
+			return errTx
+		}
+
 		return nil
 	})
 
@@ -2,6 +2,8 @@ package booking
 
 import (
 	"booking-schedule/internal/app/repository/booking"
+	"booking-schedule/internal/app/repository/outbox"
+	"booking-schedule/internal/app/repository/webhook"
 	"booking-schedule/internal/app/service/jwt"
 	"booking-schedule/internal/pkg/db"
 	"errors"
@@ -13,25 +15,32 @@ import (
 
 type Service struct {
 	bookingRepository booking.Repository
+	webhookRepository webhook.Repository
+	outboxRepository  outbox.Repository
 	jwtService        jwt.Service
 	log               *slog.Logger
 	tracer            trace.Tracer
 	txManager         db.TxManager
+	maxReminders      int
 }
 
 var (
-	ErrNotAvailible = errors.New("this period is not availible for booking")
+	ErrNotAvailible     = errors.New("this period is not availible for booking")
+	ErrTooManyReminders = errors.New("too many reminders requested for the booking")
 
 	ErrNoConnection = errors.New("can't begin transaction, no connection to database")
 	pgNoConnection  = new(*pgconn.ConnectError)
 )
 
-func NewBookingService(bookingRepository booking.Repository, jwtService jwt.Service, log *slog.Logger, txManager db.TxManager, tracer trace.Tracer) *Service {
+func NewBookingService(bookingRepository booking.Repository, webhookRepository webhook.Repository, outboxRepository outbox.Repository, jwtService jwt.Service, log *slog.Logger, txManager db.TxManager, tracer trace.Tracer, maxReminders int) *Service {
 	return &Service{
 		bookingRepository: bookingRepository,
+		webhookRepository: webhookRepository,
+		outboxRepository:  outboxRepository,
 		jwtService:        jwtService,
 		log:               log,
 		tracer:            tracer,
 		txManager:         txManager,
+		maxReminders:      maxReminders,
 	}
 }
@@ -0,0 +1,40 @@
+package booking
+
+import (
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"log/slog"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ConfirmBooking records that the user will attend the booking.
+func (s *Service) ConfirmBooking(ctx context.Context, bookingID uuid.UUID, userID int64) error {
+	const op = "service.booking.ConfirmBooking"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	err := s.bookingRepository.ConfirmBooking(ctx, bookingID, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("the confirm booking operation failed", sl.Err(err))
+		return err
+	}
+
+	span.AddEvent("booking confirmed", trace.WithAttributes(attribute.String("booking_id", bookingID.String())))
+
+	return nil
+}
@@ -1,11 +1,72 @@
 package booking
 
 import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/logger/sl"
 	"context"
+	"errors"
+	"log/slog"
 
+	"github.com/go-chi/chi/middleware"
 	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func (s *Service) DeleteBooking(ctx context.Context, bookingID uuid.UUID, userID int64) error {
-	return s.bookingRepository.DeleteBooking(ctx, bookingID, userID)
+	const op = "service.booking.DeleteBooking"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	err := s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+		// бронирование запрашивается до удаления, чтобы описать его в событии об отмене
+		booking, errTx := s.bookingRepository.GetBooking(ctx, bookingID, userID)
+		if errTx != nil {
+			span.RecordError(errTx)
+			span.SetStatus(codes.Error, errTx.Error())
+			log.Error("failed to get booking", sl.Err(errTx))
+			return errTx
+		}
+
+		errTx = s.bookingRepository.DeleteBooking(ctx, bookingID, userID)
+		if errTx != nil {
+			span.RecordError(errTx)
+			span.SetStatus(codes.Error, errTx.Error())
+			log.Error("the delete booking operation failed", sl.Err(errTx))
+			return errTx
+		}
+
+		errTx = s.enqueueEvent(ctx, model.EventBookingCancelled, booking)
+		if errTx != nil {
+			span.RecordError(errTx)
+			span.SetStatus(codes.Error, errTx.Error())
+			log.Error("failed to enqueue booking event", sl.Err(errTx))
+			return errTx
+		}
+
+		span.AddEvent("transaction successful")
+
+		return nil
+	})
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("transaction failed", sl.Err(err))
+		if errors.As(err, pgNoConnection) {
+			return ErrNoConnection
+		}
+		return err
+	}
+
+	return nil
 }
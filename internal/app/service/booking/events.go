@@ -0,0 +1,51 @@
+package booking
+
+import (
+	"booking-schedule/internal/app/message"
+	"booking-schedule/internal/app/model"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// enqueueEvent schedules deliveries of the event about the booking to the webhooks subscribed to it and stores
+// the event in the outbox to be published to the broker. It is meant to be called in the transaction that
+// changes the booking, so that events are never lost or sent about changes that were rolled back.
+func (s *Service) enqueueEvent(ctx context.Context, event string, booking *model.BookingInfo) error {
+	payload := message.NewBookingEvent(event, booking)
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	err = s.webhookRepository.EnqueueDeliveries(ctx, event, booking.UserID, data)
+	if err != nil {
+		return err
+	}
+
+	messageID, err := uuid.NewV4()
+	if err != nil {
+		return err
+	}
+
+	env, err := message.NewEnvelope(event, message.BookingEventVersion, messageID.String(), payload)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	return s.outboxRepository.AddEvent(ctx, &model.OutboxEvent{
+		MessageID:  messageID,
+		Type:       event,
+		RoutingKey: message.EventRoutingKey(event, booking.SuiteID),
+		Body:       body,
+		CreatedAt:  time.Now(),
+	})
+}
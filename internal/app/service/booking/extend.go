@@ -0,0 +1,65 @@
+package booking
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ExtendBooking moves the end of the booking by the given duration, if the suite is vacant then,
+// and returns the extended booking. Reminders about the end are rescheduled for the new end.
+func (s *Service) ExtendBooking(ctx context.Context, bookingID uuid.UUID, userID int64, by time.Duration) (*model.BookingInfo, error) {
+	const op = "service.booking.ExtendBooking"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	// чтение и запись в одной транзакции: параллельное продление приводит к ошибке сериализации и повторяется
+	// с новым временем окончания, а не затирает его
+	var mod *model.BookingInfo
+	err := s.txManager.Serializable(ctx, func(ctx context.Context) error {
+		var errTx error
+		mod, errTx = s.bookingRepository.GetBooking(ctx, bookingID, userID)
+		if errTx != nil {
+			span.RecordError(errTx)
+			span.SetStatus(codes.Error, errTx.Error())
+			log.Error("could not get booking", sl.Err(errTx))
+			return errTx
+		}
+
+		// интервалы напоминаний остаются прежними
+		mod.NotifyAt, mod.NotifyBeforeEnd = nil, nil
+		mod.EndDate = mod.EndDate.Add(by)
+
+		return s.UpdateBooking(ctx, mod)
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("transaction failed", sl.Err(err))
+		if errors.As(err, pgNoConnection) {
+			return nil, ErrNoConnection
+		}
+		return nil, err
+	}
+
+	span.AddEvent("booking extended", trace.WithAttributes(attribute.String("end_date", mod.EndDate.String())))
+
+	return mod, nil
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
 
 	"github.com/go-chi/chi/middleware"
 	"go.opentelemetry.io/otel/attribute"
@@ -27,7 +28,15 @@ func (s *Service) UpdateBooking(ctx context.Context, mod *model.BookingInfo) err
 	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
 	defer span.End()
 
-	err := s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+	if len(mod.NotifyAt) > s.maxReminders || len(mod.NotifyBeforeEnd) > s.maxReminders {
+		span.RecordError(ErrTooManyReminders)
+		span.SetStatus(codes.Error, ErrTooManyReminders.Error())
+		log.Error("invalid request", sl.Err(ErrTooManyReminders))
+		return ErrTooManyReminders
+	}
+
+	// параллельное бронирование тех же дат приводит к ошибке сериализации, и проверка доступности повторяется
+	err := s.txManager.Serializable(ctx, func(ctx context.Context) error {
 		availibility, errTx := s.bookingRepository.CheckAvailibility(ctx, mod)
 		if errTx != nil {
 			span.RecordError(errTx)
@@ -53,6 +62,40 @@ func (s *Service) UpdateBooking(ctx context.Context, mod *model.BookingInfo) err
 			return errTx
 		}
 
+		for kind, leadTimes := range map[string][]time.Duration{
+			model.ReminderKindStart: mod.NotifyAt,
+			model.ReminderKindEnd:   mod.NotifyBeforeEnd,
+		} {
+			if leadTimes == nil {
+				continue
+			}
+
+			errTx = s.bookingRepository.SetReminders(ctx, mod.ID, kind, leadTimes)
+			if errTx != nil {
+				span.RecordError(errTx)
+				span.SetStatus(codes.Error, errTx.Error())
+				log.Error("failed to set reminders", sl.Err(errTx), slog.String("kind", kind))
+				return errTx
+			}
+		}
+
+		// напоминания о перенесенном бронировании отправляются заново
+		errTx = s.bookingRepository.RescheduleReminders(ctx, mod.ID)
+		if errTx != nil {
+			span.RecordError(errTx)
+			span.SetStatus(codes.Error, errTx.Error())
+			log.Error("failed to reschedule reminders", sl.Err(errTx))
+			return errTx
+		}
+
+		errTx = s.enqueueEvent(ctx, model.EventBookingUpdated, mod)
+		if errTx != nil {
+			span.RecordError(errTx)
+			span.SetStatus(codes.Error, errTx.Error())
+			log.Error("failed to enqueue booking event", sl.Err(errTx))
+			return errTx
+		}
+
 		span.AddEvent("transaction successful")
 
 		return nil
@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/pkg/telegram"
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BookingService is the part of service/booking.Service the bot relies on.
+type BookingService interface {
+	GetVacantRooms(ctx context.Context, startDate time.Time, endDate time.Time) ([]*model.Suite, error)
+	GetVacantDates(ctx context.Context, suiteID int64) ([]*api.Interval, error)
+	AddBooking(ctx context.Context, mod *model.BookingInfo) (uuid.UUID, error)
+	GetBookings(ctx context.Context, startDate time.Time, endDate time.Time, userID int64) ([]*model.BookingInfo, error)
+	DeleteBooking(ctx context.Context, bookingID uuid.UUID, userID int64) error
+}
+
+// UserRepository finds the users linked to telegram accounts.
+type UserRepository interface {
+	GetUserByTelegramID(ctx context.Context, telegramID int64) (*model.User, error)
+}
+
+type Service struct {
+	client         *telegram.Client
+	bookingService BookingService
+	userRepository UserRepository
+	log            *slog.Logger
+	tracer         trace.Tracer
+	pollTimeout    time.Duration
+	// Время заезда и выезда, отсчитываемое от полуночи в часовом поясе пользователя
+	checkIn  time.Duration
+	checkOut time.Duration
+}
+
+const (
+	clockLayout = "15:04"
+	dateLayout  = "2006-01-02"
+
+	// Сколько дней вперед предлагается для выбора даты заезда
+	datesOffered = 7
+	// Горизонт, в пределах которого показываются бронирования пользователя
+	bookingsHorizon = 365 * 24 * time.Hour
+	// Задержка перед повторным запросом обновлений после ошибки
+	retryDelay = 5 * time.Second
+)
+
+// Количество ночей, предлагаемое при бронировании
+var nightsOffered = []int{1, 2, 3, 7}
+
+var (
+	ErrInvalidClock = errors.New("check-in and check-out time must be in 15:04 format")
+)
+
+// NewBotService creates the bot service. checkIn and checkOut are the times of day in 15:04 format
+// the bookings made through the bot start and end at in the user's time zone.
+func NewBotService(client *telegram.Client, bookingService BookingService, userRepository UserRepository, log *slog.Logger, tracer trace.Tracer, pollTimeout time.Duration, checkIn string, checkOut string) (*Service, error) {
+	in, err := parseClock(checkIn)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := parseClock(checkOut)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Service{
+		client:         client,
+		bookingService: bookingService,
+		userRepository: userRepository,
+		log:            log,
+		tracer:         tracer,
+		pollTimeout:    pollTimeout,
+		checkIn:        in,
+		checkOut:       out,
+	}, nil
+}
+
+func parseClock(clock string) (time.Duration, error) {
+	t, err := time.Parse(clockLayout, clock)
+	if err != nil {
+		return 0, ErrInvalidClock
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
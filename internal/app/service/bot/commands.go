@@ -0,0 +1,345 @@
+package bot
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/app/repository/booking"
+	bookingService "booking-schedule/internal/app/service/booking"
+	"booking-schedule/internal/pkg/telegram"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	msgHelp = "Commands:\n" +
+		"/rooms [date] - rooms vacant for the night\n" +
+		"/free <room> [date] - when the room is vacant\n" +
+		"/book [room] [date] [nights] - book a room\n" +
+		"/my - your bookings\n" +
+		"/cancel [booking id] - cancel a booking\n" +
+		"Dates are in 2006-01-02 format."
+	msgInternalError = "Something went wrong, please try again later."
+)
+
+func (s *Service) handleRooms(ctx context.Context, req *request) error {
+	loc := location(req.user)
+
+	date := today(loc)
+	if len(req.args) > 0 {
+		var ok bool
+		date, ok = parseDate(req.args[0], loc)
+		if !ok {
+			s.reply(ctx, req, "Usage: /rooms [date], e.g. /rooms "+today(loc).Format(dateLayout), nil)
+			return nil
+		}
+	}
+
+	start, end := s.stay(date, 1)
+	rooms, err := s.bookingService.GetVacantRooms(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	if len(rooms) == 0 {
+		s.reply(ctx, req, fmt.Sprintf("No rooms are vacant for the night of %s.", date.Format(dateLayout)), nil)
+		return nil
+	}
+
+	keyboard := make([][]telegram.InlineKeyboardButton, 0, len(rooms))
+	for _, room := range rooms {
+		keyboard = append(keyboard, []telegram.InlineKeyboardButton{
+			button(fmt.Sprintf("Book %s (%d guests)", room.Name, room.Capacity), "book", room.SuiteID, date.Format(dateLayout)),
+			button("Vacant dates", "free", room.SuiteID),
+		})
+	}
+
+	s.reply(ctx, req, fmt.Sprintf("Rooms vacant for the night of %s:", date.Format(dateLayout)), keyboard)
+
+	return nil
+}
+
+func (s *Service) handleFree(ctx context.Context, req *request) error {
+	loc := location(req.user)
+
+	suiteID, date, ok := int64(0), time.Time{}, len(req.args) > 0
+	if ok {
+		suiteID, ok = parseRoom(req.args[0])
+	}
+	if ok && len(req.args) > 1 {
+		date, ok = parseDate(req.args[1], loc)
+	}
+	if !ok {
+		s.reply(ctx, req, "Usage: /free <room> [date], e.g. /free 1 "+today(loc).Format(dateLayout), nil)
+		return nil
+	}
+
+	if date.IsZero() {
+		intervals, err := s.bookingService.GetVacantDates(ctx, suiteID)
+		if err != nil {
+			return err
+		}
+
+		if len(intervals) == 0 {
+			s.reply(ctx, req, fmt.Sprintf("Room %d is not vacant within the next month.", suiteID), nil)
+			return nil
+		}
+
+		lines := make([]string, 0, len(intervals))
+		for _, interval := range intervals {
+			lines = append(lines, fmt.Sprintf("%s - %s", formatTime(interval.StartDate, loc), formatTime(interval.EndDate, loc)))
+		}
+
+		s.reply(ctx, req, fmt.Sprintf("Room %d is vacant:\n%s\nChoose the check-in date:", suiteID, strings.Join(lines, "\n")), s.datesKeyboard(suiteID, loc))
+		return nil
+	}
+
+	start, end := s.stay(date, 1)
+	rooms, err := s.bookingService.GetVacantRooms(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	for _, room := range rooms {
+		if room.SuiteID == suiteID {
+			s.reply(ctx, req, fmt.Sprintf("%s is vacant for the night of %s. How many nights?", room.Name, date.Format(dateLayout)), nightsKeyboard(suiteID, date))
+			return nil
+		}
+	}
+
+	s.reply(ctx, req, fmt.Sprintf("Room %d is not vacant for the night of %s.", suiteID, date.Format(dateLayout)),
+		[][]telegram.InlineKeyboardButton{{button("Vacant dates", "free", suiteID)}})
+
+	return nil
+}
+
+func (s *Service) handleBook(ctx context.Context, req *request) error {
+	loc := location(req.user)
+
+	if len(req.args) == 0 {
+		return s.handleRooms(ctx, req)
+	}
+
+	var (
+		date   time.Time
+		nights int
+	)
+
+	suiteID, ok := parseRoom(req.args[0])
+	if ok && len(req.args) > 1 {
+		date, ok = parseDate(req.args[1], loc)
+	}
+	if ok && len(req.args) > 2 {
+		nights, ok = parseNights(req.args[2])
+	}
+	if !ok {
+		s.reply(ctx, req, "Usage: /book [room] [date] [nights], e.g. /book 1 "+today(loc).Format(dateLayout)+" 2", nil)
+		return nil
+	}
+
+	switch {
+	case date.IsZero():
+		s.reply(ctx, req, fmt.Sprintf("Room %d. Choose the check-in date:", suiteID), s.datesKeyboard(suiteID, loc))
+		return nil
+	case nights == 0:
+		s.reply(ctx, req, fmt.Sprintf("Room %d from %s. How many nights?", suiteID, date.Format(dateLayout)), nightsKeyboard(suiteID, date))
+		return nil
+	}
+
+	start, end := s.stay(date, nights)
+	if start.Before(time.Now()) {
+		s.reply(ctx, req, "The check-in time has already passed, choose another date.", s.datesKeyboard(suiteID, loc))
+		return nil
+	}
+
+	id, err := s.bookingService.AddBooking(ctx, &model.BookingInfo{
+		SuiteID:   suiteID,
+		StartDate: start,
+		EndDate:   end,
+		UserID:    req.user.ID,
+	})
+	if err != nil {
+		if errors.Is(err, bookingService.ErrNotAvailible) {
+			s.reply(ctx, req, fmt.Sprintf("Room %d is not vacant for these dates.", suiteID),
+				[][]telegram.InlineKeyboardButton{{button("Vacant dates", "free", suiteID)}})
+			return nil
+		}
+		return err
+	}
+
+	s.reply(ctx, req, fmt.Sprintf("Room %d is booked from %s to %s.\nBooking ID: %s", suiteID, formatTime(start, loc), formatTime(end, loc), id),
+		[][]telegram.InlineKeyboardButton{{button("Cancel booking", "cancel", id)}})
+
+	return nil
+}
+
+func (s *Service) handleMy(ctx context.Context, req *request) error {
+	bookings, err := s.getBookings(ctx, req.user)
+	if err != nil {
+		return err
+	}
+
+	if len(bookings) == 0 {
+		s.reply(ctx, req, "You have no upcoming bookings.", nil)
+		return nil
+	}
+
+	s.reply(ctx, req, "Your bookings:\n"+formatBookings(bookings, location(req.user)), nil)
+
+	return nil
+}
+
+func (s *Service) handleCancel(ctx context.Context, req *request) error {
+	loc := location(req.user)
+
+	if len(req.args) == 0 {
+		bookings, err := s.getBookings(ctx, req.user)
+		if err != nil {
+			return err
+		}
+
+		if len(bookings) == 0 {
+			s.reply(ctx, req, "You have no upcoming bookings.", nil)
+			return nil
+		}
+
+		keyboard := make([][]telegram.InlineKeyboardButton, 0, len(bookings))
+		for _, b := range bookings {
+			keyboard = append(keyboard, []telegram.InlineKeyboardButton{
+				button(fmt.Sprintf("Room %d from %s", b.SuiteID, b.StartDate.In(loc).Format(dateLayout)), "cancel", b.ID),
+			})
+		}
+
+		s.reply(ctx, req, "Which booking do you want to cancel?\n"+formatBookings(bookings, loc), keyboard)
+		return nil
+	}
+
+	id, err := uuid.FromString(req.args[0])
+	if err != nil {
+		s.reply(ctx, req, "Usage: /cancel [booking id]", nil)
+		return nil
+	}
+
+	err = s.bookingService.DeleteBooking(ctx, id, req.user.ID)
+	if err != nil {
+		if errors.Is(err, booking.ErrNotFound) || errors.Is(err, booking.ErrNoRowsAffected) {
+			s.reply(ctx, req, "Booking not found, it may have already been cancelled.", nil)
+			return nil
+		}
+		return err
+	}
+
+	s.reply(ctx, req, fmt.Sprintf("Booking %s is cancelled.", id), nil)
+
+	return nil
+}
+
+// getBookings returns the user's bookings that have not ended yet.
+func (s *Service) getBookings(ctx context.Context, user *model.User) ([]*model.BookingInfo, error) {
+	now := time.Now().UTC()
+
+	bookings, err := s.bookingService.GetBookings(ctx, now, now.Add(bookingsHorizon), user.ID)
+	if err != nil && !errors.Is(err, booking.ErrNotFound) {
+		return nil, err
+	}
+
+	return bookings, nil
+}
+
+// stay returns the check-in and check-out moments of a stay of nights starting on date in the user's time zone.
+// The moments are converted to UTC: bookings are stored in columns without time zone, so the service expects
+// UTC and the replies convert the moments back with formatTime.
+func (s *Service) stay(date time.Time, nights int) (time.Time, time.Time) {
+	return atClock(date, s.checkIn).UTC(), atClock(date.AddDate(0, 0, nights), s.checkOut).UTC()
+}
+
+// atClock returns the moment on the day of date at the time of day, keeping wall clock across DST changes.
+func atClock(date time.Time, clock time.Duration) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, date.Location())
+}
+
+func (s *Service) datesKeyboard(suiteID int64, loc *time.Location) [][]telegram.InlineKeyboardButton {
+	date := today(loc)
+
+	keyboard := make([][]telegram.InlineKeyboardButton, 0, datesOffered)
+	for i := 0; i < datesOffered; i++ {
+		day := date.AddDate(0, 0, i)
+		if start, _ := s.stay(day, 1); start.Before(time.Now()) {
+			continue
+		}
+		keyboard = append(keyboard, []telegram.InlineKeyboardButton{
+			button(day.Format("Mon, 2006-01-02"), "book", suiteID, day.Format(dateLayout)),
+		})
+	}
+
+	return keyboard
+}
+
+func nightsKeyboard(suiteID int64, date time.Time) [][]telegram.InlineKeyboardButton {
+	row := make([]telegram.InlineKeyboardButton, 0, len(nightsOffered))
+	for _, n := range nightsOffered {
+		row = append(row, button(strconv.Itoa(n), "book", suiteID, date.Format(dateLayout), n))
+	}
+
+	return [][]telegram.InlineKeyboardButton{row}
+}
+
+// button creates an inline keyboard button that invokes the command with args when pressed.
+func button(text string, command string, args ...interface{}) telegram.InlineKeyboardButton {
+	data := command
+	for _, arg := range args {
+		data += fmt.Sprintf(":%v", arg)
+	}
+
+	return telegram.InlineKeyboardButton{
+		Text:         text,
+		CallbackData: data,
+	}
+}
+
+func formatBookings(bookings []*model.BookingInfo, loc *time.Location) string {
+	lines := make([]string, 0, len(bookings))
+	for _, b := range bookings {
+		lines = append(lines, fmt.Sprintf("Room %d: %s - %s\n%s", b.SuiteID, formatTime(b.StartDate, loc), formatTime(b.EndDate, loc), b.ID))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02 15:04")
+}
+
+func location(user *model.User) *time.Location {
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// today returns the midnight of the current day in loc.
+func today(loc *time.Location) time.Time {
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+}
+
+func parseDate(arg string, loc *time.Location) (time.Time, bool) {
+	date, err := time.ParseInLocation(dateLayout, arg, loc)
+	return date, err == nil
+}
+
+func parseRoom(arg string) (int64, bool) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	return id, err == nil && id > 0
+}
+
+func parseNights(arg string) (int, bool) {
+	n, err := strconv.Atoi(arg)
+	return n, err == nil && n > 0 && n <= 30
+}
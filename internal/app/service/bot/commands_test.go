@@ -0,0 +1,348 @@
+package bot
+
+import (
+	"booking-schedule/internal/app/api"
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/app/repository/booking"
+	"booking-schedule/internal/pkg/telegram"
+	"booking-schedule/internal/pkg/telegram/telegramtest"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/gofrs/uuid"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const (
+	testToken      = "123:test"
+	testTelegramID = 42
+	testUserID     = 7
+	// Токио круглый год на 9 часов впереди UTC, выезд в 08:00 по Токио приходится на предыдущий день по UTC
+	testTimezone = "Asia/Tokyo"
+)
+
+type stubBookings struct {
+	mu sync.Mutex
+	// Интервалы, с которыми вызывался GetVacantRooms
+	vacantCalls [][2]time.Time
+	// Интервалы, с которыми вызывался GetBookings
+	bookingsCalls [][2]time.Time
+	added         []*model.BookingInfo
+	bookings      []*model.BookingInfo
+	vacantDates   []*api.Interval
+	// Бронирования, которые удалял DeleteBooking, и их владельцы
+	deleted   []uuid.UUID
+	deletedBy []int64
+	deleteErr error
+}
+
+func (b *stubBookings) GetVacantRooms(_ context.Context, startDate time.Time, endDate time.Time) ([]*model.Suite, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.vacantCalls = append(b.vacantCalls, [2]time.Time{startDate, endDate})
+
+	return []*model.Suite{{SuiteID: 1, Capacity: 2, Name: "Suite 1"}}, nil
+}
+
+func (b *stubBookings) GetVacantDates(context.Context, int64) ([]*api.Interval, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.vacantDates, nil
+}
+
+func (b *stubBookings) AddBooking(_ context.Context, mod *model.BookingInfo) (uuid.UUID, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.added = append(b.added, mod)
+
+	return uuid.Must(uuid.NewV4()), nil
+}
+
+func (b *stubBookings) GetBookings(_ context.Context, startDate time.Time, endDate time.Time, _ int64) ([]*model.BookingInfo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bookingsCalls = append(b.bookingsCalls, [2]time.Time{startDate, endDate})
+
+	return b.bookings, nil
+}
+
+func (b *stubBookings) DeleteBooking(_ context.Context, id uuid.UUID, userID int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deleted = append(b.deleted, id)
+	b.deletedBy = append(b.deletedBy, userID)
+
+	return b.deleteErr
+}
+
+type stubUsers struct{}
+
+func (stubUsers) GetUserByTelegramID(_ context.Context, telegramID int64) (*model.User, error) {
+	return &model.User{ID: testUserID, TelegramID: telegramID, Timezone: testTimezone}, nil
+}
+
+// runBot starts the bot against a fake Bot API server, check-in is at 14:00 and check-out at 08:00.
+func runBot(t *testing.T, bookings *stubBookings) *telegramtest.Server {
+	t.Helper()
+
+	srv := telegramtest.NewServer(testToken)
+	t.Cleanup(srv.Close)
+
+	client := telegram.NewClient(srv.URL, testToken, time.Second, time.Second)
+	svc, err := NewBotService(client, bookings, stubUsers{}, slog.New(slog.NewTextHandler(io.Discard, nil)),
+		noop.NewTracerProvider().Tracer("bot"), time.Second, "14:00", "08:00")
+	if err != nil {
+		t.Fatalf("create bot: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		svc.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return srv
+}
+
+// command sends text to the bot and returns its reply.
+func command(t *testing.T, srv *telegramtest.Server, text string) telegramtest.Sent {
+	t.Helper()
+
+	n := len(srv.Sent())
+	srv.SendText(testTelegramID, text)
+
+	sent, err := srv.WaitSent(n+1, 5*time.Second)
+	if err != nil {
+		t.Fatalf("%s: %v", text, err)
+	}
+
+	return sent[n]
+}
+
+// checkInDate returns a date a few days ahead in the test user's time zone.
+func checkInDate(t *testing.T) time.Time {
+	t.Helper()
+
+	loc, err := time.LoadLocation(testTimezone)
+	if err != nil {
+		t.Fatalf("load location: %v", err)
+	}
+
+	return today(loc).AddDate(0, 0, 10)
+}
+
+func TestBookPassesUTCToService(t *testing.T) {
+	bookings := &stubBookings{}
+	srv := runBot(t, bookings)
+
+	date := checkInDate(t)
+	reply := command(t, srv, "/book 1 "+date.Format(dateLayout)+" 2")
+
+	bookings.mu.Lock()
+	defer bookings.mu.Unlock()
+
+	if len(bookings.added) != 1 {
+		t.Fatalf("bot added %d bookings, want 1; reply: %q", len(bookings.added), reply.Text)
+	}
+	added := bookings.added[0]
+
+	wantStart := time.Date(date.Year(), date.Month(), date.Day(), 5, 0, 0, 0, time.UTC)
+	wantEnd := time.Date(date.Year(), date.Month(), date.Day()+1, 23, 0, 0, 0, time.UTC)
+	if added.StartDate.Location() != time.UTC || !added.StartDate.Equal(wantStart) {
+		t.Errorf("start date is %v, want %v", added.StartDate, wantStart)
+	}
+	if added.EndDate.Location() != time.UTC || !added.EndDate.Equal(wantEnd) {
+		t.Errorf("end date is %v, want %v", added.EndDate, wantEnd)
+	}
+	if added.UserID != testUserID {
+		t.Errorf("user id is %d, want %d", added.UserID, testUserID)
+	}
+
+	want := "from " + date.Format(dateLayout) + " 14:00 to " + date.AddDate(0, 0, 2).Format(dateLayout) + " 08:00"
+	if !strings.Contains(reply.Text, want) {
+		t.Errorf("reply %q does not mention the local time %q", reply.Text, want)
+	}
+}
+
+func TestRoomsPassesUTCToService(t *testing.T) {
+	bookings := &stubBookings{}
+	srv := runBot(t, bookings)
+
+	date := checkInDate(t)
+	reply := command(t, srv, "/rooms "+date.Format(dateLayout))
+
+	bookings.mu.Lock()
+	defer bookings.mu.Unlock()
+
+	if len(bookings.vacantCalls) != 1 {
+		t.Fatalf("bot asked for vacant rooms %d times, want 1; reply: %q", len(bookings.vacantCalls), reply.Text)
+	}
+	call := bookings.vacantCalls[0]
+
+	wantStart := time.Date(date.Year(), date.Month(), date.Day(), 5, 0, 0, 0, time.UTC)
+	wantEnd := time.Date(date.Year(), date.Month(), date.Day(), 23, 0, 0, 0, time.UTC)
+	if call[0].Location() != time.UTC || !call[0].Equal(wantStart) {
+		t.Errorf("start date is %v, want %v", call[0], wantStart)
+	}
+	if call[1].Location() != time.UTC || !call[1].Equal(wantEnd) {
+		t.Errorf("end date is %v, want %v", call[1], wantEnd)
+	}
+
+	if len(reply.Keyboard) != 1 || reply.Keyboard[0][0].CallbackData != "book:1:"+date.Format(dateLayout) {
+		t.Errorf("unexpected keyboard %+v", reply.Keyboard)
+	}
+}
+
+func TestMyShowsBookingsInUserTimezone(t *testing.T) {
+	date := checkInDate(t)
+	bookings := &stubBookings{
+		bookings: []*model.BookingInfo{{
+			ID:        uuid.Must(uuid.NewV4()),
+			SuiteID:   1,
+			StartDate: time.Date(date.Year(), date.Month(), date.Day(), 5, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(date.Year(), date.Month(), date.Day()+1, 23, 0, 0, 0, time.UTC),
+			UserID:    testUserID,
+		}},
+	}
+	srv := runBot(t, bookings)
+
+	reply := command(t, srv, "/my")
+
+	want := "Room 1: " + date.Format(dateLayout) + " 14:00 - " + date.AddDate(0, 0, 2).Format(dateLayout) + " 08:00"
+	if !strings.Contains(reply.Text, want) {
+		t.Errorf("reply %q does not contain %q", reply.Text, want)
+	}
+
+	bookings.mu.Lock()
+	defer bookings.mu.Unlock()
+
+	if len(bookings.bookingsCalls) != 1 || bookings.bookingsCalls[0][0].Location() != time.UTC {
+		t.Errorf("bookings are requested with %v, want UTC", bookings.bookingsCalls)
+	}
+}
+
+func TestFreeShowsVacantDatesInUserTimezone(t *testing.T) {
+	date := checkInDate(t)
+	bookings := &stubBookings{
+		vacantDates: []*api.Interval{{
+			StartDate: time.Date(date.Year(), date.Month(), date.Day(), 3, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(date.Year(), date.Month(), date.Day()+3, 20, 0, 0, 0, time.UTC),
+		}},
+	}
+	srv := runBot(t, bookings)
+
+	reply := command(t, srv, "/free 1")
+
+	want := date.Format(dateLayout) + " 12:00 - " + date.AddDate(0, 0, 4).Format(dateLayout) + " 05:00"
+	if !strings.Contains(reply.Text, want) {
+		t.Errorf("reply %q does not contain %q", reply.Text, want)
+	}
+	if len(reply.Keyboard) == 0 || !strings.HasPrefix(reply.Keyboard[0][0].CallbackData, "book:1:") {
+		t.Errorf("unexpected keyboard %+v", reply.Keyboard)
+	}
+}
+
+func TestFreeWithDatePassesUTCToService(t *testing.T) {
+	bookings := &stubBookings{}
+	srv := runBot(t, bookings)
+
+	date := checkInDate(t)
+	reply := command(t, srv, "/free 1 "+date.Format(dateLayout))
+
+	bookings.mu.Lock()
+	defer bookings.mu.Unlock()
+
+	if len(bookings.vacantCalls) != 1 {
+		t.Fatalf("bot asked for vacant rooms %d times, want 1; reply: %q", len(bookings.vacantCalls), reply.Text)
+	}
+	call := bookings.vacantCalls[0]
+
+	wantStart := time.Date(date.Year(), date.Month(), date.Day(), 5, 0, 0, 0, time.UTC)
+	wantEnd := time.Date(date.Year(), date.Month(), date.Day(), 23, 0, 0, 0, time.UTC)
+	if call[0].Location() != time.UTC || !call[0].Equal(wantStart) {
+		t.Errorf("start date is %v, want %v", call[0], wantStart)
+	}
+	if call[1].Location() != time.UTC || !call[1].Equal(wantEnd) {
+		t.Errorf("end date is %v, want %v", call[1], wantEnd)
+	}
+
+	want := "Suite 1 is vacant for the night of " + date.Format(dateLayout)
+	if !strings.Contains(reply.Text, want) {
+		t.Errorf("reply %q does not contain %q", reply.Text, want)
+	}
+}
+
+func TestCancelListsBookings(t *testing.T) {
+	date := checkInDate(t)
+	id := uuid.Must(uuid.NewV4())
+	bookings := &stubBookings{
+		bookings: []*model.BookingInfo{{
+			ID:        id,
+			SuiteID:   1,
+			StartDate: time.Date(date.Year(), date.Month(), date.Day(), 5, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(date.Year(), date.Month(), date.Day()+1, 23, 0, 0, 0, time.UTC),
+			UserID:    testUserID,
+		}},
+	}
+	srv := runBot(t, bookings)
+
+	reply := command(t, srv, "/cancel")
+
+	if len(reply.Keyboard) != 1 || reply.Keyboard[0][0].CallbackData != "cancel:"+id.String() {
+		t.Errorf("unexpected keyboard %+v", reply.Keyboard)
+	}
+
+	bookings.mu.Lock()
+	defer bookings.mu.Unlock()
+
+	if len(bookings.deleted) != 0 {
+		t.Errorf("bot deleted %v without confirmation", bookings.deleted)
+	}
+}
+
+func TestCancelDeletesBookingOfUser(t *testing.T) {
+	bookings := &stubBookings{}
+	srv := runBot(t, bookings)
+
+	id := uuid.Must(uuid.NewV4())
+	reply := command(t, srv, "/cancel "+id.String())
+
+	want := "Booking " + id.String() + " is cancelled."
+	if reply.Text != want {
+		t.Errorf("reply is %q, want %q", reply.Text, want)
+	}
+
+	bookings.mu.Lock()
+	defer bookings.mu.Unlock()
+
+	if len(bookings.deleted) != 1 || bookings.deleted[0] != id || bookings.deletedBy[0] != testUserID {
+		t.Errorf("bot deleted %v of users %v, want %v of user %d", bookings.deleted, bookings.deletedBy, id, testUserID)
+	}
+}
+
+func TestCancelReportsMissingBooking(t *testing.T) {
+	bookings := &stubBookings{deleteErr: booking.ErrNotFound}
+	srv := runBot(t, bookings)
+
+	reply := command(t, srv, "/cancel "+uuid.Must(uuid.NewV4()).String())
+
+	if !strings.Contains(reply.Text, "Booking not found") {
+		t.Errorf("reply %q does not report a missing booking", reply.Text)
+	}
+}
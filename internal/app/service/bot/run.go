@@ -0,0 +1,180 @@
+package bot
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/app/repository/user"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/telegram"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// request is a command received either as a message or as a press of an inline keyboard button.
+// Buttons carry the command and its arguments separated by colons, e.g. "book:3:2024-05-01:2",
+// so that pressing a button is handled the same way as typing "/book 3 2024-05-01 2".
+type request struct {
+	user    *model.User
+	chatID  int64
+	command string
+	args    []string
+	// Сообщение бота с нажатой кнопкой, которое заменяется ответом; 0, если команда пришла сообщением
+	messageID int64
+}
+
+func (s *Service) Run(ctx context.Context) {
+	const op = "service.bot.Run"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	log.Info("bot initiated")
+
+	var offset int64
+	for {
+		updates, err := s.client.GetUpdates(ctx, offset, s.pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Error("failed to get updates", sl.Err(err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+
+		for _, update := range updates {
+			s.handleUpdate(ctx, &update)
+			offset = update.UpdateID + 1
+		}
+	}
+}
+
+func (s *Service) handleUpdate(ctx context.Context, update *telegram.Update) {
+	const op = "service.bot.handleUpdate"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("update_id", update.UpdateID),
+	)
+
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.Int64("update_id", update.UpdateID)))
+	defer span.End()
+
+	var (
+		req  = new(request)
+		from int64
+		data string
+	)
+
+	switch {
+	case update.Message != nil && update.Message.From != nil:
+		from = update.Message.From.ID
+		req.chatID = update.Message.Chat.ID
+		fields := strings.Fields(update.Message.Text)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+			data = "help"
+			break
+		}
+		// в группах команда может быть адресована боту явно: /rooms@bot_name
+		command, _, _ := strings.Cut(fields[0][1:], "@")
+		data = strings.Join(append([]string{command}, fields[1:]...), ":")
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		from = update.CallbackQuery.From.ID
+		req.chatID = update.CallbackQuery.Message.Chat.ID
+		req.messageID = update.CallbackQuery.Message.MessageID
+		data = update.CallbackQuery.Data
+
+		err := s.client.AnswerCallbackQuery(ctx, update.CallbackQuery.ID, "")
+		if err != nil {
+			span.RecordError(err)
+			log.Error("failed to answer callback query", sl.Err(err))
+		}
+	default:
+		log.Debug("skipping unsupported update")
+		return
+	}
+
+	parts := strings.Split(data, ":")
+	req.command, req.args = parts[0], parts[1:]
+
+	span.SetAttributes(attribute.String("command", req.command))
+
+	usr, err := s.userRepository.GetUserByTelegramID(ctx, from)
+	if err != nil {
+		if errors.Is(err, user.ErrNotFound) {
+			s.reply(ctx, req, "Your Telegram account is not linked to any user. Sign up or update your profile with "+
+				"your Telegram ID to book through the bot.", nil)
+			return
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to get user by telegram id", sl.Err(err))
+		s.reply(ctx, req, msgInternalError, nil)
+		return
+	}
+	req.user = usr
+
+	switch req.command {
+	case "rooms":
+		err = s.handleRooms(ctx, req)
+	case "free":
+		err = s.handleFree(ctx, req)
+	case "book":
+		err = s.handleBook(ctx, req)
+	case "my":
+		err = s.handleMy(ctx, req)
+	case "cancel":
+		err = s.handleCancel(ctx, req)
+	default:
+		s.reply(ctx, req, msgHelp, nil)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to handle command", sl.Err(err), slog.String("command", req.command))
+		s.reply(ctx, req, msgInternalError, nil)
+		return
+	}
+
+	span.AddEvent("command handled")
+}
+
+// reply edits the message with the pressed button or sends a new message if the command was typed.
+func (s *Service) reply(ctx context.Context, req *request, text string, keyboard [][]telegram.InlineKeyboardButton) {
+	var markup *telegram.InlineKeyboardMarkup
+	if len(keyboard) != 0 {
+		markup = &telegram.InlineKeyboardMarkup{InlineKeyboard: keyboard}
+	}
+
+	var err error
+	if req.messageID != 0 {
+		err = s.client.EditMessageText(ctx, &telegram.EditMessageTextRequest{
+			ChatID:      req.chatID,
+			MessageID:   req.messageID,
+			Text:        text,
+			ReplyMarkup: markup,
+		})
+	} else {
+		_, err = s.client.SendMessage(ctx, &telegram.SendMessageRequest{
+			ChatID:      req.chatID,
+			Text:        text,
+			ReplyMarkup: markup,
+		})
+	}
+
+	if err != nil {
+		s.log.Error("failed to reply", sl.Err(err), slog.String("op", "service.bot.reply"), slog.Int64("chat_id", req.chatID))
+	}
+}
@@ -0,0 +1,29 @@
+package job
+
+import (
+	"booking-schedule/internal/app/repository/user"
+	"booking-schedule/internal/pkg/jobs"
+	"errors"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Service lets admins look into the scheduler jobs and run them out of schedule.
+type Service struct {
+	registry       *jobs.Registry
+	userRepository user.Repository
+	log            *slog.Logger
+	tracer         trace.Tracer
+}
+
+var ErrNotAdmin = errors.New("only admins may manage scheduler jobs")
+
+func NewJobService(registry *jobs.Registry, userRepository user.Repository, log *slog.Logger, tracer trace.Tracer) *Service {
+	return &Service{
+		registry:       registry,
+		userRepository: userRepository,
+		log:            log,
+		tracer:         tracer,
+	}
+}
@@ -0,0 +1,89 @@
+package job
+
+import (
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/jobs"
+	"context"
+	"log/slog"
+
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GetJobs returns the statuses of the scheduler jobs.
+func (s *Service) GetJobs(ctx context.Context, userID int64) ([]jobs.Status, error) {
+	const op = "service.job.GetJobs"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	err := s.checkAdmin(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("access denied", sl.Err(err))
+		return nil, err
+	}
+
+	return s.registry.Jobs(), nil
+}
+
+// TriggerJob starts the job out of its schedule without waiting for it to finish.
+func (s *Service) TriggerJob(ctx context.Context, userID int64, name string) error {
+	const op = "service.job.TriggerJob"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+		slog.String("job", name),
+	)
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(
+		attribute.String("request_id", requestID),
+		attribute.String("job", name),
+	))
+	defer span.End()
+
+	err := s.checkAdmin(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("access denied", sl.Err(err))
+		return err
+	}
+
+	err = s.registry.Trigger(name)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to trigger job", sl.Err(err))
+		return err
+	}
+
+	span.AddEvent("job triggered")
+	log.Info("job triggered", slog.Int64("user_id", userID))
+
+	return nil
+}
+
+func (s *Service) checkAdmin(ctx context.Context, userID int64) error {
+	user, err := s.userRepository.GetUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.IsAdmin {
+		return ErrNotAdmin
+	}
+
+	return nil
+}
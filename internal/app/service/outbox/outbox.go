@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"booking-schedule/internal/app/message"
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/app/repository/outbox"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Service relays the events stored in the outbox to the broker.
+type Service struct {
+	outboxRepository outbox.Repository
+	producer         broker.Producer
+	log              *slog.Logger
+	tracer           trace.Tracer
+	pollPeriod       time.Duration
+	batchSize        uint64
+	lease            time.Duration
+}
+
+func NewOutboxService(outboxRepository outbox.Repository, producer broker.Producer, log *slog.Logger, tracer trace.Tracer, pollPeriod time.Duration, batchSize uint64, lease time.Duration) *Service {
+	return &Service{
+		outboxRepository: outboxRepository,
+		producer:         producer,
+		log:              log,
+		tracer:           tracer,
+		pollPeriod:       pollPeriod,
+		batchSize:        batchSize,
+		lease:            lease,
+	}
+}
+
+// Run publishes the events from the outbox until the context is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	const op = "service.outbox.Run"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	log.Info("outbox relay initiated")
+
+	ticker := time.NewTicker(s.pollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// накопившиеся события публикуются до конца, не дожидаясь следующего опроса
+			for n := s.relay(ctx); n != 0 && n == s.batchSize; n = s.relay(ctx) {
+			}
+		}
+	}
+}
+
+// relay publishes a batch of events and returns its size. Published events are deleted from the outbox,
+// the rest are published again once their lease expires. Events are published at least once,
+// consumers are expected to deduplicate them by message id.
+func (s *Service) relay(ctx context.Context) uint64 {
+	const op = "service.outbox.relay"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := s.tracer.Start(ctx, op)
+	defer span.End()
+
+	events, err := s.outboxRepository.ClaimEvents(ctx, time.Now(), s.lease, s.batchSize)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to claim events", sl.Err(err))
+		return 0
+	}
+
+	if len(events) == 0 {
+		return 0
+	}
+
+	// события публикуются в порядке их возникновения
+	slices.SortFunc(events, func(a, b *model.OutboxEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	msgs := make([]broker.Message, 0, len(events))
+	for _, event := range events {
+		msgs = append(msgs, broker.Message{
+			ID:          event.MessageID.String(),
+			Type:        event.Type,
+			ContentType: message.ContentTypeJSON,
+			RoutingKey:  event.RoutingKey,
+			Body:        event.Body,
+		})
+	}
+
+	var failed int
+	published := make([]int64, 0, len(events))
+	errs := s.producer.PublishBatch(ctx, msgs)
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			span.RecordError(err)
+			log.Error("failed to publish event", sl.Err(err), slog.String("message_id", msgs[i].ID), slog.String("routing_key", msgs[i].RoutingKey))
+			continue
+		}
+		published = append(published, events[i].ID)
+	}
+
+	span.AddEvent("events published", trace.WithAttributes(
+		attribute.Int("quantity", len(events)),
+		attribute.Int("failed", failed),
+	))
+
+	if failed != 0 {
+		span.SetStatus(codes.Error, "failed to publish some of the events")
+	}
+
+	err = s.outboxRepository.DeleteEvents(ctx, published)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to delete published events", sl.Err(err))
+	}
+
+	return uint64(len(events))
+}
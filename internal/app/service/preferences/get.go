@@ -0,0 +1,10 @@
+package preferences
+
+import (
+	"booking-schedule/internal/app/model"
+	"context"
+)
+
+func (s *Service) GetPreferences(ctx context.Context, userID int64) (*model.Preferences, error) {
+	return s.preferencesRepository.GetPreferences(ctx, userID)
+}
@@ -0,0 +1,22 @@
+package preferences
+
+import (
+	"booking-schedule/internal/app/repository/preferences"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Service struct {
+	preferencesRepository preferences.Repository
+	log                   *slog.Logger
+	tracer                trace.Tracer
+}
+
+func NewPreferencesService(preferencesRepository preferences.Repository, log *slog.Logger, tracer trace.Tracer) *Service {
+	return &Service{
+		preferencesRepository: preferencesRepository,
+		log:                   log,
+		tracer:                tracer,
+	}
+}
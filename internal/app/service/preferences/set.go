@@ -0,0 +1,10 @@
+package preferences
+
+import (
+	"booking-schedule/internal/app/model"
+	"context"
+)
+
+func (s *Service) SetPreferences(ctx context.Context, mod *model.Preferences) error {
+	return s.preferencesRepository.SetPreferences(ctx, mod)
+}
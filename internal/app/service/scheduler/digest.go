@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"booking-schedule/internal/app/message"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// sendDigests publishes the daily digests to the users whose digest time has come in their time zone
+// and returns the number of digests that failed. Users without bookings for the day get no message.
+func (s *Service) sendDigests(ctx context.Context) (int, error) {
+	const op = "service.scheduler.sendDigests"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := s.tracer.Start(ctx, op)
+	defer span.End()
+
+	now := time.Now()
+
+	recipients, err := s.preferencesRepository.GetDueDigests(ctx, now.UTC())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, err
+	}
+
+	if len(recipients) == 0 {
+		return 0, nil
+	}
+
+	var failed, sent int
+	for _, recipient := range recipients {
+		log := log.With(slog.Int64("user_id", recipient.UserID))
+
+		loc, err := time.LoadLocation(recipient.Timezone)
+		if err != nil {
+			log.Warn("unknown recipient time zone, falling back to UTC", sl.Err(err), slog.String("timezone", recipient.Timezone))
+			loc = time.UTC
+		}
+
+		local := now.In(loc)
+		date := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+
+		bookings, err := s.bookingRepository.GetOverlappingBookings(ctx, recipient.UserID, date.UTC(), date.AddDate(0, 0, 1).UTC())
+		if err != nil {
+			failed++
+			span.RecordError(err)
+			log.Error("failed to get bookings for the digest", sl.Err(err))
+			continue
+		}
+
+		if len(bookings) != 0 {
+			digest := message.NewBookingDigest(recipient.UserID, date, bookings)
+			digest.Recipient = &message.Recipient{
+				Name:          recipient.Name,
+				TelegramID:    recipient.TelegramID,
+				Language:      recipient.Language,
+				Timezone:      recipient.Timezone,
+				DisabledTypes: recipient.DisabledTypes,
+			}
+
+			env, err := message.NewEnvelope(message.TypeBookingDigest, message.BookingDigestVersion, message.DigestKey(recipient.UserID, date), digest)
+			if err != nil {
+				failed++
+				span.RecordError(err)
+				log.Error("failed to build message", sl.Err(err))
+				continue
+			}
+
+			data, err := json.Marshal(env)
+			if err != nil {
+				failed++
+				span.RecordError(err)
+				log.Error("failed to marshal message", sl.Err(err))
+				continue
+			}
+
+			err = s.producer.Publish(ctx, broker.Message{
+				ID:          env.IdempotencyKey,
+				Type:        env.Type,
+				ContentType: message.ContentTypeJSON,
+				Body:        data,
+			})
+			if err != nil {
+				failed++
+				span.RecordError(err)
+				log.Error("failed to send digest", sl.Err(err))
+				continue
+			}
+			sent++
+		}
+
+		// пользователи без бронирований на этот день тоже отмечаются, чтобы не проверять их повторно
+		err = s.preferencesRepository.MarkDigestSent(ctx, recipient.UserID, date)
+		if err != nil {
+			failed++
+			span.RecordError(err)
+			log.Error("failed to mark digest as sent", sl.Err(err))
+		}
+	}
+
+	span.AddEvent("digests sent", trace.WithAttributes(
+		attribute.Int("quantity", sent),
+		attribute.Int("failed", failed),
+	))
+
+	return failed, nil
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"booking-schedule/internal/app/model"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+// Куда переносятся бронирования старше bookingTTL и в каком формате выгружаются в файлы
+const (
+	ArchiveTargetTable = "table"
+	ArchiveTargetFile  = "file"
+
+	ArchiveFormatJSONL = "jsonl"
+	ArchiveFormatCSV   = "csv"
+)
+
+var (
+	ErrUnknownArchiveTarget = errors.New("unknown archive target, expected table or file")
+	ErrUnknownArchiveFormat = errors.New("unknown archive format, expected jsonl or csv")
+)
+
+var csvHeader = []string{"id", "suite_id", "user_id", "start_date", "end_date", "created_at", "updated_at", "confirmed_at", "archived_at"}
+
+// exportBookings writes the batch of bookings to a gzip compressed file in the archive directory.
+// The file appears under its name only once it is written completely.
+func (s *Service) exportBookings(bookings []*model.ArchivedBooking, archivedAt time.Time, batch int) (string, error) {
+	name := filepath.Join(s.archiveDir, fmt.Sprintf("bookings_%s_%04d.%s.gz", archivedAt.UTC().Format("20060102T150405Z"), batch, s.archiveFormat))
+
+	f, err := os.CreateTemp(s.archiveDir, ".bookings_*.tmp")
+	if err != nil {
+		return "", err
+	}
+	// после переименования удалять нечего
+	defer os.Remove(f.Name()) //nolint:errcheck
+
+	zw := gzip.NewWriter(f)
+
+	switch s.archiveFormat {
+	case ArchiveFormatCSV:
+		err = writeCSV(zw, bookings)
+	default:
+		err = writeJSONL(zw, bookings)
+	}
+	if err != nil {
+		f.Close() //nolint:errcheck
+		return "", err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		f.Close() //nolint:errcheck
+		return "", err
+	}
+
+	err = f.Sync()
+	if err != nil {
+		f.Close() //nolint:errcheck
+		return "", err
+	}
+
+	err = f.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return name, os.Rename(f.Name(), name)
+}
+
+func writeJSONL(zw *gzip.Writer, bookings []*model.ArchivedBooking) error {
+	enc := json.NewEncoder(zw)
+	for _, b := range bookings {
+		err := enc.Encode(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeCSV(zw *gzip.Writer, bookings []*model.ArchivedBooking) error {
+	w := csv.NewWriter(zw)
+
+	err := w.Write(csvHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, b := range bookings {
+		err = w.Write([]string{
+			b.ID.String(),
+			strconv.FormatInt(b.SuiteID, 10),
+			strconv.FormatInt(b.UserID, 10),
+			b.StartDate.Format(time.RFC3339),
+			b.EndDate.Format(time.RFC3339),
+			b.CreatedAt.Format(time.RFC3339),
+			formatNullTime(b.UpdatedAt),
+			formatNullTime(b.ConfirmedAt),
+			b.ArchivedAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
+
+func formatNullTime(t null.Time) string {
+	if !t.Valid {
+		return ""
+	}
+
+	return t.Time.Format(time.RFC3339)
+}
@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Названия задач планировщика, под ними задачи настраиваются и запускаются вручную
+const (
+	JobReminders  = "reminders"
+	JobDigests    = "digests"
+	JobCleanup    = "cleanup"
+	JobPartitions = "partitions"
+)
+
+var ErrPartialFailure = errors.New("failed to send some of the notifications")
+
+// SendReminders releases the deferred notifications whose quiet hours are over and publishes the due reminders.
+func (s *Service) SendReminders(ctx context.Context) error {
+	const op = "service.scheduler.SendReminders"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := s.tracer.Start(ctx, op)
+	defer span.End()
+
+	failed, err := s.releaseDeferred(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to release deferred notifications", sl.Err(err))
+	}
+
+	bookings, err := s.getBookings(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to get bookings", sl.Err(err))
+		return err
+	}
+
+	if len(bookings) != 0 {
+		span.AddEvent("bookings to send acquired", trace.WithAttributes(attribute.Int("quantity", len(bookings))))
+
+		failed += s.sendBookings(ctx, bookings)
+		span.AddEvent("bookings sent", trace.WithAttributes(attribute.Int("failed", failed)))
+	} else {
+		log.Debug("no bookings to send")
+	}
+
+	if failed != 0 {
+		err = fmt.Errorf("%w: %d failed", ErrPartialFailure, failed)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// SendDigests publishes the daily digests that are due.
+func (s *Service) SendDigests(ctx context.Context) error {
+	const op = "service.scheduler.SendDigests"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := s.tracer.Start(ctx, op)
+	defer span.End()
+
+	failed, err := s.sendDigests(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to send digests", sl.Err(err))
+		return err
+	}
+
+	if failed != 0 {
+		err = fmt.Errorf("%w: %d failed", ErrPartialFailure, failed)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// CleanUp archives the bookings that ended longer than the booking TTL ago and forgets the used action tokens
+// that have expired.
+func (s *Service) CleanUp(ctx context.Context) error {
+	const op = "service.scheduler.CleanUp"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	err := s.cleanUpOldBookings(ctx)
+
+	purged, errPurge := s.bookingRepository.PurgeActionTokens(ctx, time.Now())
+	if errPurge != nil {
+		log.Error("failed to purge used action tokens", sl.Err(errPurge))
+		return errors.Join(err, errPurge)
+	}
+
+	if purged != 0 {
+		log.Info("used action tokens purged", slog.Int64("quantity", purged))
+	}
+
+	return err
+}
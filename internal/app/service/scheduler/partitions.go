@@ -0,0 +1,102 @@
+package scheduler
+
+import (
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MaintainPartitions creates the partitions of the bookings table for the current month and the months ahead
+// and removes the partitions of the months that ended longer than the booking TTL ago. An expired partition
+// is detached once all of its bookings are older than the TTL, or dropped once the cleanup job archived them.
+func (s *Service) MaintainPartitions(ctx context.Context) error {
+	const op = "service.scheduler.MaintainPartitions"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := s.tracer.Start(ctx, op)
+	defer span.End()
+
+	now := time.Now()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for i := 0; i <= s.partitionsAhead; i++ {
+		created, err := s.partitionRepository.CreatePartition(ctx, month.AddDate(0, i, 0))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to create partition", sl.Err(err))
+			return err
+		}
+		if created {
+			log.Info("partition created", slog.String("month", month.AddDate(0, i, 0).Format("2006-01")))
+		}
+	}
+
+	span.AddEvent("partitions ahead created", trace.WithAttributes(attribute.Int("months", s.partitionsAhead)))
+
+	partitions, err := s.partitionRepository.GetPartitions(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to get partitions", sl.Err(err))
+		return err
+	}
+
+	cutoff := now.Add(-s.bookingTTL)
+	for _, p := range partitions {
+		// в секции могут быть бронирования, которые начались в этом месяце, а закончились позже
+		if p.Month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+
+		// отсоединяется секция, все бронирования которой устарели; удаляется только пустая
+		var endingAfter time.Time
+		if s.detachExpired {
+			endingAfter = cutoff
+		}
+
+		active, err := s.partitionRepository.HasBookings(ctx, p.Name, endingAfter)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to check partition", sl.Err(err), slog.String("partition", p.Name))
+			return err
+		}
+		if active {
+			log.Debug("expired partition still has bookings", slog.String("partition", p.Name))
+			continue
+		}
+
+		// у напоминаний нет внешнего ключа на секционированную таблицу, их удаляет только триггер удаления строк
+		err = s.txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+			errTx := s.partitionRepository.DeleteReminders(ctx, p.Name)
+			if errTx != nil {
+				return errTx
+			}
+
+			if s.detachExpired {
+				return s.partitionRepository.DetachPartition(ctx, p.Name)
+			}
+
+			return s.partitionRepository.DropPartition(ctx, p.Name)
+		})
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to remove expired partition", sl.Err(err), slog.String("partition", p.Name))
+			return err
+		}
+
+		span.AddEvent("expired partition removed", trace.WithAttributes(attribute.String("partition", p.Name)))
+		log.Info("expired partition removed", slog.String("partition", p.Name), slog.Bool("detached", s.detachExpired))
+	}
+
+	return nil
+}
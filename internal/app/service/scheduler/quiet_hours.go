@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"time"
+
+	"booking-schedule/internal/app/model"
+)
+
+const clockLayout = "15:04"
+
+// quietHoursEnd reports whether at falls into the recipient's quiet hours and returns the moment they end.
+// Quiet hours are set in the recipient's time zone and may span midnight, e.g. 22:00-08:00.
+func quietHoursEnd(recipient *model.Recipient, at time.Time) (time.Time, bool) {
+	if !recipient.QuietHoursStart.Valid || !recipient.QuietHoursEnd.Valid {
+		return time.Time{}, false
+	}
+
+	from, err := time.Parse(clockLayout, recipient.QuietHoursStart.String)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	to, err := time.Parse(clockLayout, recipient.QuietHoursEnd.String)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(recipient.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	local := at.In(loc)
+	start := time.Date(local.Year(), local.Month(), local.Day(), from.Hour(), from.Minute(), 0, 0, loc)
+	end := time.Date(local.Year(), local.Month(), local.Day(), to.Hour(), to.Minute(), 0, 0, loc)
+
+	switch {
+	case start.Equal(end):
+		return time.Time{}, false
+	case start.Before(end):
+		if !local.Before(start) && local.Before(end) {
+			return end, true
+		}
+	default:
+		if local.Before(end) {
+			return end, true
+		}
+		if !local.Before(start) {
+			return end.AddDate(0, 0, 1), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// deferUntil returns when a reminder due at dueAt should be sent instead if it falls into quiet hours.
+// Reminders that would be late for the booking start (or end, for end reminders) if deferred
+// are considered urgent and sent anyway.
+func deferUntil(notification *model.BookingNotification, dueAt time.Time) (time.Time, bool) {
+	deadline := notification.StartDate
+	if notification.Kind == model.ReminderKindEnd {
+		deadline = notification.EndDate
+	}
+
+	end, ok := quietHoursEnd(&notification.Recipient, dueAt)
+	if !ok || !end.Before(deadline) {
+		return time.Time{}, false
+	}
+
+	return end, true
+}
@@ -1,12 +1,14 @@
 package scheduler
 
 import (
+	"booking-schedule/internal/app/message"
 	"booking-schedule/internal/app/model"
 	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
 	"context"
 	"encoding/json"
 	"log/slog"
-	"sync"
+	"slices"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
@@ -14,88 +16,117 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
-func (s *Service) Run(ctx context.Context) {
-	const op = "service.scheduler.Run"
+// getBookings returns the reminders that became due since the previous check. Reminders that became due
+// earlier, e.g. while the scheduler was down, are returned while their bookings have not started yet.
+func (s *Service) getBookings(ctx context.Context) ([]*model.BookingNotification, error) {
+	const op = "service.scheduler.getBookings"
 
 	log := s.log.With(
 		slog.String("op", op),
 	)
-	log.Info("scheduler initiated")
+	ctx, span := s.tracer.Start(ctx, op)
+	defer span.End()
 
-	ticker := time.NewTicker(s.checkPeriod)
+	now := time.Now()
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			s.handleBookings(ctx)
-		}
+	since := s.lastReminderCheck
+	if since.IsZero() {
+		since = now
+	}
+
+	bookings, err := s.bookingRepository.GetDueReminders(ctx, now, since)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to get due reminders", sl.Err(err))
+		return nil, err
 	}
+
+	s.lastReminderCheck = now
+
+	return bookings, nil
 }
 
-func (s *Service) handleBookings(ctx context.Context) {
-	const op = "service.scheduler.handleBookings"
-	wg := &sync.WaitGroup{}
+// cleanUpOldBookings moves the bookings that ended longer than the booking TTL ago out of the bookings table
+// in batches. Depending on the archive target a batch is moved into the archive table or written to a file
+// in the archive directory and then deleted, so no booking is deleted before it is saved.
+func (s *Service) cleanUpOldBookings(ctx context.Context) error {
+	const op = "scheduler.service.cleanUpOldBookings"
 
 	log := s.log.With(
 		slog.String("op", op),
+		slog.String("target", s.archiveTarget),
 	)
-	ctx, span := s.tracer.Start(ctx, op)
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("target", s.archiveTarget)))
 	defer span.End()
 
-	log.Debug("started handling")
-
-	wg.Add(2)
+	archivedAt := time.Now()
+	before := archivedAt.Add(-s.bookingTTL)
 
-	go func(*sync.WaitGroup) {
-		defer wg.Done()
-		bookings, err := s.getBookings(ctx)
+	var total int64
+	for batch := 1; ctx.Err() == nil; batch++ {
+		bookings, err := s.archiveRepository.GetExpiredBookings(ctx, before, s.archiveBatchSize)
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
-			log.Error("failed to get bookings", sl.Err(err))
-			return
+			log.Error("failed to get old bookings", sl.Err(err))
+			return err
 		}
 
 		if len(bookings) == 0 {
-			log.Debug("no bookings to send")
-			return
+			break
 		}
 
-		span.AddEvent("bookings to send acquired", trace.WithAttributes(attribute.Int("quantity", len(bookings))))
+		for _, b := range bookings {
+			b.ArchivedAt = archivedAt
+		}
 
-		for _, val := range bookings {
-			err = s.sendBooking(val)
+		var moved int64
+		if s.archiveTarget == ArchiveTargetFile {
+			var name string
+			name, err = s.exportBookings(bookings, archivedAt, batch)
 			if err != nil {
 				span.RecordError(err)
 				span.SetStatus(codes.Error, err.Error())
-				log.Error("failed to send booking:", sl.Err(err))
+				log.Error("failed to export old bookings", sl.Err(err))
+				return err
 			}
-		}
-		span.AddEvent("bookings sent")
 
-	}(wg)
+			span.AddEvent("bookings exported", trace.WithAttributes(attribute.String("file", name)))
 
-	go func(*sync.WaitGroup) {
-		defer wg.Done()
-		err := s.cleanUpOldBookings(ctx)
+			// бронирования, выгруженные, но не удаленные из-за ошибки, повторно попадут в следующий файл
+			moved, err = s.archiveRepository.DeleteBookings(ctx, bookings, before)
+		} else {
+			moved, err = s.archiveRepository.ArchiveBookings(ctx, bookings, before, archivedAt)
+		}
 		if err != nil {
 			span.RecordError(err)
 			span.SetStatus(codes.Error, err.Error())
-			log.Error("failed to clean up old bookings", sl.Err(err))
-			return
+			log.Error("failed to archive old bookings", sl.Err(err))
+			return err
 		}
-		log.Debug("old bookings handled")
-	}(wg)
 
-	wg.Wait()
-	span.AddEvent("bookings handled")
-	log.Debug("finished handling bookings")
+		total += moved
+
+		// неполная партия была последней; если ничего не перенесено, бронирования изменились, и повтор не поможет
+		if uint64(len(bookings)) < s.archiveBatchSize || moved == 0 {
+			break
+		}
+	}
+
+	span.AddEvent("old bookings archived", trace.WithAttributes(attribute.Int64("quantity", total)))
+	if total != 0 {
+		log.Info("old bookings archived", slog.Int64("quantity", total))
+	}
+
+	return ctx.Err()
 }
 
-func (s *Service) getBookings(ctx context.Context) ([]*model.BookingInfo, error) {
-	const op = "service.scheduler.getBookings"
+// sendBookings publishes reminders for the bookings as one batch and returns the number of reminders that failed.
+// Reminders of types the user opted out of are dropped, the ones due in quiet hours are deferred.
+// Handled reminders are marked as sent, the failed ones are retried on the next check.
+func (s *Service) sendBookings(ctx context.Context, bookings []*model.BookingNotification) int {
+	const op = "service.scheduler.sendBookings"
 
 	log := s.log.With(
 		slog.String("op", op),
@@ -103,23 +134,110 @@ func (s *Service) getBookings(ctx context.Context) ([]*model.BookingInfo, error)
 	ctx, span := s.tracer.Start(ctx, op)
 	defer span.End()
 
-	end := time.Now()
-	end = time.Date(end.Year(), end.Month(), end.Day(), end.Hour(), end.Minute(), 0, 0, end.Location())
-	start := end.Add(-s.checkPeriod)
+	var failed int
+	msgs := make([]broker.Message, 0, len(bookings))
+	sent := make([]*model.BookingNotification, 0, len(bookings))
+	var deferred []*model.DeferredNotification
+	var deferredReminders, handled []*model.Reminder
+
+	for _, booking := range bookings {
+		msgType := message.TypeBookingReminder
+		if booking.Kind == model.ReminderKindEnd {
+			msgType = message.TypeBookingEnding
+		}
+
+		if slices.Contains(booking.DisabledTypes, msgType) {
+			log.Debug("user opted out of reminders", slog.String("booking_id", booking.ID.String()), slog.String("type", msgType))
+			handled = append(handled, &model.Reminder{BookingID: booking.ID, Kind: booking.Kind, LeadTime: booking.LeadTime})
+			continue
+		}
+
+		reminder := message.NewBookingReminder(&booking.BookingInfo, booking.Kind, booking.LeadTime)
+		reminder.Recipient = &message.Recipient{
+			Name:          booking.Name,
+			TelegramID:    booking.TelegramID,
+			Language:      booking.Language,
+			Timezone:      booking.Timezone,
+			DisabledTypes: booking.DisabledTypes,
+		}
+		key := message.ReminderKey(booking.ID, booking.Kind, reminder.DueAt)
+
+		env, err := message.NewEnvelope(msgType, message.BookingReminderVersion, key, reminder)
+		if err != nil {
+			failed++
+			span.RecordError(err)
+			log.Error("failed to build message", sl.Err(err), slog.String("booking_id", booking.ID.String()))
+			continue
+		}
+
+		data, err := json.Marshal(env)
+		if err != nil {
+			failed++
+			span.RecordError(err)
+			log.Error("failed to marshal message", sl.Err(err), slog.String("booking_id", booking.ID.String()))
+			continue
+		}
+
+		if sendAt, ok := deferUntil(booking, reminder.DueAt); ok {
+			deferred = append(deferred, &model.DeferredNotification{
+				IdempotencyKey: key,
+				UserID:         booking.UserID,
+				Type:           env.Type,
+				Body:           data,
+				SendAt:         sendAt.UTC(),
+			})
+			deferredReminders = append(deferredReminders, &model.Reminder{BookingID: booking.ID, Kind: booking.Kind, LeadTime: booking.LeadTime})
+			continue
+		}
+
+		msgs = append(msgs, broker.Message{
+			ID:          env.IdempotencyKey,
+			Type:        env.Type,
+			ContentType: message.ContentTypeJSON,
+			Body:        data,
+		})
+		sent = append(sent, booking)
+	}
+
+	if len(deferred) != 0 {
+		err := s.notificationRepository.DeferNotifications(ctx, deferred)
+		if err != nil {
+			failed += len(deferred)
+			span.RecordError(err)
+			log.Error("failed to defer notifications", sl.Err(err))
+		} else {
+			span.AddEvent("notifications deferred till the end of quiet hours", trace.WithAttributes(attribute.Int("quantity", len(deferred))))
+			handled = append(handled, deferredReminders...)
+		}
+	}
+
+	if len(msgs) != 0 {
+		errs := s.producer.PublishBatch(ctx, msgs)
+		for i, err := range errs {
+			if err != nil {
+				failed++
+				span.RecordError(err)
+				log.Error("failed to send booking", sl.Err(err), slog.String("booking_id", sent[i].ID.String()))
+				continue
+			}
+			handled = append(handled, &model.Reminder{BookingID: sent[i].ID, Kind: sent[i].Kind, LeadTime: sent[i].LeadTime})
+		}
+	}
 
-	bookings, err := s.bookingRepository.GetBookingListByDate(ctx, start, end)
+	err := s.bookingRepository.MarkRemindersSent(ctx, handled)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		log.Error("failed to get list by date", sl.Err(err))
-		return nil, err
+		log.Error("failed to mark reminders as sent", sl.Err(err))
 	}
 
-	return bookings, nil
+	return failed
 }
 
-func (s *Service) cleanUpOldBookings(ctx context.Context) error {
-	const op = "scheduler.service.cleanUpOldBookings"
+// releaseDeferred publishes deferred notifications whose quiet hours are over and returns the number of
+// notifications that failed. Notifications the user opted out of in the meantime are dropped.
+func (s *Service) releaseDeferred(ctx context.Context) (int, error) {
+	const op = "service.scheduler.releaseDeferred"
 
 	log := s.log.With(
 		slog.String("op", op),
@@ -127,26 +245,57 @@ func (s *Service) cleanUpOldBookings(ctx context.Context) error {
 	ctx, span := s.tracer.Start(ctx, op)
 	defer span.End()
 
-	err := s.bookingRepository.DeleteBookingsBeforeDate(ctx, time.Now().Add(-s.bookingTTL))
+	notifications, err := s.notificationRepository.GetDueNotifications(ctx, time.Now())
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
-		log.Error("failed to clean up old bookings", sl.Err(err))
-		return err
+		return 0, err
 	}
 
-	return nil
-}
+	if len(notifications) == 0 {
+		return 0, nil
+	}
 
-func (s *Service) sendBooking(booking *model.BookingInfo) error {
-	data, err := json.Marshal(booking)
-	if err != nil {
-		return err
+	done := make([]string, 0, len(notifications))
+	msgs := make([]broker.Message, 0, len(notifications))
+	for _, n := range notifications {
+		if n.Disabled {
+			done = append(done, n.IdempotencyKey)
+			continue
+		}
+
+		msgs = append(msgs, broker.Message{
+			ID:          n.IdempotencyKey,
+			Type:        n.Type,
+			ContentType: message.ContentTypeJSON,
+			Body:        n.Body,
+		})
 	}
-	err = s.rabbitProducer.Publish(data)
+
+	var failed int
+	errs := s.producer.PublishBatch(ctx, msgs)
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			span.RecordError(err)
+			log.Error("failed to send deferred notification", sl.Err(err), slog.String("idempotency_key", msgs[i].ID))
+			continue
+		}
+		done = append(done, msgs[i].ID)
+	}
+
+	span.AddEvent("deferred notifications released", trace.WithAttributes(
+		attribute.Int("quantity", len(notifications)),
+		attribute.Int("failed", failed),
+	))
+
+	// неотправленные уведомления остаются в таблице до следующей проверки
+	err = s.notificationRepository.DeleteNotifications(ctx, done)
 	if err != nil {
-		return err
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return failed, err
 	}
 
-	return nil
+	return failed, nil
 }
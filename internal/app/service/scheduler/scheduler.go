@@ -1,8 +1,13 @@
 package scheduler
 
 import (
+	"booking-schedule/internal/app/repository/archive"
 	"booking-schedule/internal/app/repository/booking"
-	"booking-schedule/internal/pkg/rabbit"
+	"booking-schedule/internal/app/repository/notification"
+	"booking-schedule/internal/app/repository/partition"
+	"booking-schedule/internal/app/repository/preferences"
+	"booking-schedule/internal/pkg/broker"
+	"booking-schedule/internal/pkg/db"
 	"log/slog"
 	"time"
 
@@ -10,21 +15,46 @@ import (
 )
 
 type Service struct {
-	bookingRepository booking.Repository
-	log               *slog.Logger
-	tracer            trace.Tracer
-	rabbitProducer    rabbit.Producer
-	checkPeriod       time.Duration
-	bookingTTL        time.Duration
+	bookingRepository      booking.Repository
+	notificationRepository notification.Repository
+	preferencesRepository  preferences.Repository
+	archiveRepository      archive.Repository
+	partitionRepository    partition.Repository
+	txManager              db.TxManager
+	log                    *slog.Logger
+	tracer                 trace.Tracer
+	producer               broker.Producer
+	bookingTTL             time.Duration
+	// Устаревшие бронирования переносятся в таблицу архива или выгружаются в файлы каталога archiveDir
+	archiveTarget    string
+	archiveFormat    string
+	archiveDir       string
+	archiveBatchSize uint64
+	// Секции бронирований создаются на partitionsAhead месяцев вперед; устаревшие секции отсоединяются
+	// вместе с бронированиями, если detachExpired, или удаляются, когда их бронирования перенесены в архив
+	partitionsAhead int
+	detachExpired   bool
+	// Время предыдущей проверки напоминаний; задача напоминаний не запускается параллельно сама с собой
+	lastReminderCheck time.Time
 }
 
-func NewSchedulerService(bookingRepository booking.Repository, log *slog.Logger, tracer trace.Tracer, rabbitProducer rabbit.Producer, checkPeriod time.Duration, bookingTTL time.Duration) *Service {
+func NewSchedulerService(bookingRepository booking.Repository, notificationRepository notification.Repository, preferencesRepository preferences.Repository, archiveRepository archive.Repository, partitionRepository partition.Repository, txManager db.TxManager, log *slog.Logger, tracer trace.Tracer, producer broker.Producer, bookingTTL time.Duration, archiveTarget string, archiveFormat string, archiveDir string, archiveBatchSize uint64, partitionsAhead int, detachExpired bool) *Service {
 	return &Service{
-		bookingRepository: bookingRepository,
-		log:               log,
-		tracer:            tracer,
-		rabbitProducer:    rabbitProducer,
-		checkPeriod:       checkPeriod,
-		bookingTTL:        bookingTTL,
+		bookingRepository:      bookingRepository,
+		notificationRepository: notificationRepository,
+		preferencesRepository:  preferencesRepository,
+		archiveRepository:      archiveRepository,
+		partitionRepository:    partitionRepository,
+		txManager:              txManager,
+		log:                    log,
+		tracer:                 tracer,
+		producer:               producer,
+		bookingTTL:             bookingTTL,
+		archiveTarget:          archiveTarget,
+		archiveFormat:          archiveFormat,
+		archiveDir:             archiveDir,
+		archiveBatchSize:       archiveBatchSize,
+		partitionsAhead:        partitionsAhead,
+		detachExpired:          detachExpired,
 	}
 }
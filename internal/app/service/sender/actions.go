@@ -0,0 +1,87 @@
+package sender
+
+import (
+	"booking-schedule/internal/app/message"
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/pkg/telegram"
+	"context"
+	"net/url"
+	"strings"
+)
+
+// actionLabels are the captions of action buttons in the supported languages.
+var actionLabels = map[string]map[string]string{
+	model.LanguageRU: {
+		model.ActionConfirm: "Подтвердить",
+		model.ActionCancel:  "Отменить",
+		model.ActionExtend:  "Продлить на 30 минут",
+	},
+	model.LanguageEN: {
+		model.ActionConfirm: "Confirm",
+		model.ActionCancel:  "Cancel",
+		model.ActionExtend:  "Extend by 30 minutes",
+	},
+}
+
+// actionView is an action the recipient can take by following the link, e.g. from an email.
+type actionView struct {
+	Action string
+	Label  string
+	URL    string
+}
+
+// reminderActions returns the actions offered in the reminder: reminders about the start let the user confirm
+// attendance or cancel the booking, reminders about the end let them extend it. Action links stay valid
+// until the booking ends.
+func (s *Service) reminderActions(ctx context.Context, reminder *message.BookingReminder, language string) ([]actionView, error) {
+	if s.actions == nil || reminder.UserID == 0 {
+		return nil, nil
+	}
+
+	actions := []string{model.ActionConfirm, model.ActionCancel}
+	if reminder.Kind == model.ReminderKindEnd {
+		actions = []string{model.ActionExtend}
+	}
+
+	labels, ok := actionLabels[language]
+	if !ok {
+		labels = actionLabels[model.DefaultLanguage]
+	}
+
+	res := make([]actionView, 0, len(actions))
+	for _, action := range actions {
+		token, err := s.actions.GenerateToken(ctx, &model.BookingAction{
+			Action:    action,
+			BookingID: reminder.BookingID,
+			UserID:    reminder.UserID,
+		}, reminder.EndDate)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, actionView{
+			Action: action,
+			Label:  labels[action],
+			URL:    strings.TrimSuffix(s.actionURL, "/") + "/" + url.PathEscape(token),
+		})
+	}
+
+	return res, nil
+}
+
+// keyboard lays the actions out as a row of Telegram buttons opening the action links.
+func keyboard(actions []actionView) [][]telegram.InlineKeyboardButton {
+	if len(actions) == 0 {
+		return nil
+	}
+
+	row := make([]telegram.InlineKeyboardButton, 0, len(actions))
+	for _, action := range actions {
+		row = append(row, telegram.InlineKeyboardButton{
+			Text: action.Label,
+			URL:  action.URL,
+		})
+	}
+
+	return [][]telegram.InlineKeyboardButton{row}
+}
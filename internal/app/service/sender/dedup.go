@@ -0,0 +1,94 @@
+package sender
+
+import (
+	"booking-schedule/internal/app/message"
+	"booking-schedule/internal/app/repository/dedup"
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// handleOnce passes the envelope to handle unless a notification with the same idempotency key has been sent.
+// The key is leased for the time of handling, so replicas that received copies of the message retry them
+// later instead of sending the notification at the same time. Messages without keys are always handled.
+func (s *Service) handleOnce(ctx context.Context, env *message.Envelope, handle handler) error {
+	const op = "service.sender.handleOnce"
+
+	if env.IdempotencyKey == "" {
+		return handle(ctx, env)
+	}
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("idempotency_key", env.IdempotencyKey),
+	)
+	span := trace.SpanFromContext(ctx)
+
+	err := s.dedup.Claim(ctx, env.IdempotencyKey, time.Now(), s.dedupLease)
+	if errors.Is(err, dedup.ErrAlreadySent) {
+		return fmt.Errorf("%w: %s", ErrDuplicate, env.IdempotencyKey)
+	}
+	if err != nil {
+		return err
+	}
+
+	span.AddEvent("notification claimed", trace.WithAttributes(attribute.String("idempotency_key", env.IdempotencyKey)))
+
+	err = handle(ctx, env)
+	if err != nil && !errors.Is(err, ErrOptedOut) {
+		// ошибка освобождения не мешает повторной попытке: ключ можно будет занять, когда истечет аренда
+		releaseErr := s.dedup.Release(ctx, env.IdempotencyKey)
+		if releaseErr != nil {
+			log.Warn("could not release notification", sl.Err(releaseErr))
+		}
+		return err
+	}
+
+	// уведомление уже отправлено, поэтому ошибка записи не приводит к повторной попытке
+	markErr := s.dedup.MarkSent(ctx, env.IdempotencyKey, time.Now())
+	if markErr != nil {
+		log.Warn("could not mark notification as sent, a copy may be sent once the lease expires", sl.Err(markErr))
+	}
+
+	return err
+}
+
+// PurgeSent removes the keys of the notifications sent longer than the retention ago, every period
+// until the context is cancelled.
+func (s *Service) PurgeSent(ctx context.Context, period time.Duration) {
+	const op = "service.sender.PurgeSent"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ctx, span := s.tracer.Start(ctx, op)
+
+			purged, err := s.dedup.Purge(ctx, time.Now().Add(-s.dedupRetention))
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				log.Error("failed to purge sent notifications", sl.Err(err))
+			} else if purged != 0 {
+				log.Info("purged sent notifications", slog.Int64("purged", purged))
+			}
+
+			span.End()
+		}
+	}
+}
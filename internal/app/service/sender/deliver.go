@@ -0,0 +1,36 @@
+package sender
+
+import (
+	"booking-schedule/internal/app/message"
+	"booking-schedule/internal/pkg/telegram"
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrNoTelegramID = errors.New("recipient has no telegram id")
+
+// deliver sends the notification text with the optional inline keyboard to the recipient's Telegram chat.
+// It does nothing if the sender has no bot configured, the notification is then only logged.
+// Notifications for recipients without a Telegram ID can never be delivered and are reported as malformed.
+func (s *Service) deliver(ctx context.Context, telegramID int64, text string, buttons [][]telegram.InlineKeyboardButton) error {
+	if s.telegramClient == nil {
+		return nil
+	}
+
+	if telegramID == 0 {
+		return fmt.Errorf("%w: %w", message.ErrMalformed, ErrNoTelegramID)
+	}
+
+	req := &telegram.SendMessageRequest{
+		ChatID: telegramID,
+		Text:   text,
+	}
+	if len(buttons) != 0 {
+		req.ReplyMarkup = &telegram.InlineKeyboardMarkup{InlineKeyboard: buttons}
+	}
+
+	_, err := s.telegramClient.SendMessage(ctx, req)
+
+	return err
+}
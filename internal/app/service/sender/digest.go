@@ -0,0 +1,114 @@
+package sender
+
+import (
+	"booking-schedule/internal/app/message"
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func (s *Service) handleBookingDigest(ctx context.Context, env *message.Envelope) error {
+	const op = "service.sender.handleBookingDigest"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	_, span := s.tracer.Start(ctx, op)
+	defer span.End()
+
+	digest, err := message.DecodeBookingDigest(env)
+	if err != nil {
+		log.Error("failed to decode booking digest", sl.Err(err), slog.Int("version", env.Version))
+		return err
+	}
+
+	if digest.Recipient == nil {
+		return fmt.Errorf("%w: digest without recipient", message.ErrMalformed)
+	}
+
+	span.AddEvent("message decoded", trace.WithAttributes(
+		attribute.Int64("user_id", digest.UserID),
+		attribute.Int("bookings", len(digest.Bookings)),
+	))
+
+	recipient := digest.Recipient
+	if slices.Contains(recipient.DisabledTypes, env.Type) {
+		return fmt.Errorf("%w: %s", ErrOptedOut, env.Type)
+	}
+
+	loc, err := time.LoadLocation(recipient.Timezone)
+	if err != nil {
+		log.Warn("unknown recipient time zone, falling back to UTC", sl.Err(err), slog.String("timezone", recipient.Timezone))
+		loc = time.UTC
+	}
+
+	date, err := time.ParseInLocation(time.DateOnly, digest.Date, loc)
+	if err != nil {
+		return fmt.Errorf("%w: %s", message.ErrMalformed, err)
+	}
+
+	view := &digestView{
+		Name:     recipient.Name,
+		Date:     date,
+		Timezone: loc.String(),
+		Bookings: make([]digestBookingView, 0, len(digest.Bookings)),
+	}
+	for _, booking := range digest.Bookings {
+		view.Bookings = append(view.Bookings, digestBookingView{
+			BookingID: booking.BookingID.String(),
+			SuiteID:   booking.SuiteID,
+			StartDate: booking.StartDate.In(loc),
+			EndDate:   booking.EndDate.In(loc),
+		})
+	}
+
+	text, err := s.templates.Render(recipient.Language, env.Type, view)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to render notification", sl.Err(err), slog.String("language", recipient.Language))
+		return err
+	}
+
+	span.AddEvent("notification rendered", trace.WithAttributes(attribute.String("language", recipient.Language)))
+	// текст сводки и адрес получателя в журнал не попадают
+	log.Info("notification rendered",
+		slog.Int64("user_id", digest.UserID),
+		slog.String("language", recipient.Language),
+	)
+
+	err = s.deliver(ctx, recipient.TelegramID, text, nil)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to deliver notification", sl.Err(err))
+		return err
+	}
+
+	span.AddEvent("notification delivered")
+
+	return nil
+}
+
+// digestView is the data booking digest templates are executed with.
+// Dates are already converted to the recipient's time zone.
+type digestView struct {
+	Name     string
+	Date     time.Time
+	Timezone string
+	Bookings []digestBookingView
+}
+
+type digestBookingView struct {
+	BookingID string
+	SuiteID   int64
+	StartDate time.Time
+	EndDate   time.Time
+}
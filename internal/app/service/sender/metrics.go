@@ -0,0 +1,65 @@
+package sender
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type metrics struct {
+	received  metric.Int64Counter
+	processed metric.Int64Counter
+	duration  metric.Float64Histogram
+	inFlight  metric.Int64UpDownCounter
+}
+
+func newMetrics(meter metric.Meter) (*metrics, error) {
+	received, err := meter.Int64Counter(
+		"sender.messages.received",
+		metric.WithDescription("Number of notification messages taken from the queue"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	processed, err := meter.Int64Counter(
+		"sender.messages.processed",
+		metric.WithDescription("Number of handled notification messages by outcome"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram(
+		"sender.messages.duration",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Measures the duration of notification message handling"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	inFlight, err := meter.Int64UpDownCounter(
+		"sender.messages.in_flight",
+		metric.WithDescription("Number of notification messages being handled by the workers"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metrics{
+		received:  received,
+		processed: processed,
+		duration:  duration,
+		inFlight:  inFlight,
+	}, nil
+}
+
+func (m *metrics) record(ctx context.Context, outcome string, elapsed time.Duration) {
+	attrs := metric.WithAttributes(attribute.String("outcome", outcome))
+
+	m.processed.Add(ctx, 1, attrs)
+	m.duration.Record(ctx, float64(elapsed.Milliseconds()), attrs)
+}
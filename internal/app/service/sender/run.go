@@ -1,85 +1,339 @@
 package sender
 
 import (
+	"booking-schedule/internal/app/message"
 	"booking-schedule/internal/app/model"
 	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
-	"os"
+	"slices"
+	"sync"
+	"time"
 
-	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func (s *Service) Run(ctx context.Context) {
+const (
+	outcomeDelivered    = "delivered"
+	outcomeRetried      = "retried"
+	outcomeDeadLettered = "dead_lettered"
+	outcomeSuppressed   = "suppressed"
+	outcomeDuplicate    = "duplicate"
+	outcomeFailed       = "failed"
+)
+
+// Run hands the deliveries to a pool of workers until ctx is cancelled. Then it stops consuming and lets
+// the workers finish the messages already received, aborting them once the shutdown timeout expires.
+func (s *Service) Run(ctx context.Context) error {
 	const op = "service.sender.Run"
 
 	log := s.log.With(
 		slog.String("op", op),
 	)
 
-	log.Info("sender service initiated")
-
-	msgChan, err := s.rabbitConsumer.Consume()
+	msgChan, err := s.consumer.Consume()
 	if err != nil {
 		log.Error("could not get channel to receive messages: ", sl.Err(err))
-		os.Exit(1)
+		return err
 	}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case msg := <-msgChan:
-			err = s.receiveBookings(msg)
-			if err != nil {
-				log.Error("could not receive message: ", sl.Err(err))
-			}
-			err = msg.Ack(false)
-			if err != nil {
-				log.Error("could not acknowledge message acquiring: ", sl.Err(err))
+	log.Info("sender service initiated", slog.Int("workers", s.workers))
+
+	// обработка полученных сообщений не прерывается вместе с ctx, чтобы завершиться при остановке
+	handleCtx, abort := context.WithCancel(context.WithoutCancel(ctx))
+	defer abort()
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for msg := range msgChan {
+				s.handleMessage(handleCtx, msg)
 			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		log.Info("message channel closed")
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Info("stopping sender service, finishing in-flight messages")
+
+	err = s.consumer.Cancel()
+	if err != nil {
+		log.Error("could not stop consuming, closing consumer", sl.Err(err))
+		s.consumer.Close() //nolint:errcheck
+	}
+
+	timer := time.NewTimer(s.shutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		log.Warn("shutdown timeout expired, aborting in-flight messages")
+		abort()
+		s.consumer.Close() //nolint:errcheck
+		<-done
+	}
+
+	return nil
+}
+
+func (s *Service) handleMessage(ctx context.Context, msg *broker.Delivery) {
+	const op = "service.sender.handleMessage"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("message_id", msg.ID),
+	)
+
+	ctx = broker.ExtractTraceContext(ctx, msg)
+	ctx, span := s.tracer.Start(ctx, op,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingOperationDeliver,
+			semconv.MessagingMessageID(msg.ID),
+			attribute.Int("retry_count", msg.Retries),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	s.metrics.received.Add(ctx, 1)
+	s.metrics.inFlight.Add(ctx, 1)
+	defer s.metrics.inFlight.Add(ctx, -1)
+
+	outcome := outcomeDelivered
+	defer func() {
+		s.metrics.record(ctx, outcome, time.Since(start))
+	}()
+
+	err := s.receiveMessage(ctx, msg)
+	if errors.Is(err, ErrOptedOut) {
+		span.AddEvent("notification suppressed")
+		log.Info("notification suppressed", sl.Err(err))
+		outcome = outcomeSuppressed
+		err = nil
+	}
+	if errors.Is(err, ErrDuplicate) {
+		span.AddEvent("duplicate notification skipped")
+		log.Info("duplicate notification skipped", sl.Err(err))
+		outcome = outcomeDuplicate
+		err = nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("could not receive message: ", sl.Err(err))
+		outcome = s.rejectMessage(ctx, msg, err)
+		return
+	}
+
+	err = msg.Ack()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("could not acknowledge message acquiring: ", sl.Err(err))
+		outcome = outcomeFailed
+	}
+}
+
+// rejectMessage sends malformed messages straight to the dead-letter queue
+// and schedules a delayed retry for the rest. It returns what became of the message.
+func (s *Service) rejectMessage(ctx context.Context, msg *broker.Delivery, reason error) string {
+	const op = "service.sender.rejectMessage"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int("retry_count", msg.Retries),
+	)
+
+	span := trace.SpanFromContext(ctx)
+
+	if errors.Is(reason, ErrMalformedMessage) {
+		err := msg.Nack()
+		if err != nil {
+			log.Error("could not dead-letter message: ", sl.Err(err))
+			return outcomeFailed
 		}
+		span.AddEvent("malformed message dead-lettered")
+		log.Warn("malformed message dead-lettered")
+		return outcomeDeadLettered
+	}
 
+	err := s.consumer.Retry(msg)
+	if err != nil {
+		if errors.Is(err, broker.ErrRetriesExhausted) {
+			span.AddEvent("message dead-lettered")
+			log.Warn("message dead-lettered", sl.Err(err))
+			return outcomeDeadLettered
+		}
+		log.Error("could not schedule message retry: ", sl.Err(err))
+		return outcomeFailed
+	}
+
+	span.AddEvent("message retry scheduled")
+	log.Info("message retry scheduled")
+
+	return outcomeRetried
+}
+
+// receiveMessage decodes the envelope and passes it to the handler of its type.
+// Messages of unknown types or schema versions are reported as malformed.
+func (s *Service) receiveMessage(ctx context.Context, msg *broker.Delivery) error {
+	const op = "service.sender.receiveMessage"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := s.tracer.Start(ctx, op)
+	defer span.End()
+
+	env, err := message.Decode(msg.Body, msg.ContentType)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to decode message", sl.Err(err))
+		return fmt.Errorf("%w: %s", ErrMalformedMessage, err)
 	}
 
+	span.SetAttributes(
+		attribute.String("message_type", env.Type),
+		attribute.Int("message_version", env.Version),
+	)
+
+	handle, ok := s.handlers[env.Type]
+	if !ok {
+		err = fmt.Errorf("%w: %q", message.ErrUnknownType, env.Type)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("no handler for message", sl.Err(err))
+		return fmt.Errorf("%w: %s", ErrMalformedMessage, err)
+	}
+
+	err = s.handleOnce(ctx, env, handle)
+	if errors.Is(err, ErrOptedOut) || errors.Is(err, ErrDuplicate) {
+		return err
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if errors.Is(err, message.ErrMalformed) || errors.Is(err, message.ErrUnsupportedVersion) {
+			return fmt.Errorf("%w: %s", ErrMalformedMessage, err)
+		}
+		return err
+	}
+
+	return nil
 }
 
-func (s *Service) receiveBookings(msg amqp.Delivery) error {
-	const op = "service.sender.receiveBookings"
+func (s *Service) handleBookingReminder(ctx context.Context, env *message.Envelope) error {
+	const op = "service.sender.handleBookingReminder"
 
 	log := s.log.With(
 		slog.String("op", op),
 	)
+	_, span := s.tracer.Start(ctx, op)
+	defer span.End()
 
-	log.Info(fmt.Sprintf("Received a message: %s", msg.Body))
+	reminder, err := message.DecodeBookingReminder(env)
+	if err != nil {
+		log.Error("failed to decode booking reminder", sl.Err(err), slog.Int("version", env.Version))
+		return err
+	}
+
+	span.AddEvent("message decoded", trace.WithAttributes(attribute.String("booking_id", reminder.BookingID.String())))
+
+	recipient := reminder.Recipient
+	if recipient == nil {
+		recipient = &message.Recipient{
+			Language: model.DefaultLanguage,
+			Timezone: model.DefaultTimezone,
+		}
+	}
+
+	if slices.Contains(recipient.DisabledTypes, env.Type) {
+		return fmt.Errorf("%w: %s", ErrOptedOut, env.Type)
+	}
+
+	loc, err := time.LoadLocation(recipient.Timezone)
+	if err != nil {
+		log.Warn("unknown recipient time zone, falling back to UTC", sl.Err(err), slog.String("timezone", recipient.Timezone))
+		loc = time.UTC
+	}
 
-	var booking *model.BookingInfo
-	err := json.Unmarshal(msg.Body, &booking)
+	actions, err := s.reminderActions(ctx, reminder, recipient.Language)
 	if err != nil {
-		log.Error("failed to unmarshal message", sl.Err(err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to sign reminder actions", sl.Err(err))
 		return err
 	}
 
-	log.Info(fmt.Sprintf(
-		"Booking:  %d \n "+
-			"SuiteID: %d \n "+
-			"StartDate: %v \n "+
-			"EndDate: :%v \n "+
-			"NotifyAt: %v \n "+
-			"OwnerID: %d \n "+
-			"CreatedAt: %v \n "+
-			"UpdatedAt: %v \n\n ",
-		booking.ID,
-		booking.SuiteID,
-		booking.StartDate,
-		booking.EndDate,
-		booking.NotifyAt,
-		booking.UserID,
-		booking.CreatedAt,
-		booking.UpdatedAt,
-	))
+	text, err := s.templates.Render(recipient.Language, env.Type, &reminderView{
+		Name:      recipient.Name,
+		BookingID: reminder.BookingID.String(),
+		SuiteID:   reminder.SuiteID,
+		StartDate: reminder.StartDate.In(loc),
+		EndDate:   reminder.EndDate.In(loc),
+		Timezone:  loc.String(),
+		Actions:   actions,
+	})
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to render notification", sl.Err(err), slog.String("language", recipient.Language))
+		return err
+	}
+
+	span.AddEvent("notification rendered", trace.WithAttributes(attribute.String("language", recipient.Language)))
+	// текст уведомления и адрес получателя в журнал не попадают
+	log.Info("notification rendered",
+		slog.String("booking_id", reminder.BookingID.String()),
+		slog.String("language", recipient.Language),
+		slog.Int("actions", len(actions)),
+	)
+
+	err = s.deliver(ctx, recipient.TelegramID, text, keyboard(actions))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to deliver notification", sl.Err(err))
+		return err
+	}
+
+	span.AddEvent("notification delivered")
 
 	return nil
 }
+
+// reminderView is the data booking reminder templates are executed with.
+// Dates are already converted to the recipient's time zone.
+type reminderView struct {
+	Name      string
+	BookingID string
+	SuiteID   int64
+	StartDate time.Time
+	EndDate   time.Time
+	Timezone  string
+	// Действия с бронированием по ссылкам, например для писем; в Telegram они отправляются кнопками
+	Actions []actionView
+}
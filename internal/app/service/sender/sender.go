@@ -1,18 +1,89 @@
 package sender
 
 import (
-	"booking-schedule/internal/pkg/rabbit"
+	"booking-schedule/internal/app/message"
+	"booking-schedule/internal/app/repository/dedup"
+	"booking-schedule/internal/app/service/action"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
+	"booking-schedule/internal/pkg/telegram"
+	"context"
+	"errors"
 	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ErrMalformedMessage = errors.New("malformed message")
+	ErrOptedOut         = errors.New("recipient opted out of notifications of this type")
+	ErrDuplicate        = errors.New("notification has already been sent")
 )
 
+// Renderer turns a message into the notification text in the recipient's language.
+type Renderer interface {
+	Render(language string, msgType string, data interface{}) (string, error)
+}
+
+// handler processes the payload of one message type.
+type handler func(ctx context.Context, env *message.Envelope) error
+
 type Service struct {
-	log            *slog.Logger
-	rabbitConsumer rabbit.Consumer
+	log       *slog.Logger
+	tracer    trace.Tracer
+	metrics   *metrics
+	consumer  broker.Consumer
+	templates Renderer
+	handlers  map[string]handler
+	// Подписывает действия, доступные из напоминаний, ссылки на них ведут на actionURL
+	actions   action.Service
+	actionURL string
+	// Клиент Bot API, через который доставляются уведомления; nil, если уведомления только записываются в лог
+	telegramClient *telegram.Client
+	// Количество сообщений, обрабатываемых одновременно
+	workers         int
+	shutdownTimeout time.Duration
+	// Ключи идемпотентности отправленных уведомлений, общие для всех экземпляров сервиса
+	dedup          dedup.Repository
+	dedupLease     time.Duration
+	dedupRetention time.Duration
 }
 
-func NewSenderService(log *slog.Logger, rabbitConsumer rabbit.Consumer) *Service {
-	return &Service{
-		log:            log,
-		rabbitConsumer: rabbitConsumer,
+func NewSenderService(log *slog.Logger, tracer trace.Tracer, meter metric.Meter, consumer broker.Consumer, templates Renderer, actions action.Service, actionURL string, telegramClient *telegram.Client, workers int, shutdownTimeout time.Duration, dedup dedup.Repository, dedupLease time.Duration, dedupRetention time.Duration) *Service {
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("sender")
 	}
+
+	m, err := newMetrics(meter)
+	if err != nil {
+		log.Error("failed to create sender metrics, falling back to noop meter", sl.Err(err))
+		m, _ = newMetrics(noop.NewMeterProvider().Meter("sender")) //nolint:errcheck
+	}
+
+	s := &Service{
+		log:             log,
+		tracer:          tracer,
+		metrics:         m,
+		consumer:        consumer,
+		templates:       templates,
+		actions:         actions,
+		actionURL:       actionURL,
+		telegramClient:  telegramClient,
+		workers:         max(workers, 1),
+		shutdownTimeout: shutdownTimeout,
+		dedup:           dedup,
+		dedupLease:      dedupLease,
+		dedupRetention:  dedupRetention,
+	}
+
+	s.handlers = map[string]handler{
+		message.TypeBookingReminder: s.handleBookingReminder,
+		message.TypeBookingEnding:   s.handleBookingReminder,
+		message.TypeBookingDigest:   s.handleBookingDigest,
+	}
+
+	return s
 }
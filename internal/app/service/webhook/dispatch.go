@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/logger/sl"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/guregu/null.v3"
+)
+
+// Run posts pending deliveries to their webhooks until the context is cancelled.
+func (s *Service) Run(ctx context.Context) {
+	const op = "service.webhook.Run"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	log.Info("webhook dispatcher initiated")
+
+	ticker := time.NewTicker(s.pollPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// очередь разбирается до конца, не дожидаясь следующего опроса
+			for n := s.dispatch(ctx); n != 0 && n == s.batchSize; n = s.dispatch(ctx) {
+			}
+		}
+	}
+}
+
+// dispatch claims a batch of due deliveries, posts them concurrently and returns the size of the batch.
+func (s *Service) dispatch(ctx context.Context) uint64 {
+	const op = "service.webhook.dispatch"
+
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	ctx, span := s.tracer.Start(ctx, op)
+	defer span.End()
+
+	// доставка, не завершенная за время аренды, например из-за остановки сервиса, будет отправлена повторно
+	deliveries, err := s.webhookRepository.ClaimDeliveries(ctx, time.Now(), 2*s.client.Timeout, s.batchSize)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to claim deliveries", sl.Err(err))
+		return 0
+	}
+
+	if len(deliveries) == 0 {
+		return 0
+	}
+
+	span.AddEvent("deliveries claimed", trace.WithAttributes(attribute.Int("quantity", len(deliveries))))
+
+	wg := &sync.WaitGroup{}
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery *model.PendingDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return uint64(len(deliveries))
+}
+
+// deliver makes an attempt to post the delivery and records its outcome. Failed deliveries are retried
+// with exponential backoff until they run out of attempts.
+func (s *Service) deliver(ctx context.Context, delivery *model.PendingDelivery) {
+	const op = "service.webhook.deliver"
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.Int64("delivery_id", delivery.ID),
+		slog.Int64("webhook_id", delivery.WebhookID),
+	)
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(
+		attribute.Int64("delivery_id", delivery.ID),
+		attribute.Int("attempt", delivery.Attempts),
+	))
+	defer span.End()
+
+	now := time.Now()
+	res := &model.WebhookDelivery{
+		ID:            delivery.ID,
+		Status:        model.DeliveryDelivered,
+		NextAttemptAt: now,
+	}
+
+	status, err := s.post(ctx, delivery, now)
+	if status != 0 {
+		res.ResponseStatus = null.IntFrom(int64(status))
+	}
+
+	if err == nil {
+		res.DeliveredAt = null.TimeFrom(now)
+		span.AddEvent("delivered", trace.WithAttributes(attribute.Int("status", status)))
+		log.Debug("delivered", slog.Int("status", status))
+	} else {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		res.Error = null.StringFrom(truncate(err.Error(), errorLimit))
+
+		if delivery.Attempts >= s.maxAttempts {
+			res.Status = model.DeliveryFailed
+			log.Error("delivery failed, no attempts left", sl.Err(err), slog.Int("attempts", delivery.Attempts))
+		} else {
+			res.Status = model.DeliveryPending
+			res.NextAttemptAt = now.Add(s.backoff(delivery.Attempts))
+			log.Warn("delivery attempt failed", sl.Err(err), slog.Int("attempts", delivery.Attempts), slog.Time("next_attempt_at", res.NextAttemptAt))
+		}
+	}
+
+	err = s.webhookRepository.CompleteDelivery(ctx, res)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to record delivery outcome", sl.Err(err))
+	}
+}
+
+// post sends the signed payload to the webhook and returns the response status. Any status but 2xx is an error.
+func (s *Service) post(ctx context.Context, delivery *model.PendingDelivery, now time.Time) (int, error) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// тело ответа не сохраняется: через него в журнал доставок могли бы попасть данные получателя
+	io.Copy(io.Discard, io.LimitReader(resp.Body, drainLimit)) //nolint:errcheck
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// truncate cuts msg to at most limit bytes on a rune boundary, so that the text column gets valid UTF-8.
+func truncate(msg string, limit int) string {
+	if len(msg) <= limit {
+		return msg
+	}
+
+	for limit > 0 && !utf8.RuneStart(msg[limit]) {
+		limit--
+	}
+
+	return msg[:limit]
+}
+
+// backoff returns the delay before the attempt following the given one.
+func (s *Service) backoff(attempts int) time.Duration {
+	delay := s.retryDelay
+	for i := 1; i < attempts && delay < s.maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, s.maxRetryDelay)
+}
+
+// Sign returns the value of the signature header for the body sent at timestamp. Receivers are expected
+// to recompute it with the secret of their webhook and to reject stale timestamps.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,30 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateKeepsRunes(t *testing.T) {
+	// префикс сдвигает двухбайтовые буквы так, что граница лимита приходится на середину руны
+	msg := "response: " + strings.Repeat("ошибка получателя ", 50)
+	if utf8.RuneStart(msg[errorLimit]) {
+		t.Fatal("the limit falls on a rune boundary, the test checks nothing")
+	}
+
+	got := truncate(msg, errorLimit)
+	if !utf8.ValidString(got) {
+		t.Fatalf("truncated error is not valid UTF-8: %q", got)
+	}
+	if len(got) > errorLimit {
+		t.Fatalf("truncated error is %d bytes long, want at most %d", len(got), errorLimit)
+	}
+	if len(got) < errorLimit-utf8.UTFMax || !strings.HasPrefix(msg, got) {
+		t.Fatalf("truncated error %q is not the longest prefix of the original", got)
+	}
+
+	if short := "ошибка"; truncate(short, errorLimit) != short {
+		t.Errorf("error shorter than the limit is changed")
+	}
+}
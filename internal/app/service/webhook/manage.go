@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/model"
+	"booking-schedule/internal/app/repository/webhook"
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"slices"
+
+	"github.com/go-chi/chi/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// AddWebhook registers the webhook of the user. A signing secret is generated unless one is given,
+// subscribing to events of all bookings is reserved for admins.
+func (s *Service) AddWebhook(ctx context.Context, mod *model.Webhook) (*model.Webhook, error) {
+	const op = "service.webhook.AddWebhook"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	for _, event := range mod.Events {
+		if !slices.Contains(model.WebhookEvents, event) {
+			span.RecordError(ErrUnknownEvent)
+			span.SetStatus(codes.Error, ErrUnknownEvent.Error())
+			log.Error("invalid request", sl.Err(ErrUnknownEvent), slog.String("event", event))
+			return nil, ErrUnknownEvent
+		}
+	}
+
+	if mod.AllBookings {
+		user, err := s.userRepository.GetUser(ctx, mod.UserID)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to get user", sl.Err(err))
+			return nil, err
+		}
+
+		if !user.IsAdmin {
+			span.RecordError(ErrNotAdmin)
+			span.SetStatus(codes.Error, ErrNotAdmin.Error())
+			log.Error("invalid request", sl.Err(ErrNotAdmin))
+			return nil, ErrNotAdmin
+		}
+	}
+
+	if mod.Secret == "" {
+		secret := make([]byte, 32)
+		_, err := rand.Read(secret)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			log.Error("failed to generate secret", sl.Err(err))
+			return nil, err
+		}
+		mod.Secret = hex.EncodeToString(secret)
+	}
+
+	id, err := s.webhookRepository.AddWebhook(ctx, mod)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to add webhook", sl.Err(err))
+		return nil, err
+	}
+
+	span.AddEvent("webhook added", trace.WithAttributes(attribute.Int64("id", id)))
+
+	mod.ID = id
+
+	return mod, nil
+}
+
+func (s *Service) GetWebhooks(ctx context.Context, userID int64) ([]*model.Webhook, error) {
+	return s.webhookRepository.GetWebhooks(ctx, userID)
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, webhookID int64, userID int64) error {
+	return s.webhookRepository.DeleteWebhook(ctx, webhookID, userID)
+}
+
+// GetDeliveries returns the latest deliveries of the webhook owned by the user, newest first.
+func (s *Service) GetDeliveries(ctx context.Context, webhookID int64, userID int64) ([]*model.WebhookDelivery, error) {
+	const op = "service.webhook.GetDeliveries"
+
+	requestID := middleware.GetReqID(ctx)
+
+	log := s.log.With(
+		slog.String("op", op),
+		slog.String("request_id", requestID),
+	)
+
+	ctx, span := s.tracer.Start(ctx, op, trace.WithAttributes(attribute.String("request_id", requestID)))
+	defer span.End()
+
+	webhooks, err := s.webhookRepository.GetWebhooks(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("failed to get webhooks", sl.Err(err))
+		return nil, err
+	}
+
+	// чужие и несуществующие вебхуки неотличимы для пользователя
+	if !slices.ContainsFunc(webhooks, func(w *model.Webhook) bool { return w.ID == webhookID }) {
+		span.RecordError(webhook.ErrNotFound)
+		span.SetStatus(codes.Error, webhook.ErrNotFound.Error())
+		log.Error("webhook not found", sl.Err(webhook.ErrNotFound))
+		return nil, webhook.ErrNotFound
+	}
+
+	return s.webhookRepository.GetDeliveries(ctx, webhookID, userID, deliveriesLimit)
+}
+
+// ReplayDelivery enqueues the delivery of the webhook owned by the user once again and returns the id
+// of the new delivery.
+func (s *Service) ReplayDelivery(ctx context.Context, webhookID int64, deliveryID int64, userID int64) (int64, error) {
+	return s.webhookRepository.ReplayDelivery(ctx, webhookID, deliveryID, userID)
+}
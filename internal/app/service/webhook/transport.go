@@ -0,0 +1,80 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+var ErrForbiddenAddress = errors.New("webhook resolves to a loopback, private or otherwise non-public address")
+
+// Диапазоны, не покрытые методами netip.Addr: общее адресное пространство провайдеров (RFC 6598),
+// "этот" сеть (RFC 1122), служебные адреса IETF, тестовые сети и адреса для тестов производительности
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// IsPublicAddr reports whether webhooks may be posted to the address: loopback, private, link-local,
+// multicast, unspecified and reserved addresses are refused, so that webhooks cannot reach internal services.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newClient creates the client webhooks are posted with. Addresses are checked when the connection is dialed,
+// after the host is resolved, so the check also covers redirects and hosts that resolve to another address
+// than they did when the webhook was registered. Proxies from the environment are not used, since they
+// would dial the webhook on the client's behalf.
+func newClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: checkAddress,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// checkAddress is called with the resolved address right before connecting to it.
+func checkAddress(_ string, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, address)
+	}
+
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+
+	return nil
+}
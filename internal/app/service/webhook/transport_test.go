@@ -0,0 +1,58 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"93.184.216.34":        true,
+		"2606:2800:220:1::1":   true,
+		"127.0.0.1":            false,
+		"10.1.2.3":             false,
+		"172.16.0.1":           false,
+		"192.168.1.1":          false,
+		"169.254.169.254":      false,
+		"100.64.0.1":           false,
+		"0.0.0.0":              false,
+		"::1":                  false,
+		"fe80::1":              false,
+		"fd00::1":              false,
+		"::ffff:127.0.0.1":     false,
+		"::ffff:169.254.0.1":   false,
+		"224.0.0.1":            false,
+		"255.255.255.255":      false,
+		"64:ff9b::7f00:1":      false,
+		"::ffff:93.184.216.34": true,
+	} {
+		if got := IsPublicAddr(netip.MustParseAddr(addr)); got != want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
+
+func TestClientRefusesLoopback(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := newClient(time.Second)
+
+	// имя хоста разрешается в адрес обратной петли так же, как при подмене DNS
+	url := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+	resp, err := client.Post(url, "application/json", strings.NewReader("{}"))
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("request to a loopback address succeeded")
+	}
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("request failed with %v, want %v", err, ErrForbiddenAddress)
+	}
+}
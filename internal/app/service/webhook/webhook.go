@@ -0,0 +1,61 @@
+package webhook
+
+import (
+	"booking-schedule/internal/app/repository/user"
+	"booking-schedule/internal/app/repository/webhook"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature carries "sha256=" followed by the hex HMAC-SHA256 of the timestamp, a dot and the body,
+	// keyed with the secret of the webhook.
+	HeaderSignature = "X-Webhook-Signature"
+
+	// deliveriesLimit is the number of deliveries returned from the log of a webhook.
+	deliveriesLimit = 100
+	// errorLimit is the length the error of a failed attempt is truncated to in the log.
+	errorLimit = 512
+	// drainLimit is how much of a response body is read and discarded, so that the connection can be reused.
+	drainLimit = 4096
+)
+
+type Service struct {
+	webhookRepository webhook.Repository
+	userRepository    user.Repository
+	log               *slog.Logger
+	tracer            trace.Tracer
+	client            *http.Client
+	pollPeriod        time.Duration
+	batchSize         uint64
+	maxAttempts       int
+	retryDelay        time.Duration
+	maxRetryDelay     time.Duration
+}
+
+var (
+	ErrUnknownEvent = errors.New("unknown webhook event")
+	ErrNotAdmin     = errors.New("only admins may subscribe to events of all bookings")
+)
+
+func NewWebhookService(webhookRepository webhook.Repository, userRepository user.Repository, log *slog.Logger, tracer trace.Tracer, pollPeriod time.Duration, timeout time.Duration, batchSize uint64, maxAttempts int, retryDelay time.Duration, maxRetryDelay time.Duration) *Service {
+	return &Service{
+		webhookRepository: webhookRepository,
+		userRepository:    userRepository,
+		log:               log,
+		tracer:            tracer,
+		client:            newClient(timeout),
+		pollPeriod:        pollPeriod,
+		batchSize:         batchSize,
+		maxAttempts:       maxAttempts,
+		retryDelay:        retryDelay,
+		maxRetryDelay:     maxRetryDelay,
+	}
+}
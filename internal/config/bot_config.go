@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/exaring/otelpgx"
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Telegram struct {
+	Token string `yaml:"token" env:"TELEGRAM_TOKEN" env-required:"true"`
+	// Адрес Bot API, для локальной проверки можно указать адрес поддельного сервера
+	APIURL      string        `yaml:"api_url" env:"TELEGRAM_API_URL" env-default:"https://api.telegram.org"`
+	Timeout     time.Duration `yaml:"timeout" env:"TELEGRAM_TIMEOUT" env-default:"10s"`
+	PollTimeout time.Duration `yaml:"poll_timeout" env:"TELEGRAM_POLL_TIMEOUT" env-default:"30s"`
+	// Время заезда и выезда в формате 15:04 в часовом поясе пользователя для бронирований через бота
+	CheckIn  string `yaml:"check_in" env:"BOT_CHECK_IN" env-default:"14:00"`
+	CheckOut string `yaml:"check_out" env:"BOT_CHECK_OUT" env-default:"12:00"`
+}
+
+type BotMetrics struct {
+	Host string `yaml:"host" env:"BOT_METRICS_HOST" env-default:"0.0.0.0"`
+	Port string `yaml:"port" env:"BOT_METRICS_PORT" env-default:"2113"`
+}
+
+type BotConfig struct {
+	Env       string     `yaml:"env" env:"env" env-default:"dev"`
+	Telegram  Telegram   `yaml:"telegram"`
+	Database  Database   `yaml:"database"`
+	Tracer    Tracer     `yaml:"tracer"`
+	Reminders Reminders  `yaml:"reminders"`
+	Metrics   BotMetrics `yaml:"metrics"`
+}
+
+func ReadBotConfigFile(path string) (*BotConfig, error) {
+	config := &BotConfig{}
+
+	err := cleanenv.ReadConfig(path, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+func ReadBotConfigEnv() (*BotConfig, error) {
+	config := &BotConfig{}
+
+	err := cleanenv.ReadEnv(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// GetTelegramConfig ...
+func (b *BotConfig) GetTelegramConfig() *Telegram {
+	return &b.Telegram
+}
+
+// GetTracerConfig
+func (b *BotConfig) GetTracerConfig() *Tracer {
+	return &b.Tracer
+}
+
+// GetRemindersConfig
+func (b *BotConfig) GetRemindersConfig() *Reminders {
+	return &b.Reminders
+}
+
+// GetMetricsAddress ...
+func (b *BotConfig) GetMetricsAddress() string {
+	return b.Metrics.Host + ":" + b.Metrics.Port
+}
+
+// GetEnv ...
+func (b *BotConfig) GetEnv() string {
+	return b.Env
+}
+
+func (b *BotConfig) GetDBConfig() (*pgxpool.Config, error) {
+	dbDsn := fmt.Sprintf("user=%s dbname=%s password=%s host=%s port=%s sslmode=%s", b.Database.User, b.Database.Name, b.Database.Password, b.Database.Host, b.Database.Port, b.Database.Ssl)
+
+	poolConfig, err := pgxpool.ParseConfig(dbDsn)
+	if err != nil {
+		return nil, err
+	}
+
+	poolConfig.ConnConfig.Tracer = otelpgx.NewTracer(otelpgx.WithTrimSQLInSpanName())
+	poolConfig.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	poolConfig.MaxConns = b.Database.MaxOpenedConnections
+
+	return poolConfig, nil
+}
@@ -62,7 +62,12 @@ func (s *serviceProvider) GetDB(ctx context.Context) db.Client {
 		if err != nil {
 			s.log.Error("could not get db config: %s", sl.Err(err))
 		}
-		dbc, err := db.NewClient(ctx, cfg)
+		replicas, err := s.GetConfig().Database.GetReplicaConfigs()
+		if err != nil {
+			s.GetLogger().Error("could not get db replica configs", sl.Err(err))
+		}
+		dbCfg := s.GetConfig().Database
+		dbc, err := db.NewClient(ctx, cfg, replicas, dbCfg.ReplicaMaxLag, dbCfg.ReplicaCheckPeriod, dbCfg.SlowQueryThreshold, s.GetLogger(), s.GetMeter(ctx))
 		if err != nil {
 			s.log.Error("coud not connect to db: %s", sl.Err(err))
 		}
@@ -168,7 +173,8 @@ func (s *serviceProvider) GetLogger() *slog.Logger {
 
 func (s *serviceProvider) TxManager(ctx context.Context) db.TxManager {
 	if s.txManager == nil {
-		s.txManager = transaction.NewTransactionManager(s.GetDB(ctx).DB())
+		cfg := s.GetConfig().Database
+		s.txManager = transaction.NewTransactionManager(s.GetDB(ctx).DB(), s.GetLogger(), s.GetMeter(ctx), cfg.TxMaxAttempts, cfg.TxRetryDelay, cfg.TxMaxRetryDelay)
 	}
 
 	return s.txManager
@@ -176,7 +182,7 @@ func (s *serviceProvider) TxManager(ctx context.Context) db.TxManager {
 
 func (s *serviceProvider) GetTracer(ctx context.Context) trace.Tracer {
 	if s.tracer == nil {
-		tracer, err := observability.NewTracer(ctx, s.GetConfig().GetTracerConfig().EndpointURL, "auth", s.GetConfig().GetTracerConfig().SamplingRate)
+		tracer, err := observability.NewTracer(ctx, s.GetConfig().GetTracerConfig().EndpointURL, "auth", s.GetConfig().GetTracerConfig().SamplingRate, s.GetConfig().GetTracerConfig().Propagator)
 		if err != nil {
 			s.GetLogger().Error("failed to create tracer: ", sl.Err(err))
 			return nil
@@ -78,9 +78,24 @@ func (a *App) initServiceProvider(_ context.Context) error {
 // Run ...
 func (a *App) Run() error {
 	defer func() {
-		a.serviceProvider.db.Close() //nolint:errcheck
+		a.serviceProvider.db.Close()       //nolint:errcheck
+		a.serviceProvider.producer.Close() //nolint:errcheck
 	}()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if a.serviceProvider.GetConfig().GetMigrationsConfig().OnStart {
+		err := a.migrate(ctx)
+		if err != nil {
+			a.serviceProvider.GetLogger().Error("failed to apply migrations", sl.Err(err))
+			return err
+		}
+	}
+
+	go a.serviceProvider.GetWebhookService(ctx).Run(ctx)
+	go a.serviceProvider.GetOutboxService(ctx).Run(ctx)
+
 	err := a.startServer()
 	if err != nil {
 		a.serviceProvider.GetLogger().Error("failed to start server: %s", err)
@@ -167,6 +182,7 @@ func (a *App) startServer() error {
 func (a *App) initServer(ctx context.Context) error {
 	bookingImpl := a.serviceProvider.GetBookingImpl(ctx)
 	userImpl := a.serviceProvider.GetUserImpl(ctx)
+	webhookImpl := a.serviceProvider.GetWebhookImpl(ctx)
 
 	address, err := a.serviceProvider.GetConfig().GetAddress()
 	if err != nil {
@@ -194,7 +210,7 @@ func (a *App) initServer(ctx context.Context) error {
 		r.Use(mwLogger.New(a.serviceProvider.GetLogger()))
 		r.Use(cors.Handler(cors.Options{
 			AllowedOrigins:   []string{"https://*", "http://*"},
-			AllowedMethods:   []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
+			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 			AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"}, //"X-CSRF-Token" for tokens stored in cookies
 			ExposedHeaders:   []string{"Link"},
 			AllowCredentials: false,
@@ -209,15 +225,28 @@ func (a *App) initServer(ctx context.Context) error {
 					r.Get("/me", userImpl.GetMyProfile(a.serviceProvider.GetLogger()))
 					r.Delete("/delete", userImpl.DeleteMyProfile(a.serviceProvider.GetLogger()))
 					r.Patch("/edit", userImpl.EditMyProfile(a.serviceProvider.GetLogger()))
+					r.Get("/preferences", userImpl.GetMyPreferences(a.serviceProvider.GetLogger()))
+					r.Put("/preferences", userImpl.SetMyPreferences(a.serviceProvider.GetLogger()))
 				})
 
 			})
 			r.Get("/get-vacant-rooms", bookingImpl.GetVacantRooms(a.serviceProvider.GetLogger()))
 			r.Get("/{suite_id}/get-vacant-dates", bookingImpl.GetVacantDates(a.serviceProvider.GetLogger()))
+			r.Get("/actions/{token}", bookingImpl.ShowBookingAction(a.serviceProvider.GetLogger()))
+			r.Post("/actions/{token}", bookingImpl.HandleBookingAction(a.serviceProvider.GetLogger()))
 			r.Group(func(r chi.Router) {
 				r.Use(auth.Auth(a.serviceProvider.GetLogger(), a.serviceProvider.GetJWTService(ctx)))
 				r.Post("/add", bookingImpl.AddBooking(a.serviceProvider.GetLogger()))
 				r.Get("/get-bookings", bookingImpl.GetBookings(a.serviceProvider.GetLogger()))
+				r.Route("/webhooks", func(r chi.Router) {
+					r.Post("/", webhookImpl.AddWebhook(a.serviceProvider.GetLogger()))
+					r.Get("/", webhookImpl.GetWebhooks(a.serviceProvider.GetLogger()))
+					r.Route("/{webhook_id}", func(r chi.Router) {
+						r.Delete("/", webhookImpl.DeleteWebhook(a.serviceProvider.GetLogger()))
+						r.Get("/deliveries", webhookImpl.GetDeliveries(a.serviceProvider.GetLogger()))
+						r.Post("/deliveries/{delivery_id}/replay", webhookImpl.ReplayDelivery(a.serviceProvider.GetLogger()))
+					})
+				})
 				r.Route("/{booking_id}", func(r chi.Router) {
 					r.Get("/get", bookingImpl.GetBooking(a.serviceProvider.GetLogger()))
 					r.Patch("/update", bookingImpl.UpdateBooking(a.serviceProvider.GetLogger()))
@@ -0,0 +1,41 @@
+package bookings
+
+import (
+	"booking-schedule/internal/pkg/migrator"
+	"context"
+	"io"
+	"log/slog"
+)
+
+// RunMigrateCommand runs the migrate subcommand (up, down, status or create) against the database
+// from the config without setting the service up.
+func RunMigrateCommand(ctx context.Context, out io.Writer, configType string, pathConfig string, dir string, command string, args []string) error {
+	cfg, err := newServiceProvider(configType, pathConfig, nil).GetConfig().GetDBConfig()
+	if err != nil {
+		return err
+	}
+
+	return migrator.RunCommand(ctx, out, cfg, dir, command, args)
+}
+
+// migrate applies the pending migrations before the service starts. Instances started at once wait
+// for the advisory lock, so only the first one applies them.
+func (a *App) migrate(ctx context.Context) error {
+	cfg, err := a.serviceProvider.GetConfig().GetDBConfig()
+	if err != nil {
+		return err
+	}
+
+	m, err := migrator.NewMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Close() //nolint:errcheck
+
+	results, err := m.Up(ctx)
+	for _, res := range results {
+		a.serviceProvider.GetLogger().Info("migration applied", slog.String("migration", res.String()))
+	}
+
+	return err
+}
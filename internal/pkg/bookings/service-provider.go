@@ -3,6 +3,7 @@ package bookings
 import (
 	"context"
 	"crypto/tls"
+	"fmt"
 	"log"
 	"log/slog"
 	"net/http"
@@ -10,16 +11,28 @@ import (
 
 	"booking-schedule/internal/app/api/booking"
 	"booking-schedule/internal/app/api/user"
+	"booking-schedule/internal/app/api/webhook"
 	bookingRepository "booking-schedule/internal/app/repository/booking"
+	outboxRepository "booking-schedule/internal/app/repository/outbox"
+	preferencesRepository "booking-schedule/internal/app/repository/preferences"
 	userRepository "booking-schedule/internal/app/repository/user"
+	webhookRepository "booking-schedule/internal/app/repository/webhook"
+	"booking-schedule/internal/app/service/action"
 	bookingService "booking-schedule/internal/app/service/booking"
 	"booking-schedule/internal/app/service/jwt"
+	outboxService "booking-schedule/internal/app/service/outbox"
+	preferencesService "booking-schedule/internal/app/service/preferences"
 	userService "booking-schedule/internal/app/service/user"
+	webhookService "booking-schedule/internal/app/service/webhook"
 	"booking-schedule/internal/config"
 	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
+	"booking-schedule/internal/pkg/broker/memory"
+	"booking-schedule/internal/pkg/broker/nats"
 	"booking-schedule/internal/pkg/db"
 	"booking-schedule/internal/pkg/db/transaction"
 	"booking-schedule/internal/pkg/observability"
+	"booking-schedule/internal/pkg/rabbit"
 
 	"go.opentelemetry.io/otel/metric"
 
@@ -51,10 +64,22 @@ type serviceProvider struct {
 	userRepository userRepository.Repository
 	userService    *userService.Service
 
-	jwtService jwt.Service
+	preferencesRepository preferencesRepository.Repository
+	preferencesService    *preferencesService.Service
+
+	webhookRepository webhookRepository.Repository
+	webhookService    *webhookService.Service
+
+	outboxRepository outboxRepository.Repository
+	outboxService    *outboxService.Service
+	producer         broker.Producer
+
+	jwtService    jwt.Service
+	actionService action.Service
 
 	bookingImpl *booking.Implementation
 	userImpl    *user.Implementation
+	webhookImpl *webhook.Implementation
 }
 
 func newServiceProvider(configType string, configPath string, meter metric.Meter) *serviceProvider {
@@ -71,7 +96,12 @@ func (s *serviceProvider) GetDB(ctx context.Context) db.Client {
 		if err != nil {
 			s.log.Error("could not get db config: %s", sl.Err(err))
 		}
-		dbc, err := db.NewClient(ctx, cfg)
+		replicas, err := s.GetConfig().Database.GetReplicaConfigs()
+		if err != nil {
+			s.GetLogger().Error("could not get db replica configs", sl.Err(err))
+		}
+		dbCfg := s.GetConfig().Database
+		dbc, err := db.NewClient(ctx, cfg, replicas, dbCfg.ReplicaMaxLag, dbCfg.ReplicaCheckPeriod, dbCfg.SlowQueryThreshold, s.GetLogger(), s.GetMeter(ctx))
 		if err != nil {
 			s.log.Error("coud not connect to db: %s", sl.Err(err))
 		}
@@ -119,10 +149,34 @@ func (s *serviceProvider) GetUserRepository(ctx context.Context) userRepository.
 	return s.userRepository
 }
 
+func (s *serviceProvider) GetPreferencesRepository(ctx context.Context) preferencesRepository.Repository {
+	if s.preferencesRepository == nil {
+		s.preferencesRepository = preferencesRepository.NewPreferencesRepository(s.GetDB(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.preferencesRepository
+}
+
+func (s *serviceProvider) GetWebhookRepository(ctx context.Context) webhookRepository.Repository {
+	if s.webhookRepository == nil {
+		s.webhookRepository = webhookRepository.NewWebhookRepository(s.GetDB(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.webhookRepository
+}
+
+func (s *serviceProvider) GetOutboxRepository(ctx context.Context) outboxRepository.Repository {
+	if s.outboxRepository == nil {
+		s.outboxRepository = outboxRepository.NewOutboxRepository(s.GetDB(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.outboxRepository
+}
+
 func (s *serviceProvider) GetBookingService(ctx context.Context) *bookingService.Service {
 	if s.bookingService == nil {
 		bookingRepository := s.GetBookingRepository(ctx)
-		s.bookingService = bookingService.NewBookingService(bookingRepository, s.GetJWTService(ctx), s.GetLogger(), s.TxManager(ctx), s.GetTracer(ctx))
+		s.bookingService = bookingService.NewBookingService(bookingRepository, s.GetWebhookRepository(ctx), s.GetOutboxRepository(ctx), s.GetJWTService(ctx), s.GetLogger(), s.TxManager(ctx), s.GetTracer(ctx), s.GetConfig().GetRemindersConfig().MaxPerBooking)
 	}
 
 	return s.bookingService
@@ -137,6 +191,66 @@ func (s *serviceProvider) GetUserService(ctx context.Context) *userService.Servi
 	return s.userService
 }
 
+func (s *serviceProvider) GetPreferencesService(ctx context.Context) *preferencesService.Service {
+	if s.preferencesService == nil {
+		s.preferencesService = preferencesService.NewPreferencesService(s.GetPreferencesRepository(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.preferencesService
+}
+
+func (s *serviceProvider) GetWebhookService(ctx context.Context) *webhookService.Service {
+	if s.webhookService == nil {
+		cfg := s.GetConfig().GetWebhooksConfig()
+		s.webhookService = webhookService.NewWebhookService(s.GetWebhookRepository(ctx), s.GetUserRepository(ctx), s.GetLogger(), s.GetTracer(ctx),
+			cfg.PollPeriod, cfg.Timeout, cfg.BatchSize, cfg.MaxAttempts, cfg.RetryDelay, cfg.MaxRetryDelay)
+	}
+
+	return s.webhookService
+}
+
+func (s *serviceProvider) GetOutboxService(ctx context.Context) *outboxService.Service {
+	if s.outboxService == nil {
+		cfg := s.GetConfig().GetOutboxConfig()
+		s.outboxService = outboxService.NewOutboxService(s.GetOutboxRepository(ctx), s.GetProducer(), s.GetLogger(), s.GetTracer(ctx),
+			cfg.PollPeriod, cfg.BatchSize, cfg.Lease)
+	}
+
+	return s.outboxService
+}
+
+// GetProducer connects to the message broker selected in the config, booking events are published by their routing keys.
+func (s *serviceProvider) GetProducer() broker.Producer {
+	if s.producer == nil {
+		var (
+			p   broker.Producer
+			err error
+		)
+
+		switch kind := s.GetConfig().GetBrokerConfig().Kind; kind {
+		case broker.KindRabbitMQ:
+			p, err = rabbit.NewTopicProducer(s.GetConfig().GetRabbitPublisherConfig(), s.GetLogger())
+		case broker.KindNats:
+			p, err = nats.NewTopicProducer(s.GetConfig().GetNatsPublisherConfig(), s.GetLogger())
+		case broker.KindMemory:
+			var b *memory.Broker
+			b, err = memory.Default()
+			if err == nil {
+				p = b.NewTopicProducer(s.GetConfig().GetMemoryPublisherConfig().Exchange)
+			}
+		default:
+			err = fmt.Errorf("%w: %q", broker.ErrUnknownKind, kind)
+		}
+		if err != nil {
+			s.GetLogger().Error("could not connect to message broker", sl.Err(err))
+			os.Exit(1)
+		}
+		s.producer = p
+	}
+
+	return s.producer
+}
+
 func (s *serviceProvider) GetJWTService(ctx context.Context) jwt.Service {
 	if s.jwtService == nil {
 		s.jwtService = jwt.NewJWTService(s.GetConfig().GetJWTConfig().Secret, s.GetConfig().GetJWTConfig().Expiration, s.GetLogger(), s.GetTracer(ctx))
@@ -145,9 +259,17 @@ func (s *serviceProvider) GetJWTService(ctx context.Context) jwt.Service {
 	return s.jwtService
 }
 
+func (s *serviceProvider) GetActionService(ctx context.Context) action.Service {
+	if s.actionService == nil {
+		s.actionService = action.NewActionService(s.GetConfig().GetActionsConfig().Secret, s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.actionService
+}
+
 func (s *serviceProvider) GetBookingImpl(ctx context.Context) *booking.Implementation {
 	if s.bookingImpl == nil {
-		s.bookingImpl = booking.NewImplementation(s.GetBookingService(ctx), s.GetTracer(ctx))
+		s.bookingImpl = booking.NewImplementation(s.GetBookingService(ctx), s.GetActionService(ctx), s.GetTracer(ctx))
 	}
 
 	return s.bookingImpl
@@ -155,12 +277,20 @@ func (s *serviceProvider) GetBookingImpl(ctx context.Context) *booking.Implement
 
 func (s *serviceProvider) GetUserImpl(ctx context.Context) *user.Implementation {
 	if s.userImpl == nil {
-		s.userImpl = user.NewImplementation(s.GetUserService(ctx), s.GetTracer(ctx))
+		s.userImpl = user.NewImplementation(s.GetUserService(ctx), s.GetPreferencesService(ctx), s.GetTracer(ctx))
 	}
 
 	return s.userImpl
 }
 
+func (s *serviceProvider) GetWebhookImpl(ctx context.Context) *webhook.Implementation {
+	if s.webhookImpl == nil {
+		s.webhookImpl = webhook.NewImplementation(s.GetWebhookService(ctx), s.GetTracer(ctx))
+	}
+
+	return s.webhookImpl
+}
+
 func (s *serviceProvider) getServer(router http.Handler) *http.Server {
 	if s.server == nil {
 		address, err := s.GetConfig().GetAddress()
@@ -204,7 +334,8 @@ func (s *serviceProvider) GetLogger() *slog.Logger {
 
 func (s *serviceProvider) TxManager(ctx context.Context) db.TxManager {
 	if s.txManager == nil {
-		s.txManager = transaction.NewTransactionManager(s.GetDB(ctx).DB())
+		cfg := s.GetConfig().Database
+		s.txManager = transaction.NewTransactionManager(s.GetDB(ctx).DB(), s.GetLogger(), s.GetMeter(ctx), cfg.TxMaxAttempts, cfg.TxRetryDelay, cfg.TxMaxRetryDelay)
 	}
 
 	return s.txManager
@@ -212,7 +343,7 @@ func (s *serviceProvider) TxManager(ctx context.Context) db.TxManager {
 
 func (s *serviceProvider) GetTracer(ctx context.Context) trace.Tracer {
 	if s.tracer == nil {
-		tracer, err := observability.NewTracer(ctx, s.GetConfig().GetTracerConfig().EndpointURL, "bookings", s.GetConfig().GetTracerConfig().SamplingRate)
+		tracer, err := observability.NewTracer(ctx, s.GetConfig().GetTracerConfig().EndpointURL, "bookings", s.GetConfig().GetTracerConfig().SamplingRate, s.GetConfig().GetTracerConfig().Propagator)
 		if err != nil {
 			s.GetLogger().Error("failed to create tracer: ", sl.Err(err))
 			return nil
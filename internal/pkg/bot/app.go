@@ -0,0 +1,129 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/observability"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type App struct {
+	configType string
+	pathConfig string
+
+	serviceProvider *serviceProvider
+}
+
+// NewApp ...
+func NewApp(ctx context.Context, configType string, pathConfig string) (*App, error) {
+	a := &App{
+		configType: configType,
+		pathConfig: pathConfig,
+	}
+	err := a.initDeps(ctx)
+
+	return a, err
+}
+
+func (a *App) initDeps(ctx context.Context) error {
+
+	inits := []func(context.Context) error{
+		a.initServiceProvider,
+	}
+
+	for _, f := range inits {
+		err := f(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *App) initServiceProvider(_ context.Context) error {
+	a.serviceProvider = newServiceProvider(a.configType, a.pathConfig)
+
+	return nil
+}
+
+// Run ...
+func (a *App) Run(ctx context.Context) error {
+	defer a.serviceProvider.Close()
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	metricsServer := a.runMetricsServer(ctx)
+	defer func() {
+		// TODO: move timeout to config
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		metricsServer.Shutdown(ctx) //nolint:errcheck
+	}()
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	err := a.runBotService(ctx, wg)
+	if err != nil {
+		return err
+	}
+	wg.Wait()
+	a.serviceProvider.GetLogger().Info("bot service stopped")
+
+	return nil
+}
+
+func (a *App) runBotService(ctx context.Context, wg *sync.WaitGroup) error {
+	bot, err := a.serviceProvider.GetBotService(ctx)
+	if err != nil {
+		wg.Done()
+		return err
+	}
+
+	go func() {
+		defer wg.Done()
+
+		a.serviceProvider.GetLogger().Info("attempting to run bot service")
+		bot.Run(ctx)
+	}()
+
+	return nil
+}
+
+// runMetricsServer exposes the query, pool and transaction metrics of the bot for prometheus,
+// the bot has no HTTP server of its own.
+func (a *App) runMetricsServer(ctx context.Context) *http.Server {
+	log := a.serviceProvider.GetLogger()
+
+	if meter := a.serviceProvider.GetMeter(ctx); meter != nil {
+		go observability.CollectMachineResourceMetrics(meter, log)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	srv := &http.Server{
+		Addr:              a.serviceProvider.GetConfig().GetMetricsAddress(),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		log.Info("starting metrics server", slog.String("address", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("metrics server failed", sl.Err(err))
+		}
+	}()
+
+	return srv
+}
@@ -0,0 +1,293 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+
+	bookingRepository "booking-schedule/internal/app/repository/booking"
+	outboxRepository "booking-schedule/internal/app/repository/outbox"
+	userRepository "booking-schedule/internal/app/repository/user"
+	webhookRepository "booking-schedule/internal/app/repository/webhook"
+	bookingService "booking-schedule/internal/app/service/booking"
+	botService "booking-schedule/internal/app/service/bot"
+	"booking-schedule/internal/config"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"booking-schedule/internal/pkg/db/transaction"
+	"booking-schedule/internal/pkg/observability"
+	"booking-schedule/internal/pkg/telegram"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	envLocal = "local"
+	envDev   = "dev"
+	envProd  = "prod"
+)
+
+type serviceProvider struct {
+	configPath string
+	configType string
+	config     *config.BotConfig
+
+	db        db.Client
+	txManager db.TxManager
+
+	log    *slog.Logger
+	tracer trace.Tracer
+	meter  metric.Meter
+
+	telegramClient *telegram.Client
+
+	bookingRepository bookingRepository.Repository
+	bookingService    *bookingService.Service
+
+	userRepository userRepository.Repository
+
+	webhookRepository webhookRepository.Repository
+	outboxRepository  outboxRepository.Repository
+
+	botService *botService.Service
+}
+
+func newServiceProvider(configType string, configPath string) *serviceProvider {
+	return &serviceProvider{
+		configType: configType,
+		configPath: configPath,
+	}
+}
+
+// GetDB connects to the database, the error is returned up to App.Run so that the bot shuts down cleanly.
+func (s *serviceProvider) GetDB(ctx context.Context) (db.Client, error) {
+	if s.db == nil {
+		cfg, err := s.GetConfig().GetDBConfig()
+		if err != nil {
+			s.GetLogger().Error("could not get db config", sl.Err(err))
+			return nil, err
+		}
+		replicas, err := s.GetConfig().Database.GetReplicaConfigs()
+		if err != nil {
+			s.GetLogger().Error("could not get db replica configs", sl.Err(err))
+			return nil, err
+		}
+		dbCfg := s.GetConfig().Database
+		dbc, err := db.NewClient(ctx, cfg, replicas, dbCfg.ReplicaMaxLag, dbCfg.ReplicaCheckPeriod, dbCfg.SlowQueryThreshold, s.GetLogger(), s.GetMeter(ctx))
+		if err != nil {
+			s.GetLogger().Error("could not connect to db", sl.Err(err))
+			return nil, err
+		}
+		s.db = dbc
+	}
+
+	return s.db, nil
+}
+
+func (s *serviceProvider) GetConfig() *config.BotConfig {
+	if s.config == nil {
+		if s.configType == "env" {
+			cfg, err := config.ReadBotConfigEnv()
+			if err != nil {
+				log.Fatalf("could not get bot config from env: %s", err)
+			}
+			s.config = cfg
+		} else {
+			cfg, err := config.ReadBotConfigFile(s.configPath)
+			if err != nil {
+				log.Fatalf("could not get bot config from file: %s", err)
+			}
+			s.config = cfg
+		}
+	}
+
+	return s.config
+}
+
+func (s *serviceProvider) GetBookingRepository(ctx context.Context) (bookingRepository.Repository, error) {
+	if s.bookingRepository == nil {
+		dbc, err := s.GetDB(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.bookingRepository = bookingRepository.NewBookingRepository(dbc, s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.bookingRepository, nil
+}
+
+func (s *serviceProvider) GetUserRepository(ctx context.Context) (userRepository.Repository, error) {
+	if s.userRepository == nil {
+		dbc, err := s.GetDB(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.userRepository = userRepository.NewUserRepository(dbc, s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.userRepository, nil
+}
+
+// GetWebhookRepository lets bookings changed through the bot notify webhooks as well.
+func (s *serviceProvider) GetWebhookRepository(ctx context.Context) (webhookRepository.Repository, error) {
+	if s.webhookRepository == nil {
+		dbc, err := s.GetDB(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.webhookRepository = webhookRepository.NewWebhookRepository(dbc, s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.webhookRepository, nil
+}
+
+// GetOutboxRepository stores events about bookings changed through the bot, they are published by the bookings service.
+func (s *serviceProvider) GetOutboxRepository(ctx context.Context) (outboxRepository.Repository, error) {
+	if s.outboxRepository == nil {
+		dbc, err := s.GetDB(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.outboxRepository = outboxRepository.NewOutboxRepository(dbc, s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.outboxRepository, nil
+}
+
+// GetBookingService returns the same booking service the bookings API uses, the bot does not need JWT.
+func (s *serviceProvider) GetBookingService(ctx context.Context) (*bookingService.Service, error) {
+	if s.bookingService == nil {
+		bookings, err := s.GetBookingRepository(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks, err := s.GetWebhookRepository(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		outbox, err := s.GetOutboxRepository(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		txManager, err := s.TxManager(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		s.bookingService = bookingService.NewBookingService(bookings, webhooks, outbox, nil, s.GetLogger(), txManager, s.GetTracer(ctx), s.GetConfig().GetRemindersConfig().MaxPerBooking)
+	}
+
+	return s.bookingService, nil
+}
+
+func (s *serviceProvider) GetTelegramClient() *telegram.Client {
+	if s.telegramClient == nil {
+		cfg := s.GetConfig().GetTelegramConfig()
+		s.telegramClient = telegram.NewClient(cfg.APIURL, cfg.Token, cfg.Timeout, cfg.PollTimeout)
+	}
+
+	return s.telegramClient
+}
+
+func (s *serviceProvider) GetBotService(ctx context.Context) (*botService.Service, error) {
+	if s.botService == nil {
+		bookings, err := s.GetBookingService(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		users, err := s.GetUserRepository(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := s.GetConfig().GetTelegramConfig()
+		bot, err := botService.NewBotService(
+			s.GetTelegramClient(),
+			bookings,
+			users,
+			s.GetLogger(),
+			s.GetTracer(ctx),
+			cfg.PollTimeout,
+			cfg.CheckIn,
+			cfg.CheckOut)
+		if err != nil {
+			s.GetLogger().Error("could not create bot service", sl.Err(err))
+			return nil, err
+		}
+		s.botService = bot
+	}
+
+	return s.botService, nil
+}
+
+func (s *serviceProvider) GetLogger() *slog.Logger {
+	if s.log == nil {
+		env := s.GetConfig().GetEnv()
+		switch env {
+		case envLocal:
+			s.log = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		case envDev:
+			s.log = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		case envProd:
+			s.log = slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+		}
+
+		s.log.With(slog.String("env", env))
+	}
+
+	return s.log
+}
+
+func (s *serviceProvider) TxManager(ctx context.Context) (db.TxManager, error) {
+	if s.txManager == nil {
+		dbc, err := s.GetDB(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cfg := s.GetConfig().Database
+		s.txManager = transaction.NewTransactionManager(dbc.DB(), s.GetLogger(), s.GetMeter(ctx), cfg.TxMaxAttempts, cfg.TxRetryDelay, cfg.TxMaxRetryDelay)
+	}
+
+	return s.txManager, nil
+}
+
+func (s *serviceProvider) GetTracer(ctx context.Context) trace.Tracer {
+	if s.tracer == nil {
+		tracer, err := observability.NewTracer(ctx, s.GetConfig().GetTracerConfig().EndpointURL, "bot", s.GetConfig().GetTracerConfig().SamplingRate, s.GetConfig().GetTracerConfig().Propagator)
+		if err != nil {
+			s.GetLogger().Error("failed to create tracer", sl.Err(err))
+			return nil
+		}
+
+		s.tracer = tracer
+	}
+
+	return s.tracer
+}
+
+func (s *serviceProvider) GetMeter(ctx context.Context) metric.Meter {
+	if s.meter == nil {
+		meter, err := observability.NewMeter(ctx, "bot")
+		if err != nil {
+			s.GetLogger().Error("failed to create meter", sl.Err(err))
+			return nil
+		}
+
+		s.meter = meter
+	}
+
+	return s.meter
+}
+
+// Close releases the connection to the database if it has been established.
+func (s *serviceProvider) Close() {
+	if s.db != nil {
+		s.db.Close() //nolint:errcheck
+	}
+}
@@ -0,0 +1,118 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Kinds of the supported brokers, one of them is selected in the config of every service that sends or receives messages.
+const (
+	KindRabbitMQ = "rabbitmq"
+	KindNats     = "nats"
+	KindMemory   = "memory"
+)
+
+var (
+	ErrUnknownKind        = errors.New("unknown message broker kind, expected rabbitmq, nats or memory")
+	ErrRetriesExhausted   = errors.New("message exceeded the number of delivery attempts")
+	ErrNotAcknowledgeable = errors.New("delivery can not be acknowledged")
+	ErrForeignDelivery    = errors.New("delivery was received from another broker")
+	ErrClosed             = errors.New("broker connection closed")
+)
+
+// Message ...
+type Message struct {
+	// ID is used by the broker to identify the message, a random one is generated when empty.
+	ID          string
+	Type        string
+	ContentType string
+	// RoutingKey is used by producers publishing to a topic, others route messages to their queue.
+	RoutingKey string
+	Headers    map[string]string
+	// Timestamp is set by the producer when the message is published.
+	Timestamp time.Time
+	Body      []byte
+}
+
+// Acknowledger settles a delivery with the broker it was received from.
+type Acknowledger interface {
+	// Ack reports the message as processed, it is not delivered again.
+	Ack() error
+	// Nack rejects the message, it goes straight to the dead letters.
+	Nack() error
+}
+
+// Delivery is a message received by a consumer, it must be either acknowledged, rejected or retried.
+type Delivery struct {
+	Message
+	// Количество уже сделанных повторных попыток доставки
+	Retries int
+
+	acknowledger Acknowledger
+}
+
+// NewDelivery is meant to be used by broker implementations. Deliveries without an acknowledger
+// are only for inspection, e.g. dead letters being listed.
+func NewDelivery(msg Message, retries int, acknowledger Acknowledger) *Delivery {
+	return &Delivery{
+		Message:      msg,
+		Retries:      retries,
+		acknowledger: acknowledger,
+	}
+}
+
+// Acknowledger returns what the delivery is settled with, brokers use it to find their own deliveries.
+func (d *Delivery) Acknowledger() Acknowledger {
+	return d.acknowledger
+}
+
+// Ack ...
+func (d *Delivery) Ack() error {
+	if d.acknowledger == nil {
+		return ErrNotAcknowledgeable
+	}
+
+	return d.acknowledger.Ack()
+}
+
+// Nack ...
+func (d *Delivery) Nack() error {
+	if d.acknowledger == nil {
+		return ErrNotAcknowledgeable
+	}
+
+	return d.acknowledger.Nack()
+}
+
+// Producer ...
+type Producer interface {
+	// Publish sends msg and waits until the broker confirms it has taken responsibility for it.
+	Publish(ctx context.Context, msg Message) error
+	// PublishBatch returns the outcome for every message, the i-th element nil meaning the i-th message was accepted.
+	PublishBatch(ctx context.Context, msgs []Message) []error
+	Close() error
+}
+
+// Consumer ...
+type Consumer interface {
+	// Consume returns a channel of deliveries that is closed by Cancel or Close.
+	Consume() (<-chan *Delivery, error)
+	// Cancel stops consuming. The channel of deliveries is closed once the messages already received
+	// are passed on, they can still be settled until Close.
+	Cancel() error
+	// Retry schedules one more delivery attempt after a delay. Once the attempts are exhausted the message
+	// goes to the dead letters and ErrRetriesExhausted is returned.
+	Retry(d *Delivery) error
+	// DeadLetters returns up to limit dead-lettered messages without removing them.
+	DeadLetters(limit int) ([]*Delivery, error)
+	// Replay moves up to limit dead-lettered messages back to the queue with a fresh retry budget
+	// and returns the number of messages moved.
+	Replay(limit int) (int, error)
+	Close() error
+}
+
+// RetryDelay grows exponentially: the first retry waits base, the second 2*base and so on.
+func RetryDelay(base time.Duration, attempt int) time.Duration {
+	return base * time.Duration(1<<(attempt-1))
+}
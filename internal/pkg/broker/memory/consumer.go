@@ -0,0 +1,167 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"booking-schedule/internal/pkg/broker"
+)
+
+type consumer struct {
+	queue *queue
+
+	maxRetries int
+	retryDelay time.Duration
+
+	cancelled  chan struct{}
+	cancelOnce sync.Once
+	done       chan struct{}
+	closeOnce  sync.Once
+}
+
+// NewConsumer creates a consumer of the queue. Retries are scheduled with timers and are lost
+// if the process exits before they fire.
+func (b *Broker) NewConsumer(queueName string, maxRetries int, retryDelay time.Duration) broker.Consumer {
+	return &consumer{
+		queue:      b.queue(queueName),
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+		cancelled:  make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+// delivery settles a message taken from the queue, it can be settled only once.
+type delivery struct {
+	queue   *queue
+	item    item
+	settled atomic.Bool
+}
+
+func (d *delivery) Ack() error {
+	if !d.settled.CompareAndSwap(false, true) {
+		return broker.ErrNotAcknowledgeable
+	}
+
+	return nil
+}
+
+func (d *delivery) Nack() error {
+	if !d.settled.CompareAndSwap(false, true) {
+		return broker.ErrNotAcknowledgeable
+	}
+
+	d.queue.deadLetter(d.item)
+
+	return nil
+}
+
+// Consume ...
+func (c *consumer) Consume() (<-chan *broker.Delivery, error) {
+	select {
+	case <-c.done:
+		return nil, broker.ErrClosed
+	default:
+	}
+
+	out := make(chan *broker.Delivery)
+	go c.forward(out)
+
+	return out, nil
+}
+
+func (c *consumer) forward(out chan<- *broker.Delivery) {
+	defer close(out)
+
+	for {
+		it, ok := c.queue.pop()
+		if !ok {
+			select {
+			case <-c.cancelled:
+				return
+			case <-c.done:
+				return
+			case <-c.queue.ready:
+				continue
+			}
+		}
+
+		d := broker.NewDelivery(it.msg, it.retries, &delivery{queue: c.queue, item: it})
+
+		select {
+		case out <- d:
+		case <-c.cancelled:
+			// сообщение не было передано получателю, возвращаем его в очередь
+			c.queue.push(it)
+			return
+		case <-c.done:
+			c.queue.push(it)
+			return
+		}
+	}
+}
+
+// Cancel ...
+func (c *consumer) Cancel() error {
+	c.cancelOnce.Do(func() {
+		close(c.cancelled)
+	})
+
+	return nil
+}
+
+// Retry ...
+func (c *consumer) Retry(d *broker.Delivery) error {
+	ack, ok := d.Acknowledger().(*delivery)
+	if !ok {
+		return broker.ErrForeignDelivery
+	}
+
+	attempt := d.Retries + 1
+	if attempt > c.maxRetries {
+		err := ack.Nack()
+		if err != nil {
+			return err
+		}
+
+		return broker.ErrRetriesExhausted
+	}
+
+	if !ack.settled.CompareAndSwap(false, true) {
+		return broker.ErrNotAcknowledgeable
+	}
+
+	it := ack.item
+	it.retries = attempt
+	time.AfterFunc(broker.RetryDelay(c.retryDelay, attempt), func() {
+		c.queue.push(it)
+	})
+
+	return nil
+}
+
+// DeadLetters ...
+func (c *consumer) DeadLetters(limit int) ([]*broker.Delivery, error) {
+	items := c.queue.deadLetters(limit)
+
+	res := make([]*broker.Delivery, 0, len(items))
+	for _, it := range items {
+		res = append(res, broker.NewDelivery(it.msg, it.retries, nil))
+	}
+
+	return res, nil
+}
+
+// Replay ...
+func (c *consumer) Replay(limit int) (int, error) {
+	return c.queue.replay(limit), nil
+}
+
+func (c *consumer) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var ErrNotShared = errors.New("memory broker works only for services running in one process, use rabbitmq or nats for separate binaries")
+
+var (
+	defaultBroker = NewBroker()
+	// Процесс запускает и публикующие, и получающие сервисы
+	shared atomic.Bool
+)
+
+// Share lets Default hand out the broker of the process. It is called by the entry points that run the producing
+// and the consuming services in one process, such as a combined binary or a test harness. Separate binaries
+// would each get a broker of their own and silently lose every message, so Default refuses them.
+func Share() {
+	shared.Store(true)
+}
+
+// Default returns the broker shared by the whole process, producers and consumers created
+// in different services of one process exchange messages through it. ErrNotShared is returned
+// until Share is called.
+func Default() (*Broker, error) {
+	if !shared.Load() {
+		return nil, ErrNotShared
+	}
+
+	return defaultBroker, nil
+}
+
+// Broker keeps queues in the memory of the process. It is meant for tests and for running the services
+// in a single process during development: unacknowledged and dead-lettered messages are lost on exit.
+type Broker struct {
+	mu     sync.Mutex
+	queues map[string]*queue
+	// Привязки очередей к обменникам по шаблонам ключей маршрутизации
+	bindings map[string][]binding
+}
+
+type binding struct {
+	pattern   []string
+	queueName string
+}
+
+func NewBroker() *Broker {
+	return &Broker{
+		queues:   make(map[string]*queue),
+		bindings: make(map[string][]binding),
+	}
+}
+
+// Bind routes the messages published to exchange with routing keys matching pattern to the queue.
+// Patterns follow the AMQP topic syntax: * matches exactly one word and # matches zero or more words.
+func (b *Broker) Bind(exchange string, pattern string, queueName string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bindings[exchange] = append(b.bindings[exchange], binding{
+		pattern:   strings.Split(pattern, "."),
+		queueName: queueName,
+	})
+}
+
+func (b *Broker) queue(name string) *queue {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.queues[name]
+	if !ok {
+		q = newQueue()
+		b.queues[name] = q
+	}
+
+	return q
+}
+
+// route returns the queues bound to exchange with patterns matching routingKey.
+// Messages no queue is bound to are dropped, as a topic exchange would do.
+func (b *Broker) route(exchange string, routingKey string) []*queue {
+	b.mu.Lock()
+	bindings := b.bindings[exchange]
+	b.mu.Unlock()
+
+	key := strings.Split(routingKey, ".")
+
+	var res []*queue
+	seen := make(map[string]struct{}, len(bindings))
+	for _, binding := range bindings {
+		if _, ok := seen[binding.queueName]; ok || !match(binding.pattern, key) {
+			continue
+		}
+		seen[binding.queueName] = struct{}{}
+		res = append(res, b.queue(binding.queueName))
+	}
+
+	return res
+}
+
+func match(pattern []string, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+
+	switch pattern[0] {
+	case "#":
+		for i := 0; i <= len(key); i++ {
+			if match(pattern[1:], key[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return len(key) != 0 && match(pattern[1:], key[1:])
+	default:
+		return len(key) != 0 && key[0] == pattern[0] && match(pattern[1:], key[1:])
+	}
+}
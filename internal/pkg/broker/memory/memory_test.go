@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"booking-schedule/internal/pkg/broker"
+)
+
+func receive(t *testing.T, deliveries <-chan *broker.Delivery) *broker.Delivery {
+	t.Helper()
+
+	select {
+	case d, ok := <-deliveries:
+		if !ok {
+			t.Fatal("deliveries channel closed")
+		}
+		return d
+	case <-time.After(time.Second):
+		t.Fatal("message was not delivered")
+		return nil
+	}
+}
+
+func expectNone(t *testing.T, deliveries <-chan *broker.Delivery) {
+	t.Helper()
+
+	select {
+	case d := <-deliveries:
+		t.Fatalf("unexpected delivery %q", d.Body)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestDefaultRequiresShare(t *testing.T) {
+	shared.Store(false)
+	t.Cleanup(func() { shared.Store(false) })
+
+	_, err := Default()
+	if !errors.Is(err, ErrNotShared) {
+		t.Fatalf("Default() returned %v before Share, want %v", err, ErrNotShared)
+	}
+
+	Share()
+
+	first, err := Default()
+	if err != nil {
+		t.Fatalf("Default() after Share: %v", err)
+	}
+	second, _ := Default() //nolint:errcheck
+	if first != second {
+		t.Fatal("Default() returned different brokers within one process")
+	}
+}
+
+func TestQueueDelivers(t *testing.T) {
+	b := NewBroker()
+	p := b.NewProducer("notifications")
+	c := b.NewConsumer("notifications", 3, time.Millisecond)
+	t.Cleanup(func() { c.Close() }) //nolint:errcheck
+
+	deliveries, err := c.Consume()
+	if err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+
+	err = p.Publish(context.Background(), broker.Message{Type: "reminder", Body: []byte(`{}`)})
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	d := receive(t, deliveries)
+	if d.Type != "reminder" || d.ID == "" || d.Timestamp.IsZero() {
+		t.Errorf("unexpected delivery %+v", d.Message)
+	}
+
+	err = d.Ack()
+	if err != nil {
+		t.Fatalf("ack: %v", err)
+	}
+	if !errors.Is(d.Ack(), broker.ErrNotAcknowledgeable) {
+		t.Error("delivery was acknowledged twice")
+	}
+}
+
+func TestTopicRouting(t *testing.T) {
+	b := NewBroker()
+	b.Bind("events", "booking.*", "bookings")
+	b.Bind("events", "#", "all")
+
+	p := b.NewTopicProducer("events")
+	bookings := b.NewConsumer("bookings", 0, 0)
+	all := b.NewConsumer("all", 0, 0)
+	t.Cleanup(func() {
+		bookings.Close() //nolint:errcheck
+		all.Close()      //nolint:errcheck
+	})
+
+	bookingDeliveries, _ := bookings.Consume() //nolint:errcheck
+	allDeliveries, _ := all.Consume()          //nolint:errcheck
+
+	ctx := context.Background()
+	for _, key := range []string{"booking.created", "user.deleted"} {
+		err := p.Publish(ctx, broker.Message{RoutingKey: key, Body: []byte(key)})
+		if err != nil {
+			t.Fatalf("publish %s: %v", key, err)
+		}
+	}
+
+	if d := receive(t, bookingDeliveries); d.RoutingKey != "booking.created" {
+		t.Errorf("bookings queue received %q", d.RoutingKey)
+	}
+	expectNone(t, bookingDeliveries)
+
+	for _, key := range []string{"booking.created", "user.deleted"} {
+		if d := receive(t, allDeliveries); d.RoutingKey != key {
+			t.Errorf("catch-all queue received %q, want %q", d.RoutingKey, key)
+		}
+	}
+}
+
+func TestRetryThenDeadLetterThenReplay(t *testing.T) {
+	b := NewBroker()
+	p := b.NewProducer("notifications")
+	c := b.NewConsumer("notifications", 1, time.Millisecond)
+	t.Cleanup(func() { c.Close() }) //nolint:errcheck
+
+	deliveries, err := c.Consume()
+	if err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+
+	err = p.Publish(context.Background(), broker.Message{Body: []byte(`{}`)})
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	d := receive(t, deliveries)
+	err = c.Retry(d)
+	if err != nil {
+		t.Fatalf("first retry: %v", err)
+	}
+
+	d = receive(t, deliveries)
+	if d.Retries != 1 {
+		t.Fatalf("redelivery has %d retries, want 1", d.Retries)
+	}
+
+	err = c.Retry(d)
+	if !errors.Is(err, broker.ErrRetriesExhausted) {
+		t.Fatalf("second retry returned %v, want %v", err, broker.ErrRetriesExhausted)
+	}
+	expectNone(t, deliveries)
+
+	dead, err := c.DeadLetters(10)
+	if err != nil || len(dead) != 1 {
+		t.Fatalf("dead letters: %d, %v; want 1 message", len(dead), err)
+	}
+
+	n, err := c.Replay(10)
+	if err != nil || n != 1 {
+		t.Fatalf("replay: %d, %v; want 1 message", n, err)
+	}
+
+	d = receive(t, deliveries)
+	if d.Retries != 0 {
+		t.Errorf("replayed message has %d retries, want 0", d.Retries)
+	}
+}
+
+func TestClosedProducerRefuses(t *testing.T) {
+	p := NewBroker().NewProducer("notifications")
+
+	err := p.Close()
+	if err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	err = p.Publish(context.Background(), broker.Message{})
+	if !errors.Is(err, broker.ErrClosed) {
+		t.Fatalf("publish after close returned %v, want %v", err, broker.ErrClosed)
+	}
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"sync/atomic"
+	"time"
+
+	"booking-schedule/internal/pkg/broker"
+
+	"github.com/gofrs/uuid"
+)
+
+type producer struct {
+	broker *Broker
+	// exchange пустой у продюсеров, публикующих в очередь
+	exchange  string
+	queueName string
+	closed    atomic.Bool
+}
+
+// NewProducer creates a producer that puts messages into the queue.
+func (b *Broker) NewProducer(queueName string) broker.Producer {
+	return &producer{
+		broker:    b,
+		queueName: queueName,
+	}
+}
+
+// NewTopicProducer creates a producer that routes messages to the queues bound to the exchange by their routing keys.
+func (b *Broker) NewTopicProducer(exchange string) broker.Producer {
+	return &producer{
+		broker:   b,
+		exchange: exchange,
+	}
+}
+
+// Publish ...
+func (p *producer) Publish(ctx context.Context, msg broker.Message) error {
+	if p.closed.Load() {
+		return broker.ErrClosed
+	}
+
+	if msg.ID == "" {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return err
+		}
+		msg.ID = id.String()
+	}
+
+	// заголовки копируются, чтобы не изменять сообщение вызывающего
+	msg.Headers = maps.Clone(msg.Headers)
+	broker.InjectTraceContext(ctx, &msg)
+	msg.Timestamp = time.Now()
+
+	if p.exchange == "" {
+		p.broker.queue(p.queueName).push(item{msg: msg})
+		return nil
+	}
+
+	for _, q := range p.broker.route(p.exchange, msg.RoutingKey) {
+		q.push(item{msg: msg})
+	}
+
+	return nil
+}
+
+// PublishBatch ...
+func (p *producer) PublishBatch(ctx context.Context, msgs []broker.Message) []error {
+	errs := make([]error, len(msgs))
+	for i, msg := range msgs {
+		errs[i] = p.Publish(ctx, msg)
+	}
+
+	return errs
+}
+
+func (p *producer) Close() error {
+	p.closed.Store(true)
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"sync"
+
+	"booking-schedule/internal/pkg/broker"
+)
+
+type item struct {
+	msg     broker.Message
+	retries int
+}
+
+// queue is an unbounded FIFO queue with its dead letters.
+type queue struct {
+	mu    sync.Mutex
+	items []item
+	dead  []item
+	// ready получает значение, когда в пустую очередь добавляется сообщение
+	ready chan struct{}
+}
+
+func newQueue() *queue {
+	return &queue{
+		ready: make(chan struct{}, 1),
+	}
+}
+
+func (q *queue) push(it item) {
+	q.mu.Lock()
+	q.items = append(q.items, it)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *queue) pop() (item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.items) == 0 {
+		return item{}, false
+	}
+
+	it := q.items[0]
+	q.items[0] = item{}
+	q.items = q.items[1:]
+
+	return it, true
+}
+
+func (q *queue) deadLetter(it item) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.dead = append(q.dead, it)
+}
+
+func (q *queue) deadLetters(limit int) []item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]item(nil), q.dead[:min(limit, len(q.dead))]...)
+}
+
+// replay moves up to limit dead letters back to the queue with a fresh retry budget.
+func (q *queue) replay(limit int) int {
+	q.mu.Lock()
+	n := min(limit, len(q.dead))
+	replayed := q.dead[:n]
+	q.dead = append([]item(nil), q.dead[n:]...)
+	q.mu.Unlock()
+
+	for _, it := range replayed {
+		it.retries = 0
+		q.push(it)
+	}
+
+	return n
+}
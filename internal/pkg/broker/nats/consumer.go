@@ -0,0 +1,285 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"booking-schedule/internal/config"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	consumerName = "sender"
+	// deadLetterWait limits waiting for dead letters, there may be fewer of them than requested.
+	deadLetterWait = time.Second
+)
+
+type consumer struct {
+	conn *nats.Conn
+	js   jetstream.JetStream
+	log  *slog.Logger
+
+	subject        string
+	work           jetstream.Consumer
+	dead           jetstream.Consumer
+	publishTimeout time.Duration
+
+	maxRetries int
+	retryDelay time.Duration
+	prefetch   int
+
+	mu        sync.Mutex
+	messages  jetstream.MessagesContext
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewConsumer creates durable consumers of the work queue subject and of its dead letters.
+// Delayed retries are scheduled by the server, so they survive restarts of the consumer.
+func NewConsumer(config *config.Nats, log *slog.Logger) (broker.Consumer, error) {
+	conn, js, err := connect(config.URL, config.ReconnectDelay, log)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+
+	stream, err := declareQueueStream(ctx, js, config.Stream, config.Subject)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	work, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       consumerName,
+		FilterSubject: config.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       config.AckWait,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	dead, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       consumerName + "-dead",
+		FilterSubject: deadLetterSubject(config.Subject),
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       config.AckWait,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &consumer{
+		conn:           conn,
+		js:             js,
+		log:            log,
+		subject:        config.Subject,
+		work:           work,
+		dead:           dead,
+		publishTimeout: config.PublishTimeout,
+		maxRetries:     config.MaxRetries,
+		retryDelay:     config.RetryDelay,
+		prefetch:       config.Prefetch,
+		done:           make(chan struct{}),
+	}, nil
+}
+
+// delivery settles the JetStream message a broker.Delivery was made of.
+type delivery struct {
+	consumer *consumer
+	msg      jetstream.Msg
+}
+
+func (d *delivery) Ack() error {
+	return d.msg.Ack()
+}
+
+// Nack moves the message to the dead letters, JetStream has no dead-letter queues of its own.
+func (d *delivery) Nack() error {
+	return d.consumer.deadLetter(d.msg)
+}
+
+// Consume ...
+func (c *consumer) Consume() (<-chan *broker.Delivery, error) {
+	messages, err := c.work.Messages(jetstream.PullMaxMessages(c.prefetch))
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.messages = messages
+	c.mu.Unlock()
+
+	out := make(chan *broker.Delivery)
+	go c.forward(messages, out)
+
+	return out, nil
+}
+
+func (c *consumer) forward(messages jetstream.MessagesContext, out chan<- *broker.Delivery) {
+	const op = "nats.consumer.forward"
+
+	log := c.log.With(slog.String("op", op))
+
+	defer close(out)
+
+	for {
+		msg, err := messages.Next()
+		if errors.Is(err, jetstream.ErrMsgIteratorClosed) {
+			return
+		}
+		if err != nil {
+			// пропущенные сигналы активности и потеря соединения не прерывают получение сообщений
+			log.Warn("failed to receive message", sl.Err(err))
+			continue
+		}
+
+		select {
+		case out <- broker.NewDelivery(toMessage(msg), retryCount(msg), &delivery{consumer: c, msg: msg}):
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// Cancel stops pulling messages. Messages pulled but not yet passed on are not acknowledged,
+// the server delivers them again once the ack wait expires.
+func (c *consumer) Cancel() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.messages != nil {
+		c.messages.Stop()
+	}
+
+	return nil
+}
+
+// Retry asks the server to deliver the message again after the delay of the next attempt.
+func (c *consumer) Retry(d *broker.Delivery) error {
+	ack, ok := d.Acknowledger().(*delivery)
+	if !ok {
+		return broker.ErrForeignDelivery
+	}
+
+	attempt := d.Retries + 1
+	if attempt > c.maxRetries {
+		err := c.deadLetter(ack.msg)
+		if err != nil {
+			return err
+		}
+
+		return broker.ErrRetriesExhausted
+	}
+
+	return ack.msg.NakWithDelay(broker.RetryDelay(c.retryDelay, attempt))
+}
+
+// deadLetter republishes the message to the dead-letter subject and removes it from the queue.
+func (c *consumer) deadLetter(msg jetstream.Msg) error {
+	// Nats-Msg-Id не копируется: публикация с тем же идентификатором была бы отброшена как дубликат
+	m := nats.NewMsg(deadLetterSubject(c.subject))
+	copyHeaders(m, msg)
+	m.Header.Set(retryCountHeader, strconv.Itoa(retryCount(msg)))
+	m.Data = msg.Data()
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.publishTimeout)
+	defer cancel()
+
+	_, err := c.js.PublishMsg(ctx, m)
+	if err != nil {
+		return err
+	}
+
+	return msg.Ack()
+}
+
+// DeadLetters ...
+func (c *consumer) DeadLetters(limit int) ([]*broker.Delivery, error) {
+	msgs, err := c.fetchDeadLetters(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// сообщения возвращаются, только когда получены все, иначе сервер может доставить их повторно в этой же выборке
+	res := make([]*broker.Delivery, 0, len(msgs))
+	for _, msg := range msgs {
+		err := msg.Nak()
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, broker.NewDelivery(toMessage(msg), retryCount(msg), nil))
+	}
+
+	return res, nil
+}
+
+// Replay ...
+func (c *consumer) Replay(limit int) (int, error) {
+	msgs, err := c.fetchDeadLetters(limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var replayed int
+
+	for _, msg := range msgs {
+		m := nats.NewMsg(c.subject)
+		copyHeaders(m, msg, retryCountHeader)
+		m.Data = msg.Data()
+
+		ctx, cancel := context.WithTimeout(context.Background(), c.publishTimeout)
+		_, err := c.js.PublishMsg(ctx, m)
+		cancel()
+		if err != nil {
+			msg.Nak() //nolint:errcheck
+			return replayed, err
+		}
+
+		err = msg.Ack()
+		if err != nil {
+			return replayed, err
+		}
+
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+func (c *consumer) fetchDeadLetters(limit int) ([]jetstream.Msg, error) {
+	batch, err := c.dead.Fetch(limit, jetstream.FetchMaxWait(deadLetterWait))
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]jetstream.Msg, 0, limit)
+	for msg := range batch.Messages() {
+		msgs = append(msgs, msg)
+	}
+
+	return msgs, batch.Error()
+}
+
+func (c *consumer) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.Cancel() //nolint:errcheck
+		c.conn.Close()
+	})
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package nats
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	instrumentationName = "booking-schedule/internal/pkg/broker/nats"
+	messagingSystem     = "nats"
+
+	contentType = "application/json"
+
+	// Заголовки, в которых передаются поля сообщения, не имеющие аналогов в NATS. Идентификатор
+	// передается и в Nats-Msg-Id для дедупликации, но тот не переносится в недоставленные сообщения
+	messageIDHeader   = "Message-Id"
+	typeHeader        = "Message-Type"
+	contentTypeHeader = "Content-Type"
+	// retryCountHeader holds the number of delivery attempts made for a dead-lettered message.
+	retryCountHeader = "Retry-Count"
+
+	deadLetterSubjectSuffix = ".dead"
+	// setupTimeout limits declaring streams and consumers when connecting.
+	setupTimeout = 10 * time.Second
+)
+
+func deadLetterSubject(subject string) string {
+	return subject + deadLetterSubjectSuffix
+}
+
+// connect dials the server, the client library takes care of reconnecting
+// and resubscribing after the connection is lost.
+func connect(url string, reconnectDelay time.Duration, log *slog.Logger) (*nats.Conn, jetstream.JetStream, error) {
+	const op = "nats.connect"
+
+	log = log.With(slog.String("op", op))
+
+	conn, err := nats.Connect(url,
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(reconnectDelay),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				log.Warn("lost connection to nats", sl.Err(err))
+			}
+		}),
+		nats.ReconnectHandler(func(_ *nats.Conn) {
+			log.Info("reconnected to nats")
+		}),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	return conn, js, nil
+}
+
+// declareQueueStream declares the work queue stream holding the messages published to subject
+// and the ones dead-lettered from it. Messages are removed from the stream once acknowledged.
+func declareQueueStream(ctx context.Context, js jetstream.JetStream, stream string, subject string) (jetstream.Stream, error) {
+	return js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      stream,
+		Subjects:  []string{subject, deadLetterSubject(subject)},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+	})
+}
+
+// declareTopicStream declares the stream domain events are published to. Messages are kept
+// until every consumer bound to them acknowledges them and are dropped if there are no consumers.
+func declareTopicStream(ctx context.Context, js jetstream.JetStream, stream string, subjects []string) (jetstream.Stream, error) {
+	return js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      stream,
+		Subjects:  subjects,
+		Retention: jetstream.InterestPolicy,
+		Storage:   jetstream.FileStorage,
+	})
+}
+
+// copyHeaders copies the headers of msg except the ones of the server and the skipped ones.
+func copyHeaders(dst *nats.Msg, msg jetstream.Msg, skip ...string) {
+	for k := range msg.Headers() {
+		if strings.HasPrefix(k, "Nats-") || slices.Contains(skip, k) {
+			continue
+		}
+		dst.Header.Set(k, msg.Headers().Get(k))
+	}
+}
+
+// toMessage keeps the application headers only, the ones carrying message fields are moved to the fields.
+func toMessage(msg jetstream.Msg) broker.Message {
+	res := broker.Message{
+		RoutingKey: msg.Subject(),
+		Headers:    make(map[string]string, len(msg.Headers())),
+		Body:       msg.Data(),
+	}
+
+	for k := range msg.Headers() {
+		value := msg.Headers().Get(k)
+		switch {
+		case k == messageIDHeader:
+			res.ID = value
+		case k == typeHeader:
+			res.Type = value
+		case k == contentTypeHeader:
+			res.ContentType = value
+		case k == retryCountHeader, strings.HasPrefix(k, "Nats-"):
+		default:
+			res.Headers[k] = value
+		}
+	}
+
+	meta, err := msg.Metadata()
+	if err == nil {
+		res.Timestamp = meta.Timestamp
+	}
+
+	return res
+}
+
+// retryCount returns the number of attempts already made to process the message.
+func retryCount(msg jetstream.Msg) int {
+	count, err := strconv.Atoi(msg.Headers().Get(retryCountHeader))
+	if err == nil {
+		return count
+	}
+
+	meta, err := msg.Metadata()
+	if err != nil || meta.NumDelivered == 0 {
+		return 0
+	}
+
+	return int(meta.NumDelivered) - 1
+}
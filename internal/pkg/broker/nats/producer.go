@@ -0,0 +1,184 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"booking-schedule/internal/config"
+	"booking-schedule/internal/pkg/broker"
+
+	"github.com/gofrs/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var ErrPublishTimeout = errors.New("timed out waiting for publish acknowledgement")
+
+type producer struct {
+	conn *nats.Conn
+	js   jetstream.JetStream
+
+	// subject пустой у продюсеров, публикующих сообщения в темы по их ключам маршрутизации
+	subject        string
+	publishTimeout time.Duration
+}
+
+// NewProducer creates a producer that publishes messages to the subject of the work queue stream.
+func NewProducer(config *config.Nats, log *slog.Logger) (broker.Producer, error) {
+	conn, js, err := connect(config.URL, config.ReconnectDelay, log)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+
+	_, err = declareQueueStream(ctx, js, config.Stream, config.Subject)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &producer{
+		conn:           conn,
+		js:             js,
+		subject:        config.Subject,
+		publishTimeout: config.PublishTimeout,
+	}, nil
+}
+
+// NewTopicProducer creates a producer that publishes messages with their routing keys as subjects,
+// consumers pick the messages they need with subject filters.
+func NewTopicProducer(config *config.NatsPublisher, log *slog.Logger) (broker.Producer, error) {
+	conn, js, err := connect(config.URL, config.ReconnectDelay, log)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+
+	_, err = declareTopicStream(ctx, js, config.Stream, strings.Split(config.Subjects, ","))
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &producer{
+		conn:           conn,
+		js:             js,
+		publishTimeout: config.PublishTimeout,
+	}, nil
+}
+
+// Publish ...
+func (p *producer) Publish(ctx context.Context, msg broker.Message) error {
+	return p.PublishBatch(ctx, []broker.Message{msg})[0]
+}
+
+// PublishBatch publishes all of msgs before waiting for the acknowledgements of the stream.
+// Message ids are used for deduplication, so a message published twice is stored once.
+func (p *producer) PublishBatch(ctx context.Context, msgs []broker.Message) []error {
+	errs := make([]error, len(msgs))
+	futures := make([]jetstream.PubAckFuture, len(msgs))
+
+	for i, msg := range msgs {
+		futures[i], errs[i] = p.publishAsync(ctx, msg)
+	}
+
+	timer := time.NewTimer(p.publishTimeout)
+	defer timer.Stop()
+
+	for i, future := range futures {
+		if future == nil {
+			continue
+		}
+
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			errs[i] = err
+		case <-timer.C:
+			errs[i] = ErrPublishTimeout
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+		}
+	}
+
+	return errs
+}
+
+func (p *producer) publishAsync(ctx context.Context, msg broker.Message) (jetstream.PubAckFuture, error) {
+	messageID := msg.ID
+	if messageID == "" {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, err
+		}
+		messageID = id.String()
+	}
+
+	subject := p.subject
+	if subject == "" {
+		subject = msg.RoutingKey
+	}
+
+	spanCtx, span := otel.Tracer(instrumentationName).Start(ctx, subject+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKey.String(messagingSystem),
+			semconv.MessagingOperationPublish,
+			semconv.MessagingDestinationName(subject),
+			semconv.MessagingMessageID(messageID),
+		),
+	)
+	defer span.End()
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(spanCtx, carrier)
+
+	m := nats.NewMsg(subject)
+	for k, v := range msg.Headers {
+		m.Header.Set(k, v)
+	}
+	for k, v := range carrier {
+		m.Header.Set(k, v)
+	}
+
+	m.Header.Set(messageIDHeader, messageID)
+	m.Header.Set(typeHeader, msg.Type)
+	m.Header.Set(contentTypeHeader, contentType)
+	if msg.ContentType != "" {
+		m.Header.Set(contentTypeHeader, msg.ContentType)
+	}
+	m.Data = msg.Body
+
+	future, err := p.js.PublishMsgAsync(m, jetstream.WithMsgID(messageID))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	return future, nil
+}
+
+func (p *producer) Close() error {
+	// дожидаемся подтверждения уже опубликованных сообщений
+	select {
+	case <-p.js.PublishAsyncComplete():
+	case <-time.After(p.publishTimeout):
+	}
+
+	p.conn.Close()
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package broker
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// InjectTraceContext writes the trace context of ctx into the message headers.
+func InjectTraceContext(ctx context.Context, msg *Message) {
+	if msg.Headers == nil {
+		msg.Headers = make(map[string]string)
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(msg.Headers))
+}
+
+// ExtractTraceContext returns ctx enriched with the trace context the producer put into the message headers.
+func ExtractTraceContext(ctx context.Context, d *Delivery) context.Context {
+	if d.Headers == nil {
+		return ctx
+	}
+
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(d.Headers))
+}
@@ -1,9 +1,13 @@
 package db
 
 import (
+	"booking-schedule/internal/logger/sl"
 	"context"
+	"log/slog"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/metric"
 )
 
 type Client interface {
@@ -14,22 +18,61 @@ type Client interface {
 type client struct {
 	db        *db
 	closeFunc context.CancelFunc
+	// Регистрация обработчика статистики пулов соединений
+	poolStats metric.Registration
 }
 
-func NewClient(ctx context.Context, config *pgxpool.Config) (Client, error) {
+// NewClient connects to the primary and to the streaming replicas, if any. GetContext and SelectContext
+// made with WithReplica outside a transaction are served by a replica lagging no more than maxLag behind the primary,
+// the replicas are checked every checkPeriod. Queries are measured by db.Query.Name, the ones slower
+// than slowQuery are logged. A nil meter disables the metrics.
+func NewClient(ctx context.Context, config *pgxpool.Config, replicas []*pgxpool.Config, maxLag time.Duration, checkPeriod time.Duration, slowQuery time.Duration, log *slog.Logger, meter metric.Meter) (Client, error) {
 	dbc, err := pgxpool.NewWithConfig(ctx, config)
 	if err != nil {
 		return nil, err
 	}
 
-	_, cancel := context.WithCancel(ctx)
+	ctx, cancel := context.WithCancel(ctx)
 
-	return &client{
-		db: &db{
-			pool: dbc,
-		},
+	d := &db{
+		pool:      dbc,
+		metrics:   noopMetrics(),
+		log:       log,
+		slowQuery: slowQuery,
+	}
+
+	if len(replicas) > 0 {
+		set, err := newReplicaSet(ctx, replicas, maxLag, checkPeriod, log)
+		if err != nil {
+			cancel()
+			dbc.Close()
+			return nil, err
+		}
+
+		d.replicas = set
+		go set.monitor(ctx)
+	}
+
+	c := &client{
+		db:        d,
 		closeFunc: cancel,
-	}, nil
+	}
+
+	if meter != nil {
+		m, err := newMetrics(meter)
+		if err != nil {
+			log.Error("failed to create db metrics, falling back to noop meter", sl.Err(err))
+			return c, nil
+		}
+
+		d.metrics = m
+		c.poolStats, err = m.observePools(meter, d)
+		if err != nil {
+			log.Error("failed to observe db pool statistics", sl.Err(err))
+		}
+	}
+
+	return c, nil
 }
 
 func (c *client) Close() error {
@@ -37,10 +80,13 @@ func (c *client) Close() error {
 		if c.closeFunc != nil {
 			c.closeFunc()
 		}
+		if c.poolStats != nil {
+			_ = c.poolStats.Unregister() //nolint:errcheck
+		}
 	}
 
 	if c.db != nil {
-		c.db.pool.Close()
+		c.db.Close()
 	}
 
 	return nil
@@ -5,6 +5,8 @@ package db
 
 import (
 	"context"
+	"log/slog"
+	"time"
 
 	"github.com/georgysavva/scany/v2/pgxscan"
 	pgx "github.com/jackc/pgx/v5"
@@ -25,6 +27,19 @@ type Query struct {
 
 type db struct {
 	pool *pgxpool.Pool
+	// Потоковые реплики для чтений с WithReplica вне транзакций, nil, если реплики не настроены
+	replicas *replicaSet
+	metrics  *metrics
+	log      *slog.Logger
+	// Запросы дольше slowQuery попадают в журнал, ноль отключает журнал медленных запросов
+	slowQuery time.Duration
+}
+
+// querier is implemented both by the pool and by a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 }
 type SQLExecer interface {
 	NamedExecer
@@ -59,6 +74,10 @@ type Transactor interface {
 
 type TxManager interface {
 	ReadCommitted(ctx context.Context, f Handler) error
+	// RepeatableRead and Serializable retry the handler when the transaction fails on a serialization
+	// failure or a deadlock, the handler should be safe to run again
+	RepeatableRead(ctx context.Context, f Handler) error
+	Serializable(ctx context.Context, f Handler) error
 }
 
 type Handler func(ctx context.Context) error
@@ -67,28 +86,101 @@ func (d *db) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, erro
 	return d.pool.BeginTx(ctx, txOptions)
 }
 
+// conn returns the transaction stored in the context, so that queries made inside TxManager handlers
+// belong to the transaction, or the pool otherwise.
+func (d *db) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(TxKey).(pgx.Tx); ok {
+		return tx
+	}
+
+	return d.pool
+}
+
+// replica returns a healthy replica for a read made with WithReplica outside a transaction, or nil
+// when the read should go to the primary.
+func (d *db) replica(ctx context.Context) *replica {
+	if d.replicas == nil {
+		return nil
+	}
+	if _, ok := ctx.Value(TxKey).(pgx.Tx); ok {
+		return nil
+	}
+	if replica, _ := ctx.Value(ReplicaKey).(bool); !replica {
+		return nil
+	}
+
+	return d.replicas.pick()
+}
+
 func (d *db) GetContext(ctx context.Context, dest interface{}, q Query, args ...interface{}) error {
-	return pgxscan.Get(ctx, d.pool, dest, q.QueryRaw, args...)
+	if r := d.replica(ctx); r != nil {
+		start := time.Now()
+		err := pgxscan.Get(ctx, r.pool, dest, q.QueryRaw, args...)
+		d.observe(ctx, q, targetReplica, start, err, args)
+		if err == nil || !connectionFailed(err) {
+			return err
+		}
+		d.replicas.fail(r, err)
+	}
+
+	start := time.Now()
+	err := pgxscan.Get(ctx, d.conn(ctx), dest, q.QueryRaw, args...)
+	d.observe(ctx, q, targetPrimary, start, err, args)
+	recordFailure(ctx, err)
+
+	return err
 }
 
 func (d *db) SelectContext(ctx context.Context, dest interface{}, q Query, args ...interface{}) error {
-	return pgxscan.Select(ctx, d.pool, dest, q.QueryRaw, args...)
+	if r := d.replica(ctx); r != nil {
+		start := time.Now()
+		err := pgxscan.Select(ctx, r.pool, dest, q.QueryRaw, args...)
+		d.observe(ctx, q, targetReplica, start, err, args)
+		if err == nil || !connectionFailed(err) {
+			return err
+		}
+		d.replicas.fail(r, err)
+	}
+
+	start := time.Now()
+	err := pgxscan.Select(ctx, d.conn(ctx), dest, q.QueryRaw, args...)
+	d.observe(ctx, q, targetPrimary, start, err, args)
+	recordFailure(ctx, err)
+
+	return err
 }
 
 func (d *db) ExecContext(ctx context.Context, q Query, args ...interface{}) (pgconn.CommandTag, error) {
-	return d.pool.Exec(ctx, q.QueryRaw, args...)
+	start := time.Now()
+	tag, err := d.conn(ctx).Exec(ctx, q.QueryRaw, args...)
+	d.observe(ctx, q, targetPrimary, start, err, args)
+	recordFailure(ctx, err)
+
+	return tag, err
 }
 
 func (d *db) QueryContext(ctx context.Context, q Query, args ...interface{}) (pgx.Rows, error) {
-	return d.pool.Query(ctx, q.QueryRaw, args...)
+	start := time.Now()
+	res, err := d.conn(ctx).Query(ctx, q.QueryRaw, args...)
+	if err != nil {
+		d.observe(ctx, q, targetPrimary, start, err, args)
+		recordFailure(ctx, err)
+		return nil, err
+	}
+
+	return &rows{Rows: res, ctx: ctx, db: d, q: q, start: start, args: args}, nil
 }
 
 func (d *db) QueryRowContext(ctx context.Context, q Query, args ...interface{}) pgx.Row {
-	return d.pool.QueryRow(ctx, q.QueryRaw, args...)
+	start := time.Now()
+	return &row{Row: d.conn(ctx).QueryRow(ctx, q.QueryRaw, args...), ctx: ctx, db: d, q: q, start: start, args: args}
 }
 
 func (d *db) Close() {
 	d.pool.Close()
+	if d.replicas != nil {
+		d.replicas.close()
+	}
 }
 
 func GetContextTx(ctx context.Context, tx pgx.Tx) context.Context {
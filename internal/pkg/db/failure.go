@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Коды ошибок PostgreSQL, после которых транзакцию можно повторить целиком
+const (
+	CodeSerializationFailure = "40001"
+	CodeDeadlockDetected     = "40P01"
+)
+
+const TxFailureKey key = "tx_failure"
+
+// TxFailure remembers the serialization failure or the deadlock a query of the transaction ran into.
+// Repositories replace database errors with their own, so the transaction manager learns from it
+// whether the transaction is worth retrying.
+type TxFailure struct {
+	mu   sync.Mutex
+	code string
+}
+
+// Code returns the SQLSTATE of the retryable error, empty if there was none.
+func (f *TxFailure) Code() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.code
+}
+
+func (f *TxFailure) record(err error) {
+	code, ok := RetryableCode(err)
+	if !ok {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.code == "" {
+		f.code = code
+	}
+}
+
+// RetryableCode returns the SQLSTATE of a serialization failure or a deadlock found in the error chain.
+func RetryableCode(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return "", false
+	}
+
+	switch pgErr.Code {
+	case CodeSerializationFailure, CodeDeadlockDetected:
+		return pgErr.Code, true
+	default:
+		return "", false
+	}
+}
+
+// recordFailure notes the error on the transaction of the context, if there is one.
+func recordFailure(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+
+	if f, ok := ctx.Value(TxFailureKey).(*TxFailure); ok {
+		f.record(err)
+	}
+}
+
+// row reports the error of the query, known only once the row is scanned.
+type row struct {
+	pgx.Row
+	ctx   context.Context
+	db    *db
+	q     Query
+	start time.Time
+	args  []any
+}
+
+func (r *row) Scan(dest ...any) error {
+	err := r.Row.Scan(dest...)
+	r.db.observe(r.ctx, r.q, targetPrimary, r.start, err, r.args)
+	recordFailure(r.ctx, err)
+
+	return err
+}
+
+// rows reports the error of the query, known only once the rows are read. The query is observed
+// when the rows are closed.
+type rows struct {
+	pgx.Rows
+	ctx      context.Context
+	db       *db
+	q        Query
+	start    time.Time
+	args     []any
+	observed bool
+}
+
+func (r *rows) Err() error {
+	err := r.Rows.Err()
+	recordFailure(r.ctx, err)
+
+	return err
+}
+
+func (r *rows) Close() {
+	r.Rows.Close()
+
+	if !r.observed {
+		r.observed = true
+		r.db.observe(r.ctx, r.q, targetPrimary, r.start, r.Rows.Err(), r.args)
+	}
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// Сервер, выполнивший запрос
+const (
+	targetPrimary = "primary"
+	targetReplica = "replica"
+)
+
+type metrics struct {
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+
+	acquired metric.Int64ObservableGauge
+	idle     metric.Int64ObservableGauge
+	total    metric.Int64ObservableGauge
+	maxConns metric.Int64ObservableGauge
+	waited   metric.Int64ObservableCounter
+}
+
+func newMetrics(meter metric.Meter) (*metrics, error) {
+	duration, err := meter.Float64Histogram(
+		"db.query.duration",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Measures the duration of queries by query name and target server"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := meter.Int64Counter(
+		"db.query.errors",
+		metric.WithDescription("Number of failed queries by query name and target server, not counting queries without rows"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	acquired, err := meter.Int64ObservableGauge(
+		"db.pool.connections.acquired",
+		metric.WithDescription("Number of connections currently in use"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	idle, err := meter.Int64ObservableGauge(
+		"db.pool.connections.idle",
+		metric.WithDescription("Number of idle connections in the pool"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := meter.Int64ObservableGauge(
+		"db.pool.connections.total",
+		metric.WithDescription("Number of connections in the pool, including the ones being established"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	maxConns, err := meter.Int64ObservableGauge(
+		"db.pool.connections.max",
+		metric.WithDescription("Maximum size of the pool"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	waited, err := meter.Int64ObservableCounter(
+		"db.pool.acquires.waited",
+		metric.WithDescription("Number of connection acquires that waited for a connection to be released or established"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metrics{
+		duration: duration,
+		errors:   errs,
+		acquired: acquired,
+		idle:     idle,
+		total:    total,
+		maxConns: maxConns,
+		waited:   waited,
+	}, nil
+}
+
+// observePools reports the statistics of the primary pool and of the replica pools on every collection.
+func (m *metrics) observePools(meter metric.Meter, d *db) (metric.Registration, error) {
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		observe := func(pool *pgxpool.Pool, target string) {
+			stat := pool.Stat()
+			attrs := metric.WithAttributes(
+				attribute.String("target", target),
+				attribute.String("host", pool.Config().ConnConfig.Host),
+			)
+
+			o.ObserveInt64(m.acquired, int64(stat.AcquiredConns()), attrs)
+			o.ObserveInt64(m.idle, int64(stat.IdleConns()), attrs)
+			o.ObserveInt64(m.total, int64(stat.TotalConns()), attrs)
+			o.ObserveInt64(m.maxConns, int64(stat.MaxConns()), attrs)
+			o.ObserveInt64(m.waited, stat.EmptyAcquireCount(), attrs)
+		}
+
+		observe(d.pool, targetPrimary)
+		if d.replicas != nil {
+			for _, r := range d.replicas.replicas {
+				observe(r.pool, targetReplica)
+			}
+		}
+
+		return nil
+	}, m.acquired, m.idle, m.total, m.maxConns, m.waited)
+}
+
+func noopMetrics() *metrics {
+	m, _ := newMetrics(noop.NewMeterProvider().Meter("db")) //nolint:errcheck
+	return m
+}
+
+// observe records the duration and the outcome of the query and logs it when it is slower than the threshold.
+func (d *db) observe(ctx context.Context, q Query, target string, start time.Time, err error, args []any) {
+	elapsed := time.Since(start)
+	attrs := metric.WithAttributes(attribute.String("query", q.Name), attribute.String("target", target))
+
+	d.metrics.duration.Record(ctx, float64(elapsed)/float64(time.Millisecond), attrs)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		d.metrics.errors.Add(ctx, 1, attrs)
+	}
+
+	if d.slowQuery > 0 && elapsed >= d.slowQuery {
+		d.log.Warn("slow query",
+			slog.String("query", q.Name),
+			slog.String("target", target),
+			slog.Duration("duration", elapsed),
+			slog.Int("arg_count", len(args)),
+			slog.Any("arg_types", redactArgs(args)),
+		)
+	}
+}
+
+// redactArgs replaces the arguments with their types. Even ids and dates tell who booked what and when,
+// e.g. a telegram id, so no values get into the log.
+func redactArgs(args []any) []string {
+	res := make([]string, 0, len(args))
+
+	for _, arg := range args {
+		if arg == nil {
+			res = append(res, "null")
+			continue
+		}
+		res = append(res, fmt.Sprintf("%T", arg))
+	}
+
+	return res
+}
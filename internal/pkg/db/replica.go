@@ -0,0 +1,149 @@
+package db
+
+import (
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	ReplicaKey key = "replica"
+)
+
+// Отставание реплики в секундах: ноль, если реплика применила все полученные записи WAL
+// или сервер не находится в режиме восстановления.
+const replicaLagQuery = `select case
+	when not pg_is_in_recovery() then 0
+	when pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() then 0
+	else coalesce(extract(epoch from now() - pg_last_xact_replay_timestamp()), 0)
+end::float8`
+
+type replica struct {
+	pool *pgxpool.Pool
+	// Реплика отвечает на проверку и отстает не больше допустимого
+	healthy atomic.Bool
+}
+
+type replicaSet struct {
+	replicas    []*replica
+	next        atomic.Uint64
+	maxLag      time.Duration
+	checkPeriod time.Duration
+	log         *slog.Logger
+}
+
+// WithReplica returns a context whose reads may be served by a replica. Reads go to the primary
+// by default, only the ones that tolerate stale data should opt in.
+func WithReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ReplicaKey, true)
+}
+
+func newReplicaSet(ctx context.Context, configs []*pgxpool.Config, maxLag time.Duration, checkPeriod time.Duration, log *slog.Logger) (*replicaSet, error) {
+	set := &replicaSet{
+		maxLag:      maxLag,
+		checkPeriod: checkPeriod,
+		log:         log,
+	}
+
+	for _, config := range configs {
+		pool, err := pgxpool.NewWithConfig(ctx, config)
+		if err != nil {
+			set.close()
+			return nil, err
+		}
+
+		set.replicas = append(set.replicas, &replica{pool: pool})
+	}
+
+	return set, nil
+}
+
+// monitor checks the replicas every check period until the context is cancelled. Until the first
+// check succeeds, reads are served by the primary.
+func (s *replicaSet) monitor(ctx context.Context) {
+	ticker := time.NewTicker(s.checkPeriod)
+	defer ticker.Stop()
+
+	for {
+		for _, r := range s.replicas {
+			s.check(ctx, r)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *replicaSet) check(ctx context.Context, r *replica) {
+	ctx, cancel := context.WithTimeout(ctx, s.checkPeriod)
+	defer cancel()
+
+	host := r.pool.Config().ConnConfig.Host
+
+	var lag float64
+	err := r.pool.QueryRow(ctx, replicaLagQuery).Scan(&lag)
+	if err != nil {
+		// клиент закрывается
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+		if r.healthy.Swap(false) {
+			s.log.Warn("replica is unavailable, reads fall back to the primary", slog.String("host", host), sl.Err(err))
+		}
+		return
+	}
+
+	lagDuration := time.Duration(lag * float64(time.Second))
+	if lagDuration > s.maxLag {
+		if r.healthy.Swap(false) {
+			s.log.Warn("replica lags behind the primary, reads fall back to the primary", slog.String("host", host), slog.Duration("lag", lagDuration))
+		}
+		return
+	}
+
+	if !r.healthy.Swap(true) {
+		s.log.Info("replica is available for reads", slog.String("host", host), slog.Duration("lag", lagDuration))
+	}
+}
+
+// pick returns the next healthy replica in round-robin order or nil when none is healthy.
+func (s *replicaSet) pick() *replica {
+	n := len(s.replicas)
+	start := s.next.Add(1)
+
+	for i := 0; i < n; i++ {
+		r := s.replicas[(start+uint64(i))%uint64(n)]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// fail takes the replica out of rotation until the next successful check.
+func (s *replicaSet) fail(r *replica, err error) {
+	if r.healthy.Swap(false) {
+		s.log.Warn("replica query failed, reads fall back to the primary", slog.String("host", r.pool.Config().ConnConfig.Host), sl.Err(err))
+	}
+}
+
+func (s *replicaSet) close() {
+	for _, r := range s.replicas {
+		r.pool.Close()
+	}
+}
+
+// connectionFailed reports whether the query did not reach the replica, so that it can be repeated on the primary.
+func connectionFailed(err error) bool {
+	return errors.As(err, new(*pgconn.ConnectError)) || pgconn.SafeToRetry(err)
+}
@@ -0,0 +1,43 @@
+package transaction
+
+import (
+	"go.opentelemetry.io/otel/metric"
+)
+
+type metrics struct {
+	retries   metric.Int64Counter
+	retried   metric.Int64Counter
+	exhausted metric.Int64Counter
+}
+
+func newMetrics(meter metric.Meter) (*metrics, error) {
+	retries, err := meter.Int64Counter(
+		"db.tx.retries",
+		metric.WithDescription("Number of transaction retries by isolation level and SQLSTATE of the failure"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	retried, err := meter.Int64Counter(
+		"db.tx.retried",
+		metric.WithDescription("Number of transactions that succeeded after being retried"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	exhausted, err := meter.Int64Counter(
+		"db.tx.retries.exhausted",
+		metric.WithDescription("Number of transactions that kept failing until the attempts ran out"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metrics{
+		retries:   retries,
+		retried:   retried,
+		exhausted: exhausted,
+	}, nil
+}
@@ -0,0 +1,90 @@
+package transaction
+
+import (
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"log/slog"
+	"math/rand"
+	"time"
+
+	pgx "github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func (m *manager) RepeatableRead(ctx context.Context, f db.Handler) error {
+	txOpts := pgx.TxOptions{IsoLevel: pgx.RepeatableRead}
+	return m.retry(ctx, txOpts, f)
+}
+
+func (m *manager) Serializable(ctx context.Context, f db.Handler) error {
+	txOpts := pgx.TxOptions{IsoLevel: pgx.Serializable}
+	return m.retry(ctx, txOpts, f)
+}
+
+// retry runs the handler in a transaction again while it fails on a serialization failure or a deadlock,
+// up to the maximum number of attempts. A nested transaction is not retried on its own: such a failure
+// invalidates the outer transaction, which is retried as a whole if it was started in a retrying mode.
+func (m *manager) retry(ctx context.Context, opts pgx.TxOptions, fn db.Handler) error {
+	const op = "transaction.manager.retry"
+
+	if _, ok := ctx.Value(db.TxKey).(pgx.Tx); ok {
+		return m.transaction(ctx, opts, fn)
+	}
+
+	isolation := attribute.String("isolation", string(opts.IsoLevel))
+
+	for attempt := 1; ; attempt++ {
+		failure := &db.TxFailure{}
+		err := m.transaction(context.WithValue(ctx, db.TxFailureKey, failure), opts, fn)
+		if err == nil {
+			if attempt > 1 {
+				m.metrics.retried.Add(ctx, 1, metric.WithAttributes(isolation))
+			}
+			return nil
+		}
+
+		// ошибку запроса репозиторий мог заменить своей, ошибка коммита возвращается как есть
+		code := failure.Code()
+		if c, ok := db.RetryableCode(err); ok {
+			code = c
+		}
+		if code == "" {
+			return err
+		}
+
+		if attempt >= m.maxAttempts {
+			m.metrics.exhausted.Add(ctx, 1, metric.WithAttributes(isolation, attribute.String("code", code)))
+			m.log.Error("transaction failed after retries", sl.Err(err), slog.String("op", op), slog.Int("attempts", attempt), slog.String("code", code))
+			return err
+		}
+
+		m.metrics.retries.Add(ctx, 1, metric.WithAttributes(isolation, attribute.String("code", code)))
+		m.log.Warn("retrying transaction", slog.String("op", op), slog.Int("attempt", attempt), slog.String("code", code))
+
+		timer := time.NewTimer(m.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff doubles the delay with each attempt and picks a random one from its upper half,
+// so that transactions that conflicted with each other do not collide again.
+func (m *manager) backoff(attempt int) time.Duration {
+	delay := m.retryDelay
+	for i := 1; i < attempt && delay < m.maxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, m.maxRetryDelay)
+
+	if delay <= 1 {
+		return delay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
@@ -1,20 +1,47 @@
 package transaction
 
 import (
+	"booking-schedule/internal/logger/sl"
 	"booking-schedule/internal/pkg/db"
 	"context"
+	"log/slog"
+	"time"
 
 	pgx "github.com/jackc/pgx/v5"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
 )
 
 type manager struct {
-	db db.Transactor
+	db      db.Transactor
+	log     *slog.Logger
+	metrics *metrics
+	// Транзакции RepeatableRead и Serializable выполняются не более maxAttempts раз с паузами
+	// от retryDelay, удваивающимися до maxRetryDelay
+	maxAttempts   int
+	retryDelay    time.Duration
+	maxRetryDelay time.Duration
 }
 
-func NewTransactionManager(db db.Transactor) db.TxManager {
+func NewTransactionManager(db db.Transactor, log *slog.Logger, meter metric.Meter, maxAttempts int, retryDelay time.Duration, maxRetryDelay time.Duration) db.TxManager {
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("transaction")
+	}
+
+	m, err := newMetrics(meter)
+	if err != nil {
+		log.Error("failed to create transaction metrics, falling back to noop meter", sl.Err(err))
+		m, _ = newMetrics(noop.NewMeterProvider().Meter("transaction")) //nolint:errcheck
+	}
+
 	return &manager{
-		db: db,
+		db:            db,
+		log:           log,
+		metrics:       m,
+		maxAttempts:   max(maxAttempts, 1),
+		retryDelay:    retryDelay,
+		maxRetryDelay: max(maxRetryDelay, retryDelay),
 	}
 }
 
@@ -23,16 +50,21 @@ func MakeContextTx(ctx context.Context, tx pgx.Tx) context.Context {
 }
 
 func (m *manager) transaction(ctx context.Context, opts pgx.TxOptions, fn db.Handler) (err error) {
-	// Если это вложенная транзакция, пропускаем инициацию новой транзакции и выполняем обработчик.
-	tx, ok := ctx.Value(db.TxKey).(pgx.Tx)
-	if ok {
-		return fn(ctx)
-	}
+	var tx pgx.Tx
 
-	// Стартуем новую транзакцию.
-	tx, err = m.db.BeginTx(ctx, opts)
-	if err != nil {
-		return errors.Wrap(err, "can't begin transaction")
+	if outer, ok := ctx.Value(db.TxKey).(pgx.Tx); ok {
+		// Если это вложенная транзакция, создаем точку сохранения во внешней: откат отменит только
+		// изменения обработчика, а коммит освободит точку сохранения. Уровень изоляции задает внешняя транзакция.
+		tx, err = outer.Begin(ctx)
+		if err != nil {
+			return errors.Wrap(err, "can't create savepoint")
+		}
+	} else {
+		// Стартуем новую транзакцию.
+		tx, err = m.db.BeginTx(ctx, opts)
+		if err != nil {
+			return errors.Wrap(err, "can't begin transaction")
+		}
 	}
 
 	// Кладем транзакцию в контекст.
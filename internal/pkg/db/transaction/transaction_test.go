@@ -0,0 +1,255 @@
+package transaction
+
+import (
+	"booking-schedule/internal/pkg/db"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	pgx "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var errHandler = errors.New("handler failed")
+
+// fakeStore keeps the rows written by committed transactions.
+type fakeStore struct {
+	mu        sync.Mutex
+	committed []string
+	// Сколько транзакций начато через BeginTx, точки сохранения не считаются
+	begun int
+	// Ошибки, которые возвращают коммиты очередных транзакций верхнего уровня
+	commitErrs []error
+}
+
+func (s *fakeStore) BeginTx(_ context.Context, _ pgx.TxOptions) (pgx.Tx, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.begun++
+
+	return &fakeTx{store: s}, nil
+}
+
+func (s *fakeStore) rows() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.committed)
+}
+
+// fakeTx buffers the rows written through it until commit, a nested one is a savepoint of its parent.
+type fakeTx struct {
+	pgx.Tx
+
+	store   *fakeStore
+	parent  *fakeTx
+	pending []string
+	closed  bool
+}
+
+func (tx *fakeTx) Begin(_ context.Context) (pgx.Tx, error) {
+	return &fakeTx{store: tx.store, parent: tx}, nil
+}
+
+func (tx *fakeTx) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	if tx.closed {
+		return pgconn.CommandTag{}, pgx.ErrTxClosed
+	}
+	tx.pending = append(tx.pending, sql)
+
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+
+func (tx *fakeTx) Commit(_ context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+
+	// освобождение точки сохранения переносит ее строки во внешнюю транзакцию
+	if tx.parent != nil {
+		tx.parent.pending = append(tx.parent.pending, tx.pending...)
+		return nil
+	}
+
+	tx.store.mu.Lock()
+	defer tx.store.mu.Unlock()
+
+	if len(tx.store.commitErrs) > 0 {
+		err := tx.store.commitErrs[0]
+		tx.store.commitErrs = tx.store.commitErrs[1:]
+		if err != nil {
+			return err
+		}
+	}
+	tx.store.committed = append(tx.store.committed, tx.pending...)
+
+	return nil
+}
+
+func (tx *fakeTx) Rollback(_ context.Context) error {
+	if tx.closed {
+		return pgx.ErrTxClosed
+	}
+	tx.closed = true
+	tx.pending = nil
+
+	return nil
+}
+
+// write stands for a repository query, it runs on the transaction of the context the way db.DB does.
+func write(ctx context.Context, row string) error {
+	tx, ok := ctx.Value(db.TxKey).(pgx.Tx)
+	if !ok {
+		return errors.New("no transaction in context")
+	}
+
+	_, err := tx.Exec(ctx, row)
+
+	return err
+}
+
+func newTestManager(store *fakeStore) db.TxManager {
+	return NewTransactionManager(store, slog.New(slog.NewTextHandler(io.Discard, nil)), nil, 3, 0, 0)
+}
+
+func TestCommitKeepsWrites(t *testing.T) {
+	store := &fakeStore{}
+	txManager := newTestManager(store)
+
+	err := txManager.ReadCommitted(context.Background(), func(ctx context.Context) error {
+		if err := write(ctx, "a"); err != nil {
+			return err
+		}
+		return write(ctx, "b")
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+
+	if got := store.rows(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("committed rows are %v, want [a b]", got)
+	}
+}
+
+func TestRollbackUndoesWrites(t *testing.T) {
+	store := &fakeStore{}
+	txManager := newTestManager(store)
+
+	err := txManager.ReadCommitted(context.Background(), func(ctx context.Context) error {
+		if err := write(ctx, "a"); err != nil {
+			return err
+		}
+		return errHandler
+	})
+	if !errors.Is(err, errHandler) {
+		t.Fatalf("transaction returned %v, want %v", err, errHandler)
+	}
+
+	if got := store.rows(); len(got) != 0 {
+		t.Errorf("committed rows are %v, want none", got)
+	}
+}
+
+func TestPanicRollsBack(t *testing.T) {
+	store := &fakeStore{}
+	txManager := newTestManager(store)
+
+	err := txManager.ReadCommitted(context.Background(), func(ctx context.Context) error {
+		if err := write(ctx, "a"); err != nil {
+			return err
+		}
+		panic("boom")
+	})
+	if err == nil {
+		t.Fatal("transaction succeeded after a panic")
+	}
+
+	if got := store.rows(); len(got) != 0 {
+		t.Errorf("committed rows are %v, want none", got)
+	}
+}
+
+func TestNestedRollbackUndoesOnlySavepoint(t *testing.T) {
+	store := &fakeStore{}
+	txManager := newTestManager(store)
+
+	err := txManager.Serializable(context.Background(), func(ctx context.Context) error {
+		if err := write(ctx, "outer"); err != nil {
+			return err
+		}
+
+		errNested := txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+			if err := write(ctx, "nested"); err != nil {
+				return err
+			}
+			return errHandler
+		})
+		if !errors.Is(errNested, errHandler) {
+			t.Errorf("nested transaction returned %v, want %v", errNested, errHandler)
+		}
+
+		return write(ctx, "after")
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+
+	if got := store.rows(); !slices.Equal(got, []string{"outer", "after"}) {
+		t.Errorf("committed rows are %v, want [outer after]", got)
+	}
+	if store.begun != 1 {
+		t.Errorf("began %d transactions, want 1 with a savepoint", store.begun)
+	}
+}
+
+func TestOuterRollbackUndoesNestedWrites(t *testing.T) {
+	store := &fakeStore{}
+	txManager := newTestManager(store)
+
+	err := txManager.ReadCommitted(context.Background(), func(ctx context.Context) error {
+		err := txManager.ReadCommitted(ctx, func(ctx context.Context) error {
+			return write(ctx, "nested")
+		})
+		if err != nil {
+			return err
+		}
+
+		return errHandler
+	})
+	if !errors.Is(err, errHandler) {
+		t.Fatalf("transaction returned %v, want %v", err, errHandler)
+	}
+
+	if got := store.rows(); len(got) != 0 {
+		t.Errorf("committed rows are %v, want none", got)
+	}
+}
+
+func TestSerializationFailureRetriesFromScratch(t *testing.T) {
+	store := &fakeStore{
+		commitErrs: []error{&pgconn.PgError{Code: db.CodeSerializationFailure}},
+	}
+	txManager := newTestManager(store)
+
+	attempts := 0
+	err := txManager.Serializable(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return write(ctx, "a")
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("handler ran %d times, want 2", attempts)
+	}
+	if got := store.rows(); !slices.Equal(got, []string{"a"}) {
+		t.Errorf("committed rows are %v, want [a]", got)
+	}
+}
@@ -0,0 +1,328 @@
+package jobs
+
+import (
+	"booking-schedule/internal/logger/sl"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	outcomeSucceeded = "succeeded"
+	outcomeFailed    = "failed"
+	outcomeTimedOut  = "timed_out"
+	outcomeSkipped   = "skipped"
+
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrJobExists   = errors.New("job with this name is already registered")
+	ErrJobRunning  = errors.New("job is already running")
+	ErrNotStarted  = errors.New("job registry is not running")
+	ErrJobPanicked = errors.New("job panicked")
+	ErrInvalidJob  = errors.New("job should have a name, a schedule and a function to run")
+)
+
+// Job is a function run on a schedule. A run is cancelled once the timeout expires, zero timeout means no limit.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Timeout  time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Status describes a job and its latest run.
+type Status struct {
+	Name     string
+	Schedule string
+	Timeout  time.Duration
+	Running  bool
+	// Нулевые, пока задача не запускалась
+	LastStartedAt  time.Time
+	LastFinishedAt time.Time
+	LastDuration   time.Duration
+	LastTrigger    string
+	// Ошибка последнего запуска, пустая, если он завершился успешно
+	LastError string
+	NextRunAt time.Time
+	Runs      int64
+	Failures  int64
+}
+
+type entry struct {
+	job     Job
+	running atomic.Bool
+
+	mu     sync.Mutex
+	status Status
+}
+
+// Registry runs the registered jobs on their schedules. A job never runs concurrently with itself:
+// a run that comes due, or is triggered, while the previous one is still running is skipped.
+type Registry struct {
+	log     *slog.Logger
+	tracer  trace.Tracer
+	metrics *metrics
+
+	mu    sync.Mutex
+	jobs  map[string]*entry
+	names []string
+	// Контекст запущенного реестра, nil до вызова Run
+	ctx     context.Context
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func NewRegistry(log *slog.Logger, tracer trace.Tracer, meter metric.Meter) *Registry {
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("jobs")
+	}
+
+	m, err := newMetrics(meter)
+	if err != nil {
+		log.Error("failed to create job metrics, falling back to noop meter", sl.Err(err))
+		m, _ = newMetrics(noop.NewMeterProvider().Meter("jobs")) //nolint:errcheck
+	}
+
+	return &Registry{
+		log:     log,
+		tracer:  tracer,
+		metrics: m,
+		jobs:    make(map[string]*entry),
+	}
+}
+
+// Register adds the job to the registry, jobs registered after Run is called are not scheduled.
+func (r *Registry) Register(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return ErrInvalidJob
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.Name]; ok {
+		return fmt.Errorf("%w: %q", ErrJobExists, job.Name)
+	}
+
+	r.jobs[job.Name] = &entry{
+		job: job,
+		status: Status{
+			Name:     job.Name,
+			Schedule: job.Schedule.String(),
+			Timeout:  job.Timeout,
+		},
+	}
+	r.names = append(r.names, job.Name)
+
+	return nil
+}
+
+// Run schedules the registered jobs until the context is cancelled, then waits for the running ones
+// to return. Runs in progress are cancelled together with the context.
+func (r *Registry) Run(ctx context.Context) {
+	const op = "jobs.Registry.Run"
+
+	log := r.log.With(
+		slog.String("op", op),
+	)
+
+	r.mu.Lock()
+	r.ctx = ctx
+	entries := make([]*entry, 0, len(r.names))
+	for _, name := range r.names {
+		entries = append(entries, r.jobs[name])
+	}
+	r.mu.Unlock()
+
+	log.Info("job registry initiated", slog.Int("jobs", len(entries)))
+
+	for _, e := range entries {
+		r.wg.Add(1)
+		go func(e *entry) {
+			defer r.wg.Done()
+			r.schedule(ctx, e)
+		}(e)
+	}
+
+	<-ctx.Done()
+
+	// после этого запуски вручную отклоняются, и ожидание не пропустит новых
+	r.mu.Lock()
+	r.stopped = true
+	r.mu.Unlock()
+
+	r.wg.Wait()
+
+	log.Info("job registry stopped")
+}
+
+// schedule starts the job each time it comes due.
+func (r *Registry) schedule(ctx context.Context, e *entry) {
+	for {
+		next := e.job.Schedule.Next(time.Now())
+
+		e.mu.Lock()
+		e.status.NextRunAt = next
+		e.mu.Unlock()
+
+		if next.IsZero() {
+			r.log.Warn("job will never run again", slog.String("job", e.job.Name))
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			r.start(ctx, e, TriggerSchedule) //nolint:errcheck
+		}
+	}
+}
+
+// Trigger starts the job out of its schedule. The run is not bound to the caller's context,
+// Trigger returns as soon as it starts.
+func (r *Registry) Trigger(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.jobs[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrJobNotFound, name)
+	}
+	if r.ctx == nil || r.stopped {
+		return ErrNotStarted
+	}
+
+	return r.start(r.ctx, e, TriggerManual)
+}
+
+// Jobs returns the statuses of the registered jobs in the order of registration.
+func (r *Registry) Jobs() []Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make([]Status, 0, len(r.names))
+	for _, name := range r.names {
+		e := r.jobs[name]
+
+		e.mu.Lock()
+		status := e.status
+		e.mu.Unlock()
+
+		status.Running = e.running.Load()
+		res = append(res, status)
+	}
+
+	return res
+}
+
+func (r *Registry) start(ctx context.Context, e *entry, trigger string) error {
+	if !e.running.CompareAndSwap(false, true) {
+		r.metrics.skip(ctx, e.job.Name)
+		r.log.Warn("job run skipped, previous run is still in progress", slog.String("job", e.job.Name), slog.String("trigger", trigger))
+		return fmt.Errorf("%w: %q", ErrJobRunning, e.job.Name)
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer e.running.Store(false)
+
+		r.execute(ctx, e, trigger)
+	}()
+
+	return nil
+}
+
+func (r *Registry) execute(ctx context.Context, e *entry, trigger string) {
+	const op = "jobs.Registry.execute"
+
+	log := r.log.With(
+		slog.String("op", op),
+		slog.String("job", e.job.Name),
+		slog.String("trigger", trigger),
+	)
+	ctx, span := r.tracer.Start(ctx, "job "+e.job.Name, trace.WithAttributes(
+		attribute.String("job", e.job.Name),
+		attribute.String("trigger", trigger),
+	))
+	defer span.End()
+
+	if e.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.job.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+
+	e.mu.Lock()
+	e.status.LastStartedAt = start
+	e.status.LastTrigger = trigger
+	e.mu.Unlock()
+
+	r.metrics.running.Add(ctx, 1, metric.WithAttributes(attribute.String("job", e.job.Name)))
+	defer r.metrics.running.Add(ctx, -1, metric.WithAttributes(attribute.String("job", e.job.Name)))
+
+	log.Debug("job started")
+
+	err := run(ctx, e.job.Run)
+	elapsed := time.Since(start)
+
+	outcome := outcomeSucceeded
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		outcome = outcomeTimedOut
+	case err != nil:
+		outcome = outcomeFailed
+	}
+
+	r.metrics.record(context.WithoutCancel(ctx), e.job.Name, outcome, elapsed)
+
+	e.mu.Lock()
+	e.status.LastFinishedAt = time.Now()
+	e.status.LastDuration = elapsed
+	e.status.LastError = ""
+	e.status.Runs++
+	if err != nil {
+		e.status.LastError = err.Error()
+		e.status.Failures++
+	}
+	e.mu.Unlock()
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		log.Error("job failed", sl.Err(err), slog.String("outcome", outcome), slog.Duration("duration", elapsed))
+		return
+	}
+
+	log.Debug("job finished", slog.Duration("duration", elapsed))
+}
+
+// run calls fn, a panic is reported as an error so that it does not take down the other jobs.
+func run(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%w: %v", ErrJobPanicked, p)
+		}
+	}()
+
+	return fn(ctx)
+}
@@ -0,0 +1,59 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type metrics struct {
+	runs     metric.Int64Counter
+	duration metric.Float64Histogram
+	running  metric.Int64UpDownCounter
+}
+
+func newMetrics(meter metric.Meter) (*metrics, error) {
+	runs, err := meter.Int64Counter(
+		"scheduler.jobs.runs",
+		metric.WithDescription("Number of job runs by job and outcome"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, err := meter.Float64Histogram(
+		"scheduler.jobs.duration",
+		metric.WithUnit("ms"),
+		metric.WithDescription("Measures the duration of job runs"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	running, err := meter.Int64UpDownCounter(
+		"scheduler.jobs.running",
+		metric.WithDescription("Number of jobs being run"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metrics{
+		runs:     runs,
+		duration: duration,
+		running:  running,
+	}, nil
+}
+
+func (m *metrics) record(ctx context.Context, job string, outcome string, elapsed time.Duration) {
+	attrs := metric.WithAttributes(attribute.String("job", job), attribute.String("outcome", outcome))
+
+	m.runs.Add(ctx, 1, attrs)
+	m.duration.Record(ctx, float64(elapsed.Milliseconds()), attrs)
+}
+
+func (m *metrics) skip(ctx context.Context, job string) {
+	m.runs.Add(ctx, 1, metric.WithAttributes(attribute.String("job", job), attribute.String("outcome", outcomeSkipped)))
+}
@@ -0,0 +1,220 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSchedule = errors.New("invalid job schedule")
+
+// Schedule tells when a job runs next.
+type Schedule interface {
+	// Next returns the first moment after t the job runs at, or zero time if it never runs again.
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// ParseSchedule parses an interval such as "@every 1m", one of the shortcuts "@hourly", "@daily",
+// "@weekly" and "@monthly" or a cron expression of five fields: minute, hour, day of month,
+// month and day of week. Cron expressions are evaluated in the local time of the process.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if interval, ok := strings.CutPrefix(spec, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: %q: interval should be a positive duration", ErrInvalidSchedule, spec)
+		}
+
+		return &every{interval: d}, nil
+	}
+
+	expr := spec
+	switch spec {
+	case "@hourly":
+		expr = "0 * * * *"
+	case "@daily":
+		expr = "0 0 * * *"
+	case "@weekly":
+		expr = "0 0 * * 0"
+	case "@monthly":
+		expr = "0 0 1 * *"
+	}
+
+	return parseCron(spec, expr)
+}
+
+// every runs a job at a fixed interval from the moment the previous run was scheduled.
+type every struct {
+	interval time.Duration
+}
+
+func (e *every) Next(t time.Time) time.Time {
+	return t.Add(e.interval)
+}
+
+func (e *every) String() string {
+	return "@every " + e.interval.String()
+}
+
+// cron matches moments by the bit sets of the minutes, hours, days, months and weekdays allowed.
+type cron struct {
+	spec   string
+	minute uint64
+	hour   uint64
+	dom    uint64
+	month  uint64
+	dow    uint64
+	anyDom bool
+	anyDow bool
+}
+
+type cronField struct {
+	min, max int
+}
+
+var cronFields = [5]cronField{
+	{0, 59}, // минуты
+	{0, 23}, // часы
+	{1, 31}, // дни месяца
+	{1, 12}, // месяцы
+	{0, 7},  // дни недели, воскресенье обозначается как 0 или 7
+}
+
+func parseCron(spec string, expr string) (*cron, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != len(cronFields) {
+		return nil, fmt.Errorf("%w: %q: expected 5 fields, got %d", ErrInvalidSchedule, spec, len(fields))
+	}
+
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q: %s", ErrInvalidSchedule, spec, err)
+		}
+		sets[i] = set
+	}
+
+	// 7 и 0 обозначают воскресенье
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &cron{
+		spec:   spec,
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		anyDom: fields[2] == "*",
+		anyDow: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a comma separated list of values, ranges and "*", each optionally with a "/step".
+func parseCronField(field string, bounds cronField) (uint64, error) {
+	var set uint64
+
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+		}
+
+		lo, hi := bounds.min, bounds.max
+		if rng != "*" {
+			loStr, hiStr, isRange := strings.Cut(rng, "-")
+
+			var err error
+			lo, err = strconv.Atoi(loStr)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if isRange {
+				hi, err = strconv.Atoi(hiStr)
+				if err != nil {
+					return 0, fmt.Errorf("invalid value %q", part)
+				}
+			} else if hasStep {
+				// "5/15" означает каждые 15, начиная с 5
+				hi = bounds.max
+			}
+		}
+
+		if lo < bounds.min || hi > bounds.max || lo > hi {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, bounds.min, bounds.max)
+		}
+
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+
+	return set, nil
+}
+
+// cronHorizon limits the search for the next run of expressions that never match, e.g. "0 0 30 2 *".
+const cronHorizon = 5 * 366 * 24 * time.Hour
+
+func (c *cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	limit := t.Add(cronHorizon)
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+
+	for t.Before(limit) {
+		if c.month&(1<<int(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<t.Hour()) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<t.Minute()) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchDay follows cron: if both day of month and day of week are restricted, a day matching either runs the job.
+func (c *cron) matchDay(t time.Time) bool {
+	dom := c.dom&(1<<t.Day()) != 0
+	dow := c.dow&(1<<int(t.Weekday())) != 0
+
+	switch {
+	case c.anyDom && c.anyDow:
+		return true
+	case c.anyDom:
+		return dow
+	case c.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+func (c *cron) String() string {
+	return c.spec
+}
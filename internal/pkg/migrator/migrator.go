@@ -0,0 +1,162 @@
+package migrator
+
+import (
+	"booking-schedule/deploy/migrations"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/lock"
+)
+
+// Команды подкоманды migrate
+const (
+	CommandUp     = "up"
+	CommandDown   = "down"
+	CommandStatus = "status"
+	CommandCreate = "create"
+)
+
+var (
+	ErrUnknownCommand = errors.New("unknown migrate command, expected up, down, status or create")
+	ErrNoName         = errors.New("migrate create needs the name of the migration")
+)
+
+// Migrator applies the embedded migrations. Up and down take a postgres advisory lock for the
+// session, so that several instances started at once apply the migrations one after another.
+type Migrator struct {
+	provider *goose.Provider
+}
+
+func NewMigrator(config *pgxpool.Config) (*Migrator, error) {
+	db := stdlib.OpenDB(*config.ConnConfig)
+
+	locker, err := lock.NewPostgresSessionLocker()
+	if err != nil {
+		db.Close() //nolint:errcheck
+		return nil, err
+	}
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, migrations.FS, goose.WithSessionLocker(locker))
+	if err != nil {
+		db.Close() //nolint:errcheck
+		return nil, err
+	}
+
+	return &Migrator{
+		provider: provider,
+	}, nil
+}
+
+// Up applies the pending migrations.
+func (m *Migrator) Up(ctx context.Context) ([]*goose.MigrationResult, error) {
+	return m.provider.Up(ctx)
+}
+
+// Down rolls back the last applied migration, goose.ErrNoNextVersion means there is none.
+func (m *Migrator) Down(ctx context.Context) (*goose.MigrationResult, error) {
+	return m.provider.Down(ctx)
+}
+
+// Status lists the embedded migrations along with the time they were applied at.
+func (m *Migrator) Status(ctx context.Context) ([]*goose.MigrationStatus, error) {
+	return m.provider.Status(ctx)
+}
+
+func (m *Migrator) Close() error {
+	return m.provider.Close()
+}
+
+// Create writes an empty sql migration named after the current time to dir. The migrations are embedded
+// into the binaries, so the new one is applied once the services are rebuilt.
+func Create(dir string, name string) error {
+	if name == "" {
+		return ErrNoName
+	}
+
+	return goose.Create(nil, dir, name, "sql")
+}
+
+// RunCommand runs the migrate subcommand of a service binary: up, down and status against the database
+// described by config, create in the dir directory.
+func RunCommand(ctx context.Context, out io.Writer, config *pgxpool.Config, dir string, command string, args []string) error {
+	switch command {
+	case CommandUp, CommandDown, CommandStatus:
+	case CommandCreate:
+		var name string
+		if len(args) > 0 {
+			name = args[0]
+		}
+		return Create(dir, name)
+	default:
+		return ErrUnknownCommand
+	}
+
+	m, err := NewMigrator(config)
+	if err != nil {
+		return err
+	}
+	defer m.Close() //nolint:errcheck
+
+	switch command {
+	case CommandUp:
+		return up(ctx, out, m)
+	case CommandDown:
+		return down(ctx, out, m)
+	default:
+		return status(ctx, out, m)
+	}
+}
+
+func up(ctx context.Context, out io.Writer, m *Migrator) error {
+	results, err := m.Up(ctx)
+	for _, res := range results {
+		fmt.Fprintln(out, res)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintln(out, "no pending migrations")
+	}
+
+	return nil
+}
+
+func down(ctx context.Context, out io.Writer, m *Migrator) error {
+	res, err := m.Down(ctx)
+	if err != nil {
+		if errors.Is(err, goose.ErrNoNextVersion) {
+			fmt.Fprintln(out, "no migrations to roll back")
+			return nil
+		}
+		return err
+	}
+
+	fmt.Fprintln(out, res)
+
+	return nil
+}
+
+func status(ctx context.Context, out io.Writer, m *Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range statuses {
+		appliedAt := "pending"
+		if st.State == goose.StateApplied {
+			appliedAt = st.AppliedAt.Format("2006-01-02T15:04:05Z07:00")
+		}
+		fmt.Fprintf(out, "%-25s %s\n", appliedAt, filepath.Base(st.Source.Path))
+	}
+
+	return nil
+}
@@ -2,17 +2,35 @@ package observability
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	jaegerPropagator "go.opentelemetry.io/contrib/propagators/jaeger"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
-func NewTracer(ctx context.Context, otlpEndpoint string, svcName string, samplingRate float64) (trace.Tracer, error) {
+const (
+	PropagatorW3C          = "w3c"
+	PropagatorTraceContext = "tracecontext"
+	PropagatorBaggage      = "baggage"
+	PropagatorJaeger       = "jaeger"
+)
+
+var ErrUnknownPropagator = errors.New("unknown trace propagator")
+
+func NewTracer(ctx context.Context, otlpEndpoint string, svcName string, samplingRate float64, propagators string) (trace.Tracer, error) {
+	propagator, err := NewPropagator(propagators)
+	if err != nil {
+		return nil, err
+	}
+
 	traceExporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(otlpEndpoint))
 	if err != nil {
 		return nil, err
@@ -28,7 +46,30 @@ func NewTracer(ctx context.Context, otlpEndpoint string, svcName string, samplin
 	)
 
 	otel.SetTracerProvider(tp)
-	otel.SetTextMapPropagator(jaegerPropagator.Jaeger{})
+	otel.SetTextMapPropagator(propagator)
 
 	return otel.Tracer(svcName), nil
 }
+
+// NewPropagator builds a propagator out of a comma separated list of formats: w3c (tracecontext and baggage),
+// tracecontext, baggage or jaeger. Every listed format is both injected and extracted.
+func NewPropagator(propagators string) (propagation.TextMapPropagator, error) {
+	var res []propagation.TextMapPropagator
+
+	for _, name := range strings.Split(propagators, ",") {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case PropagatorW3C:
+			res = append(res, propagation.TraceContext{}, propagation.Baggage{})
+		case PropagatorTraceContext:
+			res = append(res, propagation.TraceContext{})
+		case PropagatorBaggage:
+			res = append(res, propagation.Baggage{})
+		case PropagatorJaeger:
+			res = append(res, jaegerPropagator.Jaeger{})
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPropagator, name)
+		}
+	}
+
+	return propagation.NewCompositeTextMapPropagator(res...), nil
+}
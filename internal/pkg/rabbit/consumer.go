@@ -2,86 +2,410 @@ package rabbit
 
 import (
 	"booking-schedule/internal/config"
-	"io"
+	"booking-schedule/internal/pkg/broker"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/streadway/amqp"
 )
 
 const consumerName = "sender"
 
-type Consumer interface {
-	Consume() (<-chan amqp.Delivery, error)
-	Close() error
-}
-
 type consumer struct {
-	connection *amqp.Connection
-	channel    *amqp.Channel
-	queue      *amqp.Queue
-	queueName  string
+	session   *session
+	queueName string
+	// prefetch ограничивает количество неподтвержденных сообщений, доставленных получателю
+	prefetch int
+
+	maxRetries     int
+	retryDelay     time.Duration
+	confirmTimeout time.Duration
 
-	closeFuncs []io.Closer
+	// Сообщения перекладываются в другие очереди через отдельный канал в режиме подтверждений,
+	// исходное сообщение подтверждается только после подтверждения брокером новой публикации
+	publishMu sync.Mutex
+	publishCh *amqp.Channel
+	confirms  chan amqp.Confirmation
+	closed    chan *amqp.Error
+
+	cancelled  chan struct{}
+	cancelOnce sync.Once
 }
 
-func NewConsumer(config *config.RabbitConsumer) (Consumer, error) {
-	closeFuncs := make([]io.Closer, 0, 2)
-	conn, err := amqp.Dial(config.DSN)
+func NewConsumer(config *config.RabbitConsumer, log *slog.Logger) (broker.Consumer, error) {
+	sess, err := newSession(config.DSN, config.ReconnectDelay, config.MaxReconnectDelay, log, func(ch *amqp.Channel) error {
+		_, err := declareQueue(ch, config.QueueName)
+		if err != nil {
+			return err
+		}
+
+		err = declareDeadLetter(ch, config.QueueName)
+		if err != nil {
+			return err
+		}
+
+		return declareRetryQueues(ch, config.QueueName, config.MaxRetries, config.RetryDelay)
+	})
 	if err != nil {
 		return nil, err
 	}
-	closeFuncs = append(closeFuncs, conn)
 
-	ch, err := conn.Channel()
+	return &consumer{
+		session:        sess,
+		queueName:      config.QueueName,
+		prefetch:       config.Prefetch,
+		maxRetries:     config.MaxRetries,
+		retryDelay:     config.RetryDelay,
+		confirmTimeout: config.ConfirmTimeout,
+		cancelled:      make(chan struct{}),
+	}, nil
+}
+
+// Consume returns a channel that outlives broker reconnections: after the session is restored
+// consuming is resumed on the new channel. The returned channel is closed only by Close.
+func (c *consumer) Consume() (<-chan *broker.Delivery, error) {
+	ch, err := c.session.Channel()
 	if err != nil {
 		return nil, err
 	}
-	closeFuncs = append(closeFuncs, ch)
 
-	q, err := ch.QueueDeclare(
-		config.QueueName, // name
-		true,             // durable
-		false,            // autodelete
-		false,            // exclusive
-		false,            // nowait
-		nil,              // args
-	)
+	msgChan, err := c.consume(ch)
 	if err != nil {
 		return nil, err
 	}
 
-	return &consumer{
-		connection: conn,
-		channel:    ch,
-		queue:      &q,
-		queueName:  config.QueueName,
-		closeFuncs: closeFuncs,
-	}, nil
+	out := make(chan *broker.Delivery)
+	go c.forward(msgChan, out)
+
+	return out, nil
 }
 
-func (c *consumer) Consume() (<-chan amqp.Delivery, error) {
-	msgChan, err := c.channel.Consume(
+// consume sets the prefetch of the channel before consuming, the setting is lost with the channel.
+// The consumer is not exclusive, so that several senders share the queue.
+func (c *consumer) consume(ch *amqp.Channel) (<-chan amqp.Delivery, error) {
+	err := ch.Qos(c.prefetch, 0, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return ch.Consume(
 		c.queueName,
 		consumerName,
 		false, // autoAck
-		true,  // exclusive
+		false, // exclusive
 		false, // noLocal
 		false, // noWait
 		nil,
 	)
+}
+
+func (c *consumer) forward(msgChan <-chan amqp.Delivery, out chan<- *broker.Delivery) {
+	defer close(out)
+
+	for {
+		for msg := range msgChan {
+			select {
+			case out <- toDelivery(msg):
+			case <-c.session.Done():
+				return
+			}
+		}
+
+		// канал доставки закрывается и после отмены получения, тогда переподключаться не нужно
+		if c.isCancelled() {
+			return
+		}
+
+		// канал доставки закрывается при потере соединения, ждем переподключения
+		for {
+			ch, err := c.session.WaitChannel()
+			if err != nil || c.isCancelled() {
+				return
+			}
+
+			msgChan, err = c.consume(ch)
+			if err == nil {
+				break
+			}
+
+			select {
+			case <-c.session.Done():
+				return
+			case <-c.cancelled:
+				return
+			case <-time.After(c.session.backoff(0)):
+			}
+		}
+	}
+}
+
+// Cancel stops consuming, the deliveries already buffered by the client are still passed on.
+func (c *consumer) Cancel() error {
+	c.cancelOnce.Do(func() {
+		close(c.cancelled)
+	})
+
+	ch, err := c.session.Channel()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return msgChan, nil
+	return ch.Cancel(consumerName, false)
 }
 
-func (c *consumer) Close() error {
-	for _, closeFunc := range c.closeFuncs {
-		err := closeFunc.Close()
+func (c *consumer) isCancelled() bool {
+	select {
+	case <-c.cancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Retry schedules one more delivery attempt for d by moving it to the delay queue of the next attempt.
+// d is acked only once the broker confirms the copy. If the copy is not confirmed d is put back to the work
+// queue, so that it is delivered again and does not hold a prefetch slot; a copy that was stored after all
+// is a duplicate the handler has to tolerate. Once the attempts are exhausted d is rejected and ends up
+// in the dead-letter queue.
+func (c *consumer) Retry(d *broker.Delivery) error {
+	ack, ok := d.Acknowledger().(*delivery)
+	if !ok {
+		return broker.ErrForeignDelivery
+	}
+	msg := ack.msg
+
+	attempt := retryCount(msg) + 1
+	if attempt > c.maxRetries {
+		err := msg.Nack(false, false)
 		if err != nil {
 			return err
 		}
+
+		return broker.ErrRetriesExhausted
+	}
+
+	headers := amqp.Table{}
+	for k, v := range msg.Headers {
+		headers[k] = v
+	}
+	headers[RetryCountHeader] = int32(attempt)
+
+	err := c.publishConfirmed(retryQueue(c.queueName, attempt), amqp.Publishing{
+		Headers:      headers,
+		DeliveryMode: amqp.Persistent,
+		ContentType:  msg.ContentType,
+		MessageId:    msg.MessageId,
+		Timestamp:    msg.Timestamp,
+		Body:         msg.Body,
+	})
+	if err != nil {
+		// без подтверждения копии исходное сообщение возвращается в очередь, иначе оно занимает место
+		// в prefetch до переподключения
+		if errNack := msg.Nack(false, true); errNack != nil {
+			return errors.Join(err, errNack)
+		}
+
+		return err
 	}
 
+	return msg.Ack(false)
+}
+
+// publishConfirmed publishes msg to the queue through the default exchange and waits until the broker
+// confirms it. The channel is dropped after a failure, so that a late confirmation is never taken
+// for the confirmation of the next message.
+func (c *consumer) publishConfirmed(queueName string, msg amqp.Publishing) error {
+	c.publishMu.Lock()
+	defer c.publishMu.Unlock()
+
+	err := c.openPublishChannel()
+	if err != nil {
+		return err
+	}
+
+	err = c.publishCh.Publish(exchangeName, queueName, false, false, msg)
+	if err != nil {
+		c.dropPublishChannel()
+		return err
+	}
+
+	timer := time.NewTimer(c.confirmTimeout)
+	defer timer.Stop()
+
+	select {
+	case confirm, ok := <-c.confirms:
+		if !ok {
+			c.dropPublishChannel()
+			return ErrChannelClosed
+		}
+		if !confirm.Ack {
+			return ErrNacked
+		}
+		return nil
+	case <-timer.C:
+		c.dropPublishChannel()
+		return ErrConfirmTimeout
+	}
+}
+
+func (c *consumer) openPublishChannel() error {
+	if c.publishCh != nil {
+		select {
+		case <-c.closed:
+			c.publishCh = nil
+		default:
+			return nil
+		}
+	}
+
+	ch, err := c.session.OpenChannel()
+	if err != nil {
+		return err
+	}
+
+	err = ch.Confirm(false)
+	if err != nil {
+		ch.Close() //nolint:errcheck
+		return err
+	}
+
+	c.publishCh = ch
+	c.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	c.closed = ch.NotifyClose(make(chan *amqp.Error, 1))
+
 	return nil
 }
+
+func (c *consumer) dropPublishChannel() {
+	c.publishCh.Close() //nolint:errcheck
+	c.publishCh = nil
+}
+
+// DeadLetters returns up to limit messages from the dead-letter queue without removing them.
+func (c *consumer) DeadLetters(limit int) ([]*broker.Delivery, error) {
+	ch, err := c.session.Channel()
+	if err != nil {
+		return nil, err
+	}
+
+	msgs := make([]amqp.Delivery, 0, limit)
+
+	for len(msgs) < limit {
+		msg, ok, err := ch.Get(deadLetterQueue(c.queueName), false)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	// возвращаем сообщения обратно в очередь, они лишь просматривались
+	res := make([]*broker.Delivery, 0, len(msgs))
+	for _, msg := range msgs {
+		err := msg.Nack(false, true)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, broker.NewDelivery(toMessage(msg), retryCount(msg), nil))
+	}
+
+	return res, nil
+}
+
+// Replay moves up to limit messages from the dead-letter queue back to the work queue
+// with a fresh retry budget and returns the number of messages moved.
+func (c *consumer) Replay(limit int) (int, error) {
+	ch, err := c.session.Channel()
+	if err != nil {
+		return 0, err
+	}
+
+	var replayed int
+
+	for replayed < limit {
+		msg, ok, err := ch.Get(deadLetterQueue(c.queueName), false)
+		if err != nil {
+			return replayed, err
+		}
+		if !ok {
+			break
+		}
+
+		headers := amqp.Table{}
+		for k, v := range msg.Headers {
+			if k == RetryCountHeader || k == "x-death" {
+				continue
+			}
+			headers[k] = v
+		}
+
+		err = c.publishConfirmed(c.queueName, amqp.Publishing{
+			Headers:      headers,
+			DeliveryMode: amqp.Persistent,
+			ContentType:  msg.ContentType,
+			MessageId:    msg.MessageId,
+			Timestamp:    msg.Timestamp,
+			Body:         msg.Body,
+		})
+		if err != nil {
+			msg.Nack(false, true) //nolint:errcheck
+			return replayed, err
+		}
+
+		err = msg.Ack(false)
+		if err != nil {
+			return replayed, err
+		}
+
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+func (c *consumer) Close() error {
+	return c.session.Close()
+}
+
+// delivery settles the AMQP delivery a broker.Delivery was made of.
+type delivery struct {
+	msg amqp.Delivery
+}
+
+func (d *delivery) Ack() error {
+	return d.msg.Ack(false)
+}
+
+func (d *delivery) Nack() error {
+	return d.msg.Nack(false, false)
+}
+
+func toDelivery(msg amqp.Delivery) *broker.Delivery {
+	return broker.NewDelivery(toMessage(msg), retryCount(msg), &delivery{msg: msg})
+}
+
+// toMessage keeps only the headers with string values, the rest are AMQP internals such as x-death.
+func toMessage(msg amqp.Delivery) broker.Message {
+	headers := make(map[string]string, len(msg.Headers))
+	for k, v := range msg.Headers {
+		if value, ok := v.(string); ok {
+			headers[k] = value
+		}
+	}
+
+	return broker.Message{
+		ID:          msg.MessageId,
+		Type:        msg.Type,
+		ContentType: msg.ContentType,
+		RoutingKey:  msg.RoutingKey,
+		Headers:     headers,
+		Timestamp:   msg.Timestamp,
+		Body:        msg.Body,
+	}
+}
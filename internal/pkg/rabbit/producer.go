@@ -1,96 +1,356 @@
 package rabbit
 
 import (
-	"io"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
 
 	"booking-schedule/internal/config"
+	"booking-schedule/internal/pkg/broker"
 
+	"github.com/gofrs/uuid"
 	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
+	instrumentationName = "booking-schedule/internal/pkg/rabbit"
+
 	exchangeName = ""
-	contentType  = "text/plain"
+	contentType  = "application/json"
+
+	// confirmBuffer should exceed the number of messages in flight within a batch,
+	// since an unread confirmation blocks the whole connection.
+	confirmBuffer = 1024
 )
 
-// Producer ...
-type Producer interface {
-	Publish(msg []byte) error
-	Close() error
-}
+var (
+	ErrNacked         = errors.New("broker failed to accept message")
+	ErrUnroutable     = errors.New("message could not be routed to any queue")
+	ErrConfirmTimeout = errors.New("timed out waiting for publish confirmation")
+	ErrChannelClosed  = errors.New("channel closed before publish was confirmed")
+)
 
 type producer struct {
-	connection *amqp.Connection
-	channel    *amqp.Channel
-	queue      *amqp.Queue
+	session *session
 
-	queueName string
+	// exchange пустой у продюсеров, публикующих в очередь через обменник по умолчанию
+	exchange       string
+	queueName      string
+	confirmTimeout time.Duration
+	// mandatory требует, чтобы сообщение попало хотя бы в одну очередь
+	mandatory bool
 
-	closeFuncs []io.Closer
+	// mu сериализует публикации, чтобы сопоставлять подтверждения по номерам доставки
+	mu       sync.Mutex
+	channel  *amqp.Channel
+	confirms chan amqp.Confirmation
+	returns  chan amqp.Return
+	nextTag  uint64
 }
 
 // NewProducer ...
-func NewProducer(config *config.RabbitProducer) (Producer, error) {
-	closeFuncs := make([]io.Closer, 0, 2)
+func NewProducer(config *config.RabbitProducer, log *slog.Logger) (broker.Producer, error) {
+	p := &producer{
+		queueName:      config.QueueName,
+		confirmTimeout: config.ConfirmTimeout,
+		mandatory:      true,
+	}
+
+	sess, err := newSession(config.DSN, config.ReconnectDelay, config.MaxReconnectDelay, log, func(ch *amqp.Channel) error {
+		_, err := declareQueue(ch, config.QueueName)
+		if err != nil {
+			return err
+		}
 
-	conn, err := amqp.Dial(config.DSN)
+		err = declareDeadLetter(ch, config.QueueName)
+		if err != nil {
+			return err
+		}
+
+		return p.setChannel(ch)
+	})
 	if err != nil {
 		return nil, err
 	}
-	closeFuncs = append(closeFuncs, conn)
 
-	ch, err := conn.Channel()
-	if err != nil {
-		return nil, err
+	p.session = sess
+
+	return p, nil
+}
+
+// NewTopicProducer creates a producer that publishes messages to the topic exchange by their routing keys.
+// Messages no queue is bound to are dropped by the broker, consumers declare and bind their own queues.
+func NewTopicProducer(config *config.RabbitPublisher, log *slog.Logger) (broker.Producer, error) {
+	p := &producer{
+		exchange:       config.Exchange,
+		confirmTimeout: config.ConfirmTimeout,
 	}
-	closeFuncs = append(closeFuncs, ch)
 
-	q, err := ch.QueueDeclare(
-		config.QueueName, // name
-		true,             // durable
-		false,            // autodelete
-		false,            // exclusive
-		false,            // nowait
-		nil,              // args
-	)
+	sess, err := newSession(config.DSN, config.ReconnectDelay, config.MaxReconnectDelay, log, func(ch *amqp.Channel) error {
+		err := declareTopicExchange(ch, config.Exchange)
+		if err != nil {
+			return err
+		}
+
+		return p.setChannel(ch)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &producer{
-		connection: conn,
-		channel:    ch,
-		queue:      &q,
-		queueName:  config.QueueName,
-		closeFuncs: closeFuncs,
-	}, nil
-}
-
-// Publish ...
-func (p *producer) Publish(msg []byte) error {
-	err := p.channel.Publish(
-		exchangeName,
-		p.queueName,
-		false,
-		false,
-		amqp.Publishing{
-			DeliveryMode: amqp.Persistent,
-			ContentType:  contentType,
-			Body:         msg,
-		},
-	)
+	p.session = sess
+
+	return p, nil
+}
+
+// setChannel puts a fresh channel into confirm mode. Delivery tags start over for every channel.
+func (p *producer) setChannel(ch *amqp.Channel) error {
+	err := ch.Confirm(false)
 	if err != nil {
 		return err
 	}
 
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.channel = ch
+	p.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, confirmBuffer))
+	p.returns = ch.NotifyReturn(make(chan amqp.Return, confirmBuffer))
+	p.nextTag = 1
+
 	return nil
 }
 
-// Close ...
-func (p *producer) Close() error {
-	for _, closeFunc := range p.closeFuncs {
-		closeFunc.Close() //nolint:errcheck
+// Publish sends msg and waits until the broker confirms it has taken responsibility for it.
+func (p *producer) Publish(ctx context.Context, msg broker.Message) error {
+	return p.PublishBatch(ctx, []broker.Message{msg})[0]
+}
+
+// PublishBatch publishes all of msgs before waiting for confirmations. The i-th element
+// of the result is the outcome for the i-th message, nil meaning the message was accepted and routed.
+func (p *producer) PublishBatch(ctx context.Context, msgs []broker.Message) []error {
+	errs := make([]error, len(msgs))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := p.session.Channel()
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
 	}
 
-	return nil
+	p.skipStale()
+
+	pending := make(map[uint64]int, len(msgs))
+	messageIDs := make(map[string]int, len(msgs))
+
+	for i, msg := range msgs {
+		messageID, err := messageID(msg)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+
+		destination, routingKey := p.route(msg)
+
+		spanCtx, span := otel.Tracer(instrumentationName).Start(ctx, destination+" publish",
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(
+				semconv.MessagingSystemRabbitmq,
+				semconv.MessagingOperationPublish,
+				semconv.MessagingDestinationName(destination),
+				semconv.MessagingRabbitmqDestinationRoutingKey(routingKey),
+				semconv.MessagingMessageID(messageID),
+			),
+		)
+		carrier := propagation.MapCarrier{}
+		otel.GetTextMapPropagator().Inject(spanCtx, carrier)
+
+		headers := amqp.Table{}
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+		for k, v := range carrier {
+			headers[k] = v
+		}
+
+		err = p.channel.Publish(
+			p.exchange,
+			routingKey,
+			p.mandatory,
+			false, // immediate
+			amqp.Publishing{
+				Headers:      headers,
+				DeliveryMode: amqp.Persistent,
+				ContentType:  messageContentType(msg),
+				MessageId:    messageID,
+				Type:         msg.Type,
+				Timestamp:    time.Now(),
+				Body:         msg.Body,
+			},
+		)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			span.End()
+			errs[i] = err
+			continue
+		}
+		span.End()
+
+		pending[p.nextTag] = i
+		messageIDs[messageID] = i
+		p.nextTag++
+
+		p.collectConfirms(pending, messageIDs, errs)
+	}
+
+	p.awaitConfirms(ctx, pending, messageIDs, errs)
+
+	return errs
+}
+
+// route returns the name of the destination the message is published to and its routing key.
+func (p *producer) route(msg broker.Message) (string, string) {
+	if p.exchange == exchangeName {
+		return p.queueName, p.queueName
+	}
+
+	return p.exchange, msg.RoutingKey
+}
+
+func messageID(msg broker.Message) (string, error) {
+	if msg.ID != "" {
+		return msg.ID, nil
+	}
+
+	id, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
+
+	return id.String(), nil
+}
+
+func messageContentType(msg broker.Message) string {
+	if msg.ContentType != "" {
+		return msg.ContentType
+	}
+
+	return contentType
+}
+
+func (p *producer) awaitConfirms(ctx context.Context, pending map[uint64]int, messageIDs map[string]int, errs []error) {
+	timer := time.NewTimer(p.confirmTimeout)
+	defer timer.Stop()
+
+	fail := func(reason error) {
+		for _, i := range pending {
+			errs[i] = reason
+		}
+	}
+
+	for len(pending) > 0 {
+		select {
+		case ret := <-p.returns:
+			p.markReturned(ret, messageIDs, errs)
+		case confirm, ok := <-p.confirms:
+			if !ok {
+				fail(ErrChannelClosed)
+				return
+			}
+
+			p.handleConfirm(confirm, pending, messageIDs, errs)
+		case <-ctx.Done():
+			fail(ctx.Err())
+			return
+		case <-timer.C:
+			fail(ErrConfirmTimeout)
+			return
+		}
+	}
+}
+
+// collectConfirms handles the confirmations that have already arrived without waiting for the rest.
+func (p *producer) collectConfirms(pending map[uint64]int, messageIDs map[string]int, errs []error) {
+	for {
+		select {
+		case ret := <-p.returns:
+			p.markReturned(ret, messageIDs, errs)
+		case confirm, ok := <-p.confirms:
+			if !ok {
+				return
+			}
+			p.handleConfirm(confirm, pending, messageIDs, errs)
+		default:
+			return
+		}
+	}
+}
+
+func (p *producer) handleConfirm(confirm amqp.Confirmation, pending map[uint64]int, messageIDs map[string]int, errs []error) {
+	// брокер присылает basic.return раньше подтверждения того же сообщения
+	p.drainReturns(messageIDs, errs)
+
+	i, ok := pending[confirm.DeliveryTag]
+	if !ok {
+		return
+	}
+	delete(pending, confirm.DeliveryTag)
+
+	if !confirm.Ack && errs[i] == nil {
+		errs[i] = ErrNacked
+	}
+}
+
+func (p *producer) markReturned(ret amqp.Return, messageIDs map[string]int, errs []error) {
+	i, ok := messageIDs[ret.MessageId]
+	if !ok {
+		return
+	}
+
+	errs[i] = fmt.Errorf("%w: %s", ErrUnroutable, ret.ReplyText)
+}
+
+func (p *producer) drainReturns(messageIDs map[string]int, errs []error) {
+	for {
+		select {
+		case ret := <-p.returns:
+			p.markReturned(ret, messageIDs, errs)
+		default:
+			return
+		}
+	}
+}
+
+// skipStale discards confirmations and returns left over from batches that gave up waiting,
+// so that they never fill up the buffers.
+func (p *producer) skipStale() {
+	for {
+		select {
+		case <-p.returns:
+		case _, ok := <-p.confirms:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Close ...
+func (p *producer) Close() error {
+	return p.session.Close()
 }
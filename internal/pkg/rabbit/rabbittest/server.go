@@ -0,0 +1,823 @@
+// Package rabbittest provides a local stand-in for a RabbitMQ broker that speaks just enough AMQP 0-9-1
+// for the rabbit package: queues, bindings, publisher confirms, consuming and basic.get. The stand-in can be
+// stopped and started again on the same address to emulate a broker restart, queues survive it the way durable
+// queues with persistent messages do.
+package rabbittest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+var ErrTimeout = errors.New("timed out waiting for messages")
+
+const (
+	frameMethod    = 1
+	frameHeader    = 2
+	frameBody      = 3
+	frameHeartbeat = 8
+	frameEnd       = 0xCE
+
+	frameMax = 131072
+)
+
+// Message is a message stored in a queue of the stand-in.
+type Message struct {
+	Exchange   string
+	RoutingKey string
+	Body       []byte
+
+	// свойства сообщения в том виде, в котором их прислал клиент
+	props []byte
+}
+
+type queue struct {
+	messages  []*Message
+	consumers []*consumer
+	next      int
+}
+
+type consumer struct {
+	tag   string
+	noAck bool
+	ch    *channel
+}
+
+type binding struct {
+	queue string
+	key   string
+}
+
+// Server is a fake AMQP broker listening on a local address.
+type Server struct {
+	addr string
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[*conn]struct{}
+	queues   map[string]*queue
+	bindings map[string][]binding
+	// публикации с этими ключами маршрутизации не сохраняются, а подтверждаются отказом (basic.nack)
+	nacked map[string]bool
+	// закрывается и пересоздается при каждом изменении очередей, чтобы разбудить ожидающих
+	changed chan struct{}
+}
+
+// NewServer starts the stand-in on a random local port.
+func NewServer() (*Server, error) {
+	s := &Server{
+		conns:    make(map[*conn]struct{}),
+		queues:   make(map[string]*queue),
+		bindings: make(map[string][]binding),
+		nacked:   make(map[string]bool),
+		changed:  make(chan struct{}),
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s.addr = l.Addr().String()
+	s.serve(l)
+
+	return s, nil
+}
+
+// URL returns the DSN to dial the stand-in with.
+func (s *Server) URL() string {
+	return "amqp://guest:guest@" + s.addr + "/"
+}
+
+// Stop drops all client connections and stops accepting new ones, as a broker going down would.
+func (s *Server) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener != nil {
+		s.listener.Close() //nolint:errcheck
+		s.listener = nil
+	}
+
+	for c := range s.conns {
+		c.close()
+	}
+}
+
+// Start accepts connections on the same address again after Stop.
+func (s *Server) Start() error {
+	l, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	s.serve(l)
+
+	return nil
+}
+
+// Close stops the stand-in for good.
+func (s *Server) Close() {
+	s.Stop()
+}
+
+// Messages returns the messages ready for delivery in the queue.
+func (s *Server) Messages(name string) []*Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	q, ok := s.queues[name]
+	if !ok {
+		return nil
+	}
+
+	return append([]*Message(nil), q.messages...)
+}
+
+// NackPublishes makes the stand-in refuse the publishings with the routing key, e.g. the ones sent to a queue
+// through the default exchange: they are dropped and negatively confirmed, as a broker that failed to store
+// them would.
+func (s *Server) NackPublishes(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nacked[key] = true
+}
+
+// Unacked returns the number of messages delivered to the clients and not settled yet.
+func (s *Server) Unacked() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for c := range s.conns {
+		for _, ch := range c.channels {
+			n += len(ch.unacked)
+		}
+	}
+
+	return n
+}
+
+// WaitMessages waits until the queue holds at least n messages ready for delivery.
+func (s *Server) WaitMessages(name string, n int, timeout time.Duration) ([]*Message, error) {
+	deadline := time.After(timeout)
+
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		var msgs []*Message
+		if q, ok := s.queues[name]; ok {
+			msgs = append(msgs, q.messages...)
+		}
+		s.mu.Unlock()
+
+		if len(msgs) >= n {
+			return msgs, nil
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return msgs, ErrTimeout
+		}
+	}
+}
+
+func (s *Server) serve(l net.Listener) {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			c := &conn{
+				srv:      s,
+				nc:       nc,
+				channels: make(map[uint16]*channel),
+			}
+
+			s.mu.Lock()
+			s.conns[c] = struct{}{}
+			s.mu.Unlock()
+
+			go c.run()
+		}
+	}()
+}
+
+// notify wakes up WaitMessages, the caller holds s.mu.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *Server) declare(name string) *queue {
+	q, ok := s.queues[name]
+	if !ok {
+		q = &queue{}
+		s.queues[name] = q
+	}
+
+	return q
+}
+
+// route returns the queues the message is published to, the caller holds s.mu.
+func (s *Server) route(exchange string, key string) []*queue {
+	if exchange == "" {
+		if q, ok := s.queues[key]; ok {
+			return []*queue{q}
+		}
+		return nil
+	}
+
+	var res []*queue
+	for _, b := range s.bindings[exchange] {
+		if b.key == key || b.key == "#" {
+			if q, ok := s.queues[b.queue]; ok {
+				res = append(res, q)
+			}
+		}
+	}
+
+	return res
+}
+
+// dispatch hands the ready messages of the queue to its consumers in turn, the caller holds s.mu.
+func (s *Server) dispatch(q *queue) {
+	for len(q.messages) > 0 && len(q.consumers) > 0 {
+		msg := q.messages[0]
+		q.messages = q.messages[1:]
+
+		cons := q.consumers[q.next%len(q.consumers)]
+		q.next++
+
+		cons.ch.deliver(cons, q, msg)
+	}
+}
+
+type unacked struct {
+	queue *queue
+	msg   *Message
+}
+
+type channel struct {
+	id      uint16
+	conn    *conn
+	confirm bool
+	// номера публикаций для подтверждений и номера доставок ведутся отдельно
+	publishTag  uint64
+	deliveryTag uint64
+	unacked     map[uint64]unacked
+	consumers   map[string]*queue
+
+	// публикация, для которой еще не получены заголовок и тело
+	publishing *publishing
+}
+
+type publishing struct {
+	exchange  string
+	key       string
+	mandatory bool
+	size      uint64
+	props     []byte
+	body      []byte
+}
+
+// deliver sends basic.deliver, the caller holds the server lock.
+func (ch *channel) deliver(cons *consumer, q *queue, msg *Message) {
+	ch.deliveryTag++
+	if !cons.noAck {
+		ch.unacked[ch.deliveryTag] = unacked{queue: q, msg: msg}
+	}
+
+	var w writer
+	w.shortstr(cons.tag)
+	w.longlong(ch.deliveryTag)
+	w.octet(0)
+	w.shortstr(msg.Exchange)
+	w.shortstr(msg.RoutingKey)
+	ch.conn.sendContent(ch.id, 60, 60, w.Bytes(), msg)
+}
+
+// requeue puts the unacknowledged messages back, the caller holds the server lock.
+func (ch *channel) requeue() {
+	for _, u := range ch.unacked {
+		u.queue.messages = append([]*Message{u.msg}, u.queue.messages...)
+	}
+	ch.unacked = make(map[uint64]unacked)
+
+	for tag, q := range ch.consumers {
+		removeConsumer(q, ch, tag)
+	}
+	ch.consumers = make(map[string]*queue)
+}
+
+// removeConsumer removes the consumer of the channel, tags are unique only within a channel.
+func removeConsumer(q *queue, ch *channel, tag string) {
+	for i, c := range q.consumers {
+		if c.ch == ch && c.tag == tag {
+			q.consumers = append(q.consumers[:i], q.consumers[i+1:]...)
+			return
+		}
+	}
+}
+
+type conn struct {
+	srv *Server
+	nc  net.Conn
+
+	wmu      sync.Mutex
+	channels map[uint16]*channel
+	nextTag  int
+}
+
+func (c *conn) close() {
+	c.nc.Close() //nolint:errcheck
+}
+
+func (c *conn) run() {
+	defer func() {
+		c.nc.Close() //nolint:errcheck
+
+		c.srv.mu.Lock()
+		defer c.srv.mu.Unlock()
+
+		delete(c.srv.conns, c)
+		for _, ch := range c.channels {
+			ch.requeue()
+		}
+		for _, q := range c.srv.queues {
+			c.srv.dispatch(q)
+		}
+		c.srv.notify()
+	}()
+
+	r := bufio.NewReader(c.nc)
+
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return
+	}
+
+	if err := c.handshake(r); err != nil {
+		return
+	}
+
+	for {
+		typ, chID, payload, err := readFrame(r)
+		if err != nil {
+			return
+		}
+
+		switch typ {
+		case frameMethod:
+			if !c.handleMethod(chID, payload) {
+				return
+			}
+		case frameHeader:
+			c.handleHeader(chID, payload)
+		case frameBody:
+			c.handleBody(chID, payload)
+		case frameHeartbeat:
+		}
+	}
+}
+
+func (c *conn) handshake(r *bufio.Reader) error {
+	var w writer
+	w.octet(0)
+	w.octet(9)
+	w.long(0) // server-properties
+	w.longstr("PLAIN")
+	w.longstr("en_US")
+	c.sendMethod(0, 10, 10, w.Bytes())
+
+	// connection.start-ok
+	if _, _, _, err := readFrame(r); err != nil {
+		return err
+	}
+
+	w = writer{}
+	w.short(0)
+	w.long(frameMax)
+	w.short(0)
+	c.sendMethod(0, 10, 30, w.Bytes())
+
+	// connection.tune-ok и connection.open
+	if _, _, _, err := readFrame(r); err != nil {
+		return err
+	}
+	if _, _, _, err := readFrame(r); err != nil {
+		return err
+	}
+
+	w = writer{}
+	w.shortstr("")
+	c.sendMethod(0, 10, 41, w.Bytes())
+
+	return nil
+}
+
+// handleMethod handles a method frame and reports whether the connection stays open.
+func (c *conn) handleMethod(chID uint16, payload []byte) bool {
+	r := &reader{b: payload}
+	class, method := r.short(), r.short()
+
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := c.channels[chID]
+
+	switch {
+	case class == 10 && method == 50: // connection.close
+		c.sendMethod(0, 10, 51, nil)
+		return false
+	case class == 10 && method == 51: // connection.close-ok
+		return false
+	case class == 20 && method == 10: // channel.open
+		c.channels[chID] = &channel{
+			id:        chID,
+			conn:      c,
+			unacked:   make(map[uint64]unacked),
+			consumers: make(map[string]*queue),
+		}
+		var w writer
+		w.longstr("")
+		c.sendMethod(chID, 20, 11, w.Bytes())
+	case class == 20 && method == 40: // channel.close
+		if ch != nil {
+			ch.requeue()
+			delete(c.channels, chID)
+			for _, q := range s.queues {
+				s.dispatch(q)
+			}
+			s.notify()
+		}
+		c.sendMethod(chID, 20, 41, nil)
+	case class == 20 && method == 41: // channel.close-ok
+		delete(c.channels, chID)
+	case class == 40 && method == 10: // exchange.declare
+		r.short()
+		r.shortstr()
+		r.shortstr()
+		bits := r.octet()
+		if bits&0x10 == 0 {
+			c.sendMethod(chID, 40, 11, nil)
+		}
+	case class == 50 && method == 10: // queue.declare
+		r.short()
+		name := r.shortstr()
+		bits := r.octet()
+		q := s.declare(name)
+		if bits&0x10 == 0 {
+			var w writer
+			w.shortstr(name)
+			w.long(uint32(len(q.messages)))
+			w.long(uint32(len(q.consumers)))
+			c.sendMethod(chID, 50, 11, w.Bytes())
+		}
+	case class == 50 && method == 20: // queue.bind
+		r.short()
+		name, exchange, key := r.shortstr(), r.shortstr(), r.shortstr()
+		bits := r.octet()
+		s.bindings[exchange] = append(s.bindings[exchange], binding{queue: name, key: key})
+		if bits&0x01 == 0 {
+			c.sendMethod(chID, 50, 21, nil)
+		}
+	case class == 60 && method == 10: // basic.qos
+		c.sendMethod(chID, 60, 11, nil)
+	case class == 60 && method == 20: // basic.consume
+		r.short()
+		name, tag := r.shortstr(), r.shortstr()
+		bits := r.octet()
+		if tag == "" {
+			c.nextTag++
+			tag = fmt.Sprintf("ctag-%d", c.nextTag)
+		}
+		q := s.declare(name)
+		q.consumers = append(q.consumers, &consumer{tag: tag, noAck: bits&0x02 != 0, ch: ch})
+		ch.consumers[tag] = q
+		if bits&0x08 == 0 {
+			var w writer
+			w.shortstr(tag)
+			c.sendMethod(chID, 60, 21, w.Bytes())
+		}
+		s.dispatch(q)
+	case class == 60 && method == 30: // basic.cancel
+		tag := r.shortstr()
+		bits := r.octet()
+		if q, ok := ch.consumers[tag]; ok {
+			removeConsumer(q, ch, tag)
+			delete(ch.consumers, tag)
+		}
+		if bits&0x01 == 0 {
+			var w writer
+			w.shortstr(tag)
+			c.sendMethod(chID, 60, 31, w.Bytes())
+		}
+	case class == 60 && method == 40: // basic.publish
+		r.short()
+		exchange, key := r.shortstr(), r.shortstr()
+		bits := r.octet()
+		ch.publishing = &publishing{exchange: exchange, key: key, mandatory: bits&0x01 != 0}
+	case class == 60 && method == 70: // basic.get
+		r.short()
+		name := r.shortstr()
+		bits := r.octet()
+		q := s.declare(name)
+		if len(q.messages) == 0 {
+			var w writer
+			w.shortstr("")
+			c.sendMethod(chID, 60, 72, w.Bytes())
+			break
+		}
+		msg := q.messages[0]
+		q.messages = q.messages[1:]
+		ch.deliveryTag++
+		if bits&0x01 == 0 {
+			ch.unacked[ch.deliveryTag] = unacked{queue: q, msg: msg}
+		}
+		var w writer
+		w.longlong(ch.deliveryTag)
+		w.octet(0)
+		w.shortstr(msg.Exchange)
+		w.shortstr(msg.RoutingKey)
+		w.long(uint32(len(q.messages)))
+		c.sendContent(chID, 60, 71, w.Bytes(), msg)
+		s.notify()
+	case class == 60 && method == 80: // basic.ack
+		tag := r.longlong()
+		bits := r.octet()
+		ch.settle(tag, bits&0x01 != 0, false)
+	case class == 60 && method == 90: // basic.reject
+		tag := r.longlong()
+		bits := r.octet()
+		ch.settle(tag, false, bits&0x01 != 0)
+	case class == 60 && method == 120: // basic.nack
+		tag := r.longlong()
+		bits := r.octet()
+		ch.settle(tag, bits&0x01 != 0, bits&0x02 != 0)
+	case class == 85 && method == 10: // confirm.select
+		bits := r.octet()
+		ch.confirm = true
+		if bits&0x01 == 0 {
+			c.sendMethod(chID, 85, 11, nil)
+		}
+	}
+
+	return true
+}
+
+// settle acks or rejects deliveries, rejected messages are put back when requeue is set and dropped
+// otherwise. The caller holds the server lock.
+func (ch *channel) settle(tag uint64, multiple bool, requeue bool) {
+	s := ch.conn.srv
+
+	for t, u := range ch.unacked {
+		if t != tag && !(multiple && t < tag) {
+			continue
+		}
+		delete(ch.unacked, t)
+
+		if requeue {
+			u.queue.messages = append([]*Message{u.msg}, u.queue.messages...)
+			s.dispatch(u.queue)
+		}
+	}
+
+	s.notify()
+}
+
+func (c *conn) handleHeader(chID uint16, payload []byte) {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := c.channels[chID]
+	if ch == nil || ch.publishing == nil {
+		return
+	}
+
+	r := &reader{b: payload}
+	r.short()
+	r.short()
+	ch.publishing.size = r.longlong()
+	ch.publishing.props = append([]byte(nil), payload[r.off:]...)
+
+	if ch.publishing.size == 0 {
+		c.publish(ch)
+	}
+}
+
+func (c *conn) handleBody(chID uint16, payload []byte) {
+	s := c.srv
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch := c.channels[chID]
+	if ch == nil || ch.publishing == nil {
+		return
+	}
+
+	ch.publishing.body = append(ch.publishing.body, payload...)
+	if uint64(len(ch.publishing.body)) >= ch.publishing.size {
+		c.publish(ch)
+	}
+}
+
+// publish routes the completed publishing and confirms it, the caller holds the server lock.
+func (c *conn) publish(ch *channel) {
+	s := c.srv
+	p := ch.publishing
+	ch.publishing = nil
+
+	msg := &Message{
+		Exchange:   p.exchange,
+		RoutingKey: p.key,
+		Body:       p.body,
+		props:      p.props,
+	}
+
+	queues := s.route(p.exchange, p.key)
+	if len(queues) == 0 && p.mandatory {
+		var w writer
+		w.short(312)
+		w.shortstr("NO_ROUTE")
+		w.shortstr(p.exchange)
+		w.shortstr(p.key)
+		c.sendContent(ch.id, 60, 50, w.Bytes(), msg)
+	}
+
+	nack := s.nacked[p.key]
+	if nack {
+		queues = nil
+	}
+
+	for _, q := range queues {
+		q.messages = append(q.messages, msg)
+		s.dispatch(q)
+	}
+	s.notify()
+
+	if ch.confirm {
+		ch.publishTag++
+		var w writer
+		w.longlong(ch.publishTag)
+		w.octet(0)
+		if nack {
+			c.sendMethod(ch.id, 60, 120, w.Bytes())
+		} else {
+			c.sendMethod(ch.id, 60, 80, w.Bytes())
+		}
+	}
+}
+
+func (c *conn) sendMethod(chID uint16, class uint16, method uint16, args []byte) {
+	var w writer
+	w.short(class)
+	w.short(method)
+	w.Write(args) //nolint:errcheck
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	writeFrame(c.nc, frameMethod, chID, w.Bytes()) //nolint:errcheck
+}
+
+// sendContent sends a method followed by the content header and body frames of the message.
+func (c *conn) sendContent(chID uint16, class uint16, method uint16, args []byte, msg *Message) {
+	var m writer
+	m.short(class)
+	m.short(method)
+	m.Write(args) //nolint:errcheck
+
+	var h writer
+	h.short(60)
+	h.short(0)
+	h.longlong(uint64(len(msg.Body)))
+	props := msg.props
+	if len(props) == 0 {
+		props = []byte{0, 0}
+	}
+	h.Write(props) //nolint:errcheck
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	writeFrame(c.nc, frameMethod, chID, m.Bytes()) //nolint:errcheck
+	writeFrame(c.nc, frameHeader, chID, h.Bytes()) //nolint:errcheck
+	for body := msg.Body; len(body) > 0; {
+		n := min(len(body), frameMax-8)
+		writeFrame(c.nc, frameBody, chID, body[:n]) //nolint:errcheck
+		body = body[n:]
+	}
+}
+
+func readFrame(r *bufio.Reader) (byte, uint16, []byte, error) {
+	head := make([]byte, 7)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return 0, 0, nil, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(head[3:7])+1)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, 0, nil, err
+	}
+	if payload[len(payload)-1] != frameEnd {
+		return 0, 0, nil, errors.New("bad frame end")
+	}
+
+	return head[0], binary.BigEndian.Uint16(head[1:3]), payload[:len(payload)-1], nil
+}
+
+func writeFrame(w io.Writer, typ byte, chID uint16, payload []byte) error {
+	buf := make([]byte, 0, len(payload)+8)
+	buf = append(buf, typ)
+	buf = binary.BigEndian.AppendUint16(buf, chID)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(payload)))
+	buf = append(buf, payload...)
+	buf = append(buf, frameEnd)
+
+	_, err := w.Write(buf)
+	return err
+}
+
+type writer struct {
+	bytes.Buffer
+}
+
+func (w *writer) octet(v byte) {
+	w.WriteByte(v) //nolint:errcheck
+}
+
+func (w *writer) short(v uint16) {
+	w.Write(binary.BigEndian.AppendUint16(nil, v)) //nolint:errcheck
+}
+
+func (w *writer) long(v uint32) {
+	w.Write(binary.BigEndian.AppendUint32(nil, v)) //nolint:errcheck
+}
+
+func (w *writer) longlong(v uint64) {
+	w.Write(binary.BigEndian.AppendUint64(nil, v)) //nolint:errcheck
+}
+
+func (w *writer) shortstr(v string) {
+	w.octet(byte(len(v)))
+	w.WriteString(v) //nolint:errcheck
+}
+
+func (w *writer) longstr(v string) {
+	w.long(uint32(len(v)))
+	w.WriteString(v) //nolint:errcheck
+}
+
+// reader decodes method arguments, tables are skipped since the stand-in does not need them.
+type reader struct {
+	b   []byte
+	off int
+}
+
+func (r *reader) next(n int) []byte {
+	if r.off+n > len(r.b) {
+		r.off = len(r.b)
+		return make([]byte, n)
+	}
+
+	res := r.b[r.off : r.off+n]
+	r.off += n
+
+	return res
+}
+
+func (r *reader) octet() byte {
+	return r.next(1)[0]
+}
+
+func (r *reader) short() uint16 {
+	return binary.BigEndian.Uint16(r.next(2))
+}
+
+func (r *reader) long() uint32 {
+	return binary.BigEndian.Uint32(r.next(4))
+}
+
+func (r *reader) longlong() uint64 {
+	return binary.BigEndian.Uint64(r.next(8))
+}
+
+func (r *reader) shortstr() string {
+	return string(r.next(int(r.octet())))
+}
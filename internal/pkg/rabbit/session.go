@@ -0,0 +1,216 @@
+package rabbit
+
+import (
+	"errors"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"booking-schedule/internal/logger/sl"
+
+	"github.com/streadway/amqp"
+)
+
+var (
+	ErrNotConnected = errors.New("not connected to rabbitmq, reconnection in progress")
+	ErrClosed       = errors.New("rabbitmq session closed")
+)
+
+// session keeps a connection and a channel to the broker alive. Once the broker closes either of them
+// the session redials with jittered exponential backoff and declares the topology again.
+type session struct {
+	dsn      string
+	setup    func(ch *amqp.Channel) error
+	minDelay time.Duration
+	maxDelay time.Duration
+	log      *slog.Logger
+
+	mu         sync.RWMutex
+	connection *amqp.Connection
+	channel    *amqp.Channel
+	// ready закрывается, когда соединение установлено, и пересоздается при его потере
+	ready chan struct{}
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newSession(dsn string, minDelay time.Duration, maxDelay time.Duration, log *slog.Logger, setup func(ch *amqp.Channel) error) (*session, error) {
+	s := &session{
+		dsn:      dsn,
+		setup:    setup,
+		minDelay: minDelay,
+		maxDelay: maxDelay,
+		log:      log,
+		ready:    make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	go s.supervise()
+
+	return s, nil
+}
+
+func (s *session) connect() error {
+	conn, err := amqp.Dial(s.dsn)
+	if err != nil {
+		return err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		conn.Close() //nolint:errcheck
+		return err
+	}
+
+	err = s.setup(ch)
+	if err != nil {
+		conn.Close() //nolint:errcheck
+		return err
+	}
+
+	s.mu.Lock()
+	s.connection = conn
+	s.channel = ch
+	close(s.ready)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *session) supervise() {
+	const op = "rabbit.session.supervise"
+
+	log := s.log.With(slog.String("op", op))
+
+	for {
+		s.mu.RLock()
+		connClosed := s.connection.NotifyClose(make(chan *amqp.Error, 1))
+		chClosed := s.channel.NotifyClose(make(chan *amqp.Error, 1))
+		s.mu.RUnlock()
+
+		var reason *amqp.Error
+		select {
+		case <-s.done:
+			return
+		case reason = <-connClosed:
+		case reason = <-chClosed:
+		}
+
+		s.mu.Lock()
+		s.ready = make(chan struct{})
+		s.connection.Close() //nolint:errcheck
+		s.mu.Unlock()
+
+		if reason != nil {
+			log.Warn("lost connection to rabbitmq", sl.Err(reason))
+		}
+
+		for attempt := 0; ; attempt++ {
+			select {
+			case <-s.done:
+				return
+			case <-time.After(s.backoff(attempt)):
+			}
+
+			err := s.connect()
+			if err == nil {
+				log.Info("reconnected to rabbitmq", slog.Int("attempts", attempt+1))
+				break
+			}
+
+			log.Error("could not reconnect to rabbitmq", sl.Err(err), slog.Int("attempt", attempt+1))
+		}
+	}
+}
+
+// backoff doubles the delay with every attempt up to maxDelay and picks a random value
+// from its upper half so that several clients do not hammer a restarted broker simultaneously.
+func (s *session) backoff(attempt int) time.Duration {
+	delay := s.maxDelay
+	if attempt < 32 && s.minDelay<<attempt < s.maxDelay {
+		delay = s.minDelay << attempt
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Channel returns the current channel or ErrNotConnected while the session is reconnecting.
+func (s *session) Channel() (*amqp.Channel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	select {
+	case <-s.done:
+		return nil, ErrClosed
+	case <-s.ready:
+		return s.channel, nil
+	default:
+		return nil, ErrNotConnected
+	}
+}
+
+// OpenChannel opens one more channel on the current connection or returns ErrNotConnected while
+// the session is reconnecting. The channel is closed along with the connection.
+func (s *session) OpenChannel() (*amqp.Channel, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	select {
+	case <-s.done:
+		return nil, ErrClosed
+	case <-s.ready:
+		return s.connection.Channel()
+	default:
+		return nil, ErrNotConnected
+	}
+}
+
+// WaitChannel blocks until the session is connected or closed.
+func (s *session) WaitChannel() (*amqp.Channel, error) {
+	for {
+		s.mu.RLock()
+		ready := s.ready
+		s.mu.RUnlock()
+
+		select {
+		case <-s.done:
+			return nil, ErrClosed
+		case <-ready:
+		}
+
+		ch, err := s.Channel()
+		if !errors.Is(err, ErrNotConnected) {
+			return ch, err
+		}
+	}
+}
+
+// Done is closed when the session is closed.
+func (s *session) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *session) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.channel.Close() //nolint:errcheck
+		err = s.connection.Close()
+		if errors.Is(err, amqp.ErrClosed) {
+			err = nil
+		}
+	})
+
+	return err
+}
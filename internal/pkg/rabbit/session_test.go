@@ -0,0 +1,254 @@
+package rabbit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"booking-schedule/internal/config"
+	"booking-schedule/internal/pkg/broker"
+	"booking-schedule/internal/pkg/rabbit/rabbittest"
+)
+
+const testQueue = "bookings.notifications"
+
+func newTestServer(t *testing.T) *rabbittest.Server {
+	t.Helper()
+
+	srv, err := rabbittest.NewServer()
+	if err != nil {
+		t.Fatalf("start broker stand-in: %v", err)
+	}
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func newTestProducer(t *testing.T, srv *rabbittest.Server) broker.Producer {
+	t.Helper()
+
+	p, err := NewProducer(&config.RabbitProducer{
+		DSN:               srv.URL(),
+		QueueName:         testQueue,
+		ReconnectDelay:    10 * time.Millisecond,
+		MaxReconnectDelay: 50 * time.Millisecond,
+		ConfirmTimeout:    time.Second,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("create producer: %v", err)
+	}
+	t.Cleanup(func() { p.Close() }) //nolint:errcheck
+
+	return p
+}
+
+func newTestConsumer(t *testing.T, srv *rabbittest.Server) broker.Consumer {
+	t.Helper()
+
+	c, err := NewConsumer(&config.RabbitConsumer{
+		DSN:               srv.URL(),
+		QueueName:         testQueue,
+		Prefetch:          1,
+		MaxRetries:        3,
+		RetryDelay:        time.Second,
+		ReconnectDelay:    10 * time.Millisecond,
+		MaxReconnectDelay: 50 * time.Millisecond,
+		ConfirmTimeout:    time.Second,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("create consumer: %v", err)
+	}
+	t.Cleanup(func() { c.Close() }) //nolint:errcheck
+
+	return c
+}
+
+// eventually calls publish until it returns an error matching want, nil included, or the timeout expires.
+func eventually(t *testing.T, want error, publish func() error) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	var err error
+	for time.Now().Before(deadline) {
+		err = publish()
+		if (want == nil && err == nil) || (want != nil && errors.Is(err, want)) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("publish returned %v, want %v", err, want)
+}
+
+func TestProducerPublishesAfterBrokerRestart(t *testing.T) {
+	srv := newTestServer(t)
+	p := newTestProducer(t, srv)
+	ctx := context.Background()
+
+	publish := func() error {
+		return p.Publish(ctx, broker.Message{Body: []byte(`{}`)})
+	}
+
+	err := publish()
+	if err != nil {
+		t.Fatalf("publish before restart: %v", err)
+	}
+
+	srv.Stop()
+	eventually(t, ErrNotConnected, publish)
+
+	err = srv.Start()
+	if err != nil {
+		t.Fatalf("restart broker stand-in: %v", err)
+	}
+	eventually(t, nil, publish)
+
+	msgs := srv.Messages(testQueue)
+	if len(msgs) != 2 {
+		t.Fatalf("queue holds %d messages, want 2", len(msgs))
+	}
+}
+
+func TestConsumerResumesAfterBrokerRestart(t *testing.T) {
+	srv := newTestServer(t)
+	p := newTestProducer(t, srv)
+	c := newTestConsumer(t, srv)
+	ctx := context.Background()
+
+	deliveries, err := c.Consume()
+	if err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+
+	receive := func(body string) {
+		t.Helper()
+
+		select {
+		case d := <-deliveries:
+			if string(d.Body) != body {
+				t.Fatalf("received %q, want %q", d.Body, body)
+			}
+			err := d.Ack()
+			if err != nil {
+				t.Fatalf("ack: %v", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message %q was not delivered", body)
+		}
+	}
+
+	err = p.Publish(ctx, broker.Message{Body: []byte(`"first"`)})
+	if err != nil {
+		t.Fatalf("publish before restart: %v", err)
+	}
+	receive(`"first"`)
+
+	// подтверждение отправляется асинхронно, без него брокер доставил бы сообщение повторно
+	deadline := time.Now().Add(5 * time.Second)
+	for srv.Unacked() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	srv.Stop()
+	err = srv.Start()
+	if err != nil {
+		t.Fatalf("restart broker stand-in: %v", err)
+	}
+
+	eventually(t, nil, func() error {
+		return p.Publish(ctx, broker.Message{Body: []byte(`"second"`)})
+	})
+	receive(`"second"`)
+}
+
+func TestRetryAcksAfterConfirm(t *testing.T) {
+	srv := newTestServer(t)
+	p := newTestProducer(t, srv)
+	c := newTestConsumer(t, srv)
+
+	err := p.Publish(context.Background(), broker.Message{Body: []byte(`{}`)})
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	deliveries, err := c.Consume()
+	if err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+
+	var d *broker.Delivery
+	select {
+	case d = <-deliveries:
+	case <-time.After(5 * time.Second):
+		t.Fatal("message was not delivered")
+	}
+
+	err = c.Retry(d)
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	_, err = srv.WaitMessages(retryQueue(testQueue, 1), 1, 5*time.Second)
+	if err != nil {
+		t.Fatalf("retry queue: %v", err)
+	}
+
+	// исходное сообщение подтверждено и не вернется в очередь после закрытия соединения
+	err = c.Close()
+	if err != nil {
+		t.Fatalf("close consumer: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if msgs := srv.Messages(testQueue); len(msgs) != 0 {
+		t.Fatalf("work queue holds %d messages after retry, want 0", len(msgs))
+	}
+}
+
+func TestRetryRequeuesWhenCopyIsNacked(t *testing.T) {
+	srv := newTestServer(t)
+	p := newTestProducer(t, srv)
+	c := newTestConsumer(t, srv)
+
+	srv.NackPublishes(retryQueue(testQueue, 1))
+
+	err := p.Publish(context.Background(), broker.Message{Body: []byte(`{}`)})
+	if err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	deliveries, err := c.Consume()
+	if err != nil {
+		t.Fatalf("consume: %v", err)
+	}
+
+	receive := func() *broker.Delivery {
+		t.Helper()
+
+		select {
+		case d := <-deliveries:
+			return d
+		case <-time.After(5 * time.Second):
+			t.Fatal("message was not delivered")
+			return nil
+		}
+	}
+
+	err = c.Retry(receive())
+	if !errors.Is(err, ErrNacked) {
+		t.Fatalf("retry returned %v, want %v", err, ErrNacked)
+	}
+
+	// при prefetch 1 повторная доставка возможна, только если исходное сообщение вернулось в очередь
+	d := receive()
+	if string(d.Body) != `{}` {
+		t.Fatalf("redelivered body is %s", d.Body)
+	}
+
+	if msgs := srv.Messages(retryQueue(testQueue, 1)); len(msgs) != 0 {
+		t.Fatalf("retry queue holds %d messages, want 0", len(msgs))
+	}
+}
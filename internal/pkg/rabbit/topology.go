@@ -0,0 +1,143 @@
+package rabbit
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"booking-schedule/internal/pkg/broker"
+
+	"github.com/streadway/amqp"
+)
+
+// ErrQueueMismatch is returned when the work queue already exists with other arguments.
+var ErrQueueMismatch = errors.New("work queue exists without the dead-letter exchange, set another AMQP_QUEUE or recreate the queue")
+
+const (
+	// RetryCountHeader holds the number of delivery attempts already made for a message.
+	RetryCountHeader = "x-retry-count"
+
+	deadLetterExchangeSuffix = ".dlx"
+	deadLetterQueueSuffix    = ".dead"
+	retryQueueFormat         = "%s.retry.%d"
+)
+
+func deadLetterExchange(queueName string) string {
+	return queueName + deadLetterExchangeSuffix
+}
+
+func deadLetterQueue(queueName string) string {
+	return queueName + deadLetterQueueSuffix
+}
+
+func retryQueue(queueName string, attempt int) string {
+	return fmt.Sprintf(retryQueueFormat, queueName, attempt)
+}
+
+// declareQueue declares the work queue, which dead-letters rejected messages to its DLX. The arguments
+// of an existing queue cannot be changed, so a queue declared earlier without the DLX has to be
+// replaced as described in the README.
+func declareQueue(ch *amqp.Channel, queueName string) (amqp.Queue, error) {
+	q, err := ch.QueueDeclare(
+		queueName, // name
+		true,      // durable
+		false,     // autodelete
+		false,     // exclusive
+		false,     // nowait
+		amqp.Table{
+			"x-dead-letter-exchange":    deadLetterExchange(queueName),
+			"x-dead-letter-routing-key": queueName,
+		},
+	)
+	var amqpErr *amqp.Error
+	if errors.As(err, &amqpErr) && amqpErr.Code == amqp.PreconditionFailed {
+		return q, fmt.Errorf("%w: %s", ErrQueueMismatch, amqpErr.Reason)
+	}
+
+	return q, err
+}
+
+// declareTopicExchange declares the durable exchange domain events are published to.
+func declareTopicExchange(ch *amqp.Channel, exchange string) error {
+	return ch.ExchangeDeclare(
+		exchange,           // name
+		amqp.ExchangeTopic, // kind
+		true,               // durable
+		false,              // autodelete
+		false,              // internal
+		false,              // nowait
+		nil,                // args
+	)
+}
+
+// declareDeadLetter declares the dead-letter exchange of the work queue and the queue bound to it.
+func declareDeadLetter(ch *amqp.Channel, queueName string) error {
+	err := ch.ExchangeDeclare(
+		deadLetterExchange(queueName), // name
+		amqp.ExchangeDirect,           // kind
+		true,                          // durable
+		false,                         // autodelete
+		false,                         // internal
+		false,                         // nowait
+		nil,                           // args
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = ch.QueueDeclare(
+		deadLetterQueue(queueName), // name
+		true,                       // durable
+		false,                      // autodelete
+		false,                      // exclusive
+		false,                      // nowait
+		nil,                        // args
+	)
+	if err != nil {
+		return err
+	}
+
+	return ch.QueueBind(
+		deadLetterQueue(queueName),    // name
+		queueName,                     // key
+		deadLetterExchange(queueName), // exchange
+		false,                         // nowait
+		nil,                           // args
+	)
+}
+
+// declareRetryQueues declares one delay queue per retry attempt. Messages sit in a delay queue
+// until their TTL expires and are then dead-lettered back to the work queue.
+func declareRetryQueues(ch *amqp.Channel, queueName string, maxRetries int, baseDelay time.Duration) error {
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		_, err := ch.QueueDeclare(
+			retryQueue(queueName, attempt), // name
+			true,                           // durable
+			false,                          // autodelete
+			false,                          // exclusive
+			false,                          // nowait
+			amqp.Table{
+				"x-message-ttl":             broker.RetryDelay(baseDelay, attempt).Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": queueName,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// retryCount returns the number of attempts already made to process the delivery.
+func retryCount(msg amqp.Delivery) int {
+	switch count := msg.Headers[RetryCountHeader].(type) {
+	case int32:
+		return int(count)
+	case int64:
+		return int(count)
+	default:
+		return 0
+	}
+}
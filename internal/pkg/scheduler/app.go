@@ -1,8 +1,23 @@
 package scheduler
 
 import (
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/middleware/auth"
+	mwLogger "booking-schedule/internal/middleware/logger"
+	"booking-schedule/internal/middleware/metrics"
+	"booking-schedule/internal/pkg/observability"
 	"context"
-	"sync"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/go-chi/chi/middleware"
+	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/riandyrn/otelchi"
 )
 
 type App struct {
@@ -50,28 +65,67 @@ func (a *App) Run(ctx context.Context) error {
 	//nolint:errcheck
 	defer func() {
 		a.serviceProvider.db.Close()
-		a.serviceProvider.rabbitProducer.Close()
+		a.serviceProvider.producer.Close()
 	}()
 
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	err := a.runSchedulerService(ctx, wg)
-	if err != nil {
-		return err
-	}
-	wg.Wait()
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	adminServer := a.runAdminServer(ctx)
+	defer func() {
+		// TODO: move timeout to config
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		adminServer.Shutdown(ctx) //nolint:errcheck
+	}()
+
+	a.serviceProvider.GetLogger().Info("attempting to run scheduler service")
+	// возвращается после остановки всех задач
+	a.serviceProvider.GetJobRegistry(ctx).Run(ctx)
 	a.serviceProvider.GetLogger().Info("scheduler service stopped")
 
 	return nil
 }
 
-func (a *App) runSchedulerService(ctx context.Context, wg *sync.WaitGroup) error {
-	go func() {
-		defer wg.Done()
+// runAdminServer serves the metrics and the admin API to list the jobs, trigger them and query the bookings archive.
+func (a *App) runAdminServer(ctx context.Context) *http.Server {
+	log := a.serviceProvider.GetLogger()
 
-		a.serviceProvider.GetLogger().Info("attempting to run scheduler service")
-		a.serviceProvider.GetSchedulerService(ctx).Run(ctx)
+	if meter := a.serviceProvider.GetMeter(ctx); meter != nil {
+		go observability.CollectMachineResourceMetrics(meter, log)
+	}
+
+	jobImpl := a.serviceProvider.GetJobImpl(ctx)
+	archiveImpl := a.serviceProvider.GetArchiveImpl(ctx)
+
+	router := chi.NewRouter()
+	router.Handle("/metrics", promhttp.Handler())
+	router.Group(func(r chi.Router) {
+		r.Use(middleware.RequestID)
+		r.Use(otelchi.Middleware("scheduler-admin", otelchi.WithChiRoutes(router)))
+		r.Use(metrics.NewMetricMiddleware(a.serviceProvider.GetMeter(ctx)))
+		r.Use(mwLogger.New(log))
+		r.Use(middleware.Recoverer)
+		r.Use(auth.Auth(log, a.serviceProvider.GetJWTService(ctx)))
+		r.Route("/jobs", func(r chi.Router) {
+			r.Get("/", jobImpl.GetJobs(log))
+			r.Post("/{job_name}/run", jobImpl.TriggerJob(log))
+		})
+		r.Get("/archive/bookings", archiveImpl.GetArchivedBookings(log))
+	})
+
+	srv := &http.Server{
+		Addr:              a.serviceProvider.GetConfig().GetAdminAddress(),
+		Handler:           router,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		log.Info("starting admin server", slog.String("address", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("admin server failed", sl.Err(err))
+		}
 	}()
 
-	return nil
+	return srv
 }
@@ -1,19 +1,36 @@
 package scheduler
 
 import (
+	"booking-schedule/internal/app/api/archive"
+	"booking-schedule/internal/app/api/job"
+	archiveRepository "booking-schedule/internal/app/repository/archive"
 	bookingRepository "booking-schedule/internal/app/repository/booking"
+	notificationRepository "booking-schedule/internal/app/repository/notification"
+	partitionRepository "booking-schedule/internal/app/repository/partition"
+	preferencesRepository "booking-schedule/internal/app/repository/preferences"
+	userRepository "booking-schedule/internal/app/repository/user"
+	archiveService "booking-schedule/internal/app/service/archive"
+	jobService "booking-schedule/internal/app/service/job"
+	"booking-schedule/internal/app/service/jwt"
 	schedulerService "booking-schedule/internal/app/service/scheduler"
 	"booking-schedule/internal/config"
 	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
+	"booking-schedule/internal/pkg/broker/memory"
+	"booking-schedule/internal/pkg/broker/nats"
 	"booking-schedule/internal/pkg/db"
+	"booking-schedule/internal/pkg/db/transaction"
+	"booking-schedule/internal/pkg/jobs"
 	"booking-schedule/internal/pkg/observability"
 	"booking-schedule/internal/pkg/rabbit"
 	"context"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
 	"time"
 
+	"go.opentelemetry.io/otel/metric"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -32,12 +49,27 @@ type serviceProvider struct {
 
 	log    *slog.Logger
 	tracer trace.Tracer
+	meter  metric.Meter
 
-	rabbitProducer rabbit.Producer
+	producer broker.Producer
 
-	bookingRepository bookingRepository.Repository
+	bookingRepository      bookingRepository.Repository
+	notificationRepository notificationRepository.Repository
+	preferencesRepository  preferencesRepository.Repository
+	userRepository         userRepository.Repository
+	archiveRepository      archiveRepository.Repository
+	partitionRepository    partitionRepository.Repository
+
+	txManager db.TxManager
 
 	schedulerService *schedulerService.Service
+	jobRegistry      *jobs.Registry
+	jobService       *jobService.Service
+	jwtService       jwt.Service
+	archiveService   *archiveService.Service
+
+	jobImpl     *job.Implementation
+	archiveImpl *archive.Implementation
 }
 
 func newServiceProvider(configType string, configPath string) *serviceProvider {
@@ -54,7 +86,8 @@ func (s *serviceProvider) GetDB(ctx context.Context) db.Client {
 			s.GetLogger().Error("could not get db config", sl.Err(err))
 			os.Exit(1)
 		}
-		dbc, err := db.NewClient(ctx, cfg)
+		// задания читают только что записанные ими строки, поэтому реплики не используются
+		dbc, err := db.NewClient(ctx, cfg, nil, 0, 0, s.GetConfig().Database.SlowQueryThreshold, s.GetLogger(), s.GetMeter(ctx))
 		if err != nil {
 			s.GetLogger().Error("could not connect to db", sl.Err(err))
 			os.Exit(1)
@@ -94,20 +127,173 @@ func (s *serviceProvider) GetBookingRepository(ctx context.Context) bookingRepos
 	return s.bookingRepository
 }
 
+func (s *serviceProvider) GetNotificationRepository(ctx context.Context) notificationRepository.Repository {
+	if s.notificationRepository == nil {
+		s.notificationRepository = notificationRepository.NewNotificationRepository(s.GetDB(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.notificationRepository
+}
+
+func (s *serviceProvider) GetPreferencesRepository(ctx context.Context) preferencesRepository.Repository {
+	if s.preferencesRepository == nil {
+		s.preferencesRepository = preferencesRepository.NewPreferencesRepository(s.GetDB(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.preferencesRepository
+}
+
+func (s *serviceProvider) GetArchiveRepository(ctx context.Context) archiveRepository.Repository {
+	if s.archiveRepository == nil {
+		s.archiveRepository = archiveRepository.NewArchiveRepository(s.GetDB(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.archiveRepository
+}
+
+func (s *serviceProvider) GetPartitionRepository(ctx context.Context) partitionRepository.Repository {
+	if s.partitionRepository == nil {
+		s.partitionRepository = partitionRepository.NewPartitionRepository(s.GetDB(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.partitionRepository
+}
+
+// GetSchedulerService checks the archive settings, the archive directory is created when bookings are archived to files.
 func (s *serviceProvider) GetSchedulerService(ctx context.Context) *schedulerService.Service {
 	if s.schedulerService == nil {
+		cfg := s.GetConfig().GetArchiveConfig()
+
+		switch cfg.Target {
+		case schedulerService.ArchiveTargetTable:
+		case schedulerService.ArchiveTargetFile:
+			if cfg.Format != schedulerService.ArchiveFormatJSONL && cfg.Format != schedulerService.ArchiveFormatCSV {
+				s.GetLogger().Error("invalid archive config", sl.Err(schedulerService.ErrUnknownArchiveFormat), slog.String("format", cfg.Format))
+				os.Exit(1)
+			}
+
+			err := os.MkdirAll(cfg.Dir, 0o750)
+			if err != nil {
+				s.GetLogger().Error("could not create archive directory", sl.Err(err), slog.String("dir", cfg.Dir))
+				os.Exit(1)
+			}
+		default:
+			s.GetLogger().Error("invalid archive config", sl.Err(schedulerService.ErrUnknownArchiveTarget), slog.String("target", cfg.Target))
+			os.Exit(1)
+		}
+
+		if cfg.BatchSize == 0 {
+			s.GetLogger().Error("invalid archive config: batch size should be positive")
+			os.Exit(1)
+		}
+
 		s.schedulerService = schedulerService.NewSchedulerService(
 			s.GetBookingRepository(ctx),
+			s.GetNotificationRepository(ctx),
+			s.GetPreferencesRepository(ctx),
+			s.GetArchiveRepository(ctx),
+			s.GetPartitionRepository(ctx),
+			s.TxManager(ctx),
 			s.GetLogger(),
 			s.GetTracer(ctx),
-			s.GetRabbitProducer(),
-			time.Duration(s.GetConfig().GetSchedulerConfig().CheckPeriodSec)*time.Second,
-			time.Duration(s.GetConfig().GetSchedulerConfig().BookingTTL)*time.Hour*24)
+			s.GetProducer(),
+			time.Duration(s.GetConfig().GetSchedulerConfig().BookingTTL)*time.Hour*24,
+			cfg.Target,
+			cfg.Format,
+			cfg.Dir,
+			cfg.BatchSize,
+			s.GetConfig().GetPartitionsConfig().AheadMonths,
+			s.GetConfig().GetPartitionsConfig().DetachExpired)
 	}
 
 	return s.schedulerService
 }
 
+func (s *serviceProvider) GetUserRepository(ctx context.Context) userRepository.Repository {
+	if s.userRepository == nil {
+		s.userRepository = userRepository.NewUserRepository(s.GetDB(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.userRepository
+}
+
+// GetJobRegistry registers the jobs of the scheduler service with the schedules from the config.
+func (s *serviceProvider) GetJobRegistry(ctx context.Context) *jobs.Registry {
+	if s.jobRegistry == nil {
+		registry := jobs.NewRegistry(s.GetLogger(), s.GetTracer(ctx), s.GetMeter(ctx))
+		svc := s.GetSchedulerService(ctx)
+		cfg := s.GetConfig().GetJobsConfig()
+
+		for _, j := range []struct {
+			name     string
+			schedule string
+			timeout  time.Duration
+			run      func(ctx context.Context) error
+		}{
+			{schedulerService.JobReminders, cfg.Reminders.Schedule, cfg.Reminders.Timeout, svc.SendReminders},
+			{schedulerService.JobDigests, cfg.Digests.Schedule, cfg.Digests.Timeout, svc.SendDigests},
+			{schedulerService.JobCleanup, cfg.Cleanup.Schedule, cfg.Cleanup.Timeout, svc.CleanUp},
+			{schedulerService.JobPartitions, cfg.Partitions.Schedule, cfg.Partitions.Timeout, svc.MaintainPartitions},
+		} {
+			schedule, err := jobs.ParseSchedule(j.schedule)
+			if err != nil {
+				s.GetLogger().Error("invalid job schedule", sl.Err(err), slog.String("job", j.name))
+				os.Exit(1)
+			}
+
+			err = registry.Register(jobs.Job{Name: j.name, Schedule: schedule, Timeout: j.timeout, Run: j.run})
+			if err != nil {
+				s.GetLogger().Error("could not register job", sl.Err(err), slog.String("job", j.name))
+				os.Exit(1)
+			}
+		}
+
+		s.jobRegistry = registry
+	}
+
+	return s.jobRegistry
+}
+
+func (s *serviceProvider) GetJobService(ctx context.Context) *jobService.Service {
+	if s.jobService == nil {
+		s.jobService = jobService.NewJobService(s.GetJobRegistry(ctx), s.GetUserRepository(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.jobService
+}
+
+func (s *serviceProvider) GetArchiveService(ctx context.Context) *archiveService.Service {
+	if s.archiveService == nil {
+		s.archiveService = archiveService.NewArchiveService(s.GetArchiveRepository(ctx), s.GetUserRepository(ctx), s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.archiveService
+}
+
+func (s *serviceProvider) GetJWTService(ctx context.Context) jwt.Service {
+	if s.jwtService == nil {
+		s.jwtService = jwt.NewJWTService(s.GetConfig().GetJWTConfig().Secret, s.GetConfig().GetJWTConfig().Expiration, s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.jwtService
+}
+
+func (s *serviceProvider) GetJobImpl(ctx context.Context) *job.Implementation {
+	if s.jobImpl == nil {
+		s.jobImpl = job.NewImplementation(s.GetJobService(ctx), s.GetTracer(ctx))
+	}
+
+	return s.jobImpl
+}
+
+func (s *serviceProvider) GetArchiveImpl(ctx context.Context) *archive.Implementation {
+	if s.archiveImpl == nil {
+		s.archiveImpl = archive.NewImplementation(s.GetArchiveService(ctx), s.GetTracer(ctx))
+	}
+
+	return s.archiveImpl
+}
+
 func (s *serviceProvider) GetLogger() *slog.Logger {
 	if s.log == nil {
 		env := s.GetConfig().GetEnv()
@@ -126,23 +312,41 @@ func (s *serviceProvider) GetLogger() *slog.Logger {
 	return s.log
 }
 
-// GetRabbitProducer ...
-func (s *serviceProvider) GetRabbitProducer() rabbit.Producer {
-	if s.rabbitProducer == nil {
-		rp, err := rabbit.NewProducer(s.GetConfig().GetRabbitProducerConfig())
+// GetProducer connects to the message broker selected in the config.
+func (s *serviceProvider) GetProducer() broker.Producer {
+	if s.producer == nil {
+		var (
+			p   broker.Producer
+			err error
+		)
+
+		switch kind := s.GetConfig().GetBrokerConfig().Kind; kind {
+		case broker.KindRabbitMQ:
+			p, err = rabbit.NewProducer(s.GetConfig().GetRabbitProducerConfig(), s.GetLogger())
+		case broker.KindNats:
+			p, err = nats.NewProducer(s.GetConfig().GetNatsConfig(), s.GetLogger())
+		case broker.KindMemory:
+			var b *memory.Broker
+			b, err = memory.Default()
+			if err == nil {
+				p = b.NewProducer(s.GetConfig().GetMemoryConfig().QueueName)
+			}
+		default:
+			err = fmt.Errorf("%w: %q", broker.ErrUnknownKind, kind)
+		}
 		if err != nil {
-			s.log.Error("could not connect to rabbit producer", sl.Err(err))
+			s.GetLogger().Error("could not connect to message broker", sl.Err(err))
 			os.Exit(1)
 		}
-		s.rabbitProducer = rp
+		s.producer = p
 	}
 
-	return s.rabbitProducer
+	return s.producer
 }
 
 func (s *serviceProvider) GetTracer(ctx context.Context) trace.Tracer {
 	if s.tracer == nil {
-		tracer, err := observability.NewTracer(ctx, s.GetConfig().GetTracerConfig().EndpointURL, "scheduler", s.GetConfig().GetTracerConfig().SamplingRate)
+		tracer, err := observability.NewTracer(ctx, s.GetConfig().GetTracerConfig().EndpointURL, "scheduler", s.GetConfig().GetTracerConfig().SamplingRate, s.GetConfig().GetTracerConfig().Propagator)
 		if err != nil {
 			s.GetLogger().Error("failed to create tracer", sl.Err(err))
 			return nil
@@ -154,3 +358,26 @@ func (s *serviceProvider) GetTracer(ctx context.Context) trace.Tracer {
 
 	return s.tracer
 }
+
+func (s *serviceProvider) TxManager(ctx context.Context) db.TxManager {
+	if s.txManager == nil {
+		cfg := s.GetConfig().Database
+		s.txManager = transaction.NewTransactionManager(s.GetDB(ctx).DB(), s.GetLogger(), s.GetMeter(ctx), cfg.TxMaxAttempts, cfg.TxRetryDelay, cfg.TxMaxRetryDelay)
+	}
+
+	return s.txManager
+}
+
+func (s *serviceProvider) GetMeter(ctx context.Context) metric.Meter {
+	if s.meter == nil {
+		meter, err := observability.NewMeter(ctx, "scheduler")
+		if err != nil {
+			s.GetLogger().Error("failed to create meter", sl.Err(err))
+			return nil
+		}
+
+		s.meter = meter
+	}
+
+	return s.meter
+}
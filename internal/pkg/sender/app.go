@@ -1,8 +1,18 @@
 package sender
 
 import (
+	sender "booking-schedule/internal/app/service/sender"
+	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/observability"
 	"context"
-	"sync"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type App struct {
@@ -47,29 +57,75 @@ func (a *App) initServiceProvider(_ context.Context) error {
 
 // Run ...
 func (a *App) Run(ctx context.Context) error {
+	defer a.serviceProvider.Close()
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	metricsServer := a.runMetricsServer(ctx)
 	defer func() {
-		a.serviceProvider.rabbitConsumer.Close() //nolint:errcheck
+		// TODO: move timeout to config
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		metricsServer.Shutdown(ctx) //nolint:errcheck
 	}()
 
-	wg := &sync.WaitGroup{}
-	wg.Add(1)
-	err := a.runSenderService(ctx, wg)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// брокер, база данных и шаблоны подключаются здесь, ошибки возвращаются вызывающему вместо завершения процесса
+	senderService, err := a.serviceProvider.GetSenderService(ctx)
+	if err != nil {
+		return err
+	}
+
+	store, err := a.serviceProvider.GetTemplates()
+	if err != nil {
+		return err
+	}
+
+	go store.Watch(ctx, a.serviceProvider.GetConfig().GetTemplatesConfig().ReloadPeriod)
+	go senderService.PurgeSent(ctx, a.serviceProvider.GetConfig().GetDedupConfig().PurgePeriod)
+
+	err = a.runSenderService(ctx, senderService)
 	if err != nil {
 		return err
 	}
-	wg.Wait()
 	a.serviceProvider.GetLogger().Info("sender service stopped")
 	return nil
 }
 
-func (a *App) runSenderService(ctx context.Context, wg *sync.WaitGroup) error {
-	go func() {
-		defer wg.Done()
+// runSenderService blocks until the service is stopped and the messages it received are handled.
+func (a *App) runSenderService(ctx context.Context, senderService *sender.Service) error {
+	a.serviceProvider.GetLogger().Info("attempting to run sender service")
+
+	return senderService.Run(ctx)
+}
+
+// runMetricsServer exposes sender metrics for prometheus, since unlike the API services
+// the sender has no HTTP server of its own.
+func (a *App) runMetricsServer(ctx context.Context) *http.Server {
+	log := a.serviceProvider.GetLogger()
+
+	if meter := a.serviceProvider.GetMeter(ctx); meter != nil {
+		go observability.CollectMachineResourceMetrics(meter, log)
+	}
 
-		a.serviceProvider.GetLogger().Info("attempting to run sender service")
-		a.serviceProvider.GetSenderService(ctx).Run(ctx)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
 
+	srv := &http.Server{
+		Addr:              a.serviceProvider.GetConfig().GetMetricsAddress(),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		log.Info("starting metrics server", slog.String("address", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("metrics server failed", sl.Err(err))
+		}
 	}()
 
-	return nil
+	return srv
 }
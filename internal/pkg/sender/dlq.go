@@ -0,0 +1,51 @@
+package sender
+
+import (
+	"booking-schedule/internal/pkg/broker"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrUnknownCommand = errors.New("unknown dead-letter queue command, expected list or replay")
+
+// RunDeadLetterCommand lets an operator look into the dead-letter queue (list) or send
+// dead-lettered messages back to the work queue (replay).
+func (a *App) RunDeadLetterCommand(out io.Writer, command string, limit int) error {
+	if command != "list" && command != "replay" {
+		return ErrUnknownCommand
+	}
+
+	consumer, err := a.serviceProvider.GetConsumer()
+	if err != nil {
+		return err
+	}
+	defer consumer.Close() //nolint:errcheck
+
+	if command == "list" {
+		return listDeadLetters(out, consumer, limit)
+	}
+
+	return replayDeadLetters(out, consumer, limit)
+}
+
+func listDeadLetters(out io.Writer, consumer broker.Consumer, limit int) error {
+	msgs, err := consumer.DeadLetters(limit)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range msgs {
+		fmt.Fprintf(out, "message_id=%q timestamp=%s retries=%d\n%s\n\n", msg.ID, msg.Timestamp.Format("2006-01-02T15:04:05Z07:00"), msg.Retries, msg.Body)
+	}
+	fmt.Fprintf(out, "%d message(s) in dead-letter queue shown\n", len(msgs))
+
+	return nil
+}
+
+func replayDeadLetters(out io.Writer, consumer broker.Consumer, limit int) error {
+	replayed, err := consumer.Replay(limit)
+	fmt.Fprintf(out, "%d message(s) replayed\n", replayed)
+
+	return err
+}
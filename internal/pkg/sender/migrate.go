@@ -0,0 +1,18 @@
+package sender
+
+import (
+	"booking-schedule/internal/pkg/migrator"
+	"context"
+	"io"
+)
+
+// RunMigrateCommand runs the migrate subcommand (up, down, status or create) against the database
+// from the config without setting the service up.
+func RunMigrateCommand(ctx context.Context, out io.Writer, configType string, pathConfig string, dir string, command string, args []string) error {
+	cfg, err := newServiceProvider(configType, pathConfig).GetConfig().GetDBConfig()
+	if err != nil {
+		return err
+	}
+
+	return migrator.RunCommand(ctx, out, cfg, dir, command, args)
+}
@@ -1,14 +1,27 @@
 package sender
 
 import (
+	dedupRepository "booking-schedule/internal/app/repository/dedup"
+	"booking-schedule/internal/app/service/action"
 	sender "booking-schedule/internal/app/service/sender"
 	"booking-schedule/internal/config"
 	"booking-schedule/internal/logger/sl"
+	"booking-schedule/internal/pkg/broker"
+	"booking-schedule/internal/pkg/broker/memory"
+	"booking-schedule/internal/pkg/broker/nats"
+	"booking-schedule/internal/pkg/db"
+	"booking-schedule/internal/pkg/observability"
 	"booking-schedule/internal/pkg/rabbit"
+	"booking-schedule/internal/pkg/telegram"
+	"booking-schedule/internal/pkg/templates"
 	"context"
+	"fmt"
 	"log"
 	"log/slog"
 	"os"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -18,13 +31,23 @@ const (
 )
 
 type serviceProvider struct {
+	db db.Client
+
 	configType string
 	configPath string
 	config     *config.SenderConfig
 
 	log            *slog.Logger
-	rabbitConsumer rabbit.Consumer
-	senderService  *sender.Service
+	tracer         trace.Tracer
+	meter          metric.Meter
+	consumer       broker.Consumer
+	templates      *templates.Store
+	actionService  action.Service
+	telegramClient *telegram.Client
+
+	dedupRepository dedupRepository.Repository
+
+	senderService *sender.Service
 }
 
 func newServiceProvider(configType string, configPath string) *serviceProvider {
@@ -34,6 +57,26 @@ func newServiceProvider(configType string, configPath string) *serviceProvider {
 	}
 }
 
+// GetDB connects to the database, the error is returned up to App.Run so that the sender shuts down cleanly.
+func (s *serviceProvider) GetDB(ctx context.Context) (db.Client, error) {
+	if s.db == nil {
+		cfg, err := s.GetConfig().GetDBConfig()
+		if err != nil {
+			s.GetLogger().Error("could not get db config", sl.Err(err))
+			return nil, err
+		}
+		// журнал отправленных уведомлений только захватывается и обновляется, чтений для реплик у отправителя нет
+		dbc, err := db.NewClient(ctx, cfg, nil, 0, 0, s.GetConfig().Database.SlowQueryThreshold, s.GetLogger(), s.GetMeter(ctx))
+		if err != nil {
+			s.GetLogger().Error("could not connect to db", sl.Err(err))
+			return nil, err
+		}
+		s.db = dbc
+	}
+
+	return s.db, nil
+}
+
 func (s *serviceProvider) GetConfig() *config.SenderConfig {
 	if s.config == nil {
 		if s.configType == "env" {
@@ -57,14 +100,73 @@ func (s *serviceProvider) GetConfig() *config.SenderConfig {
 	return s.config
 }
 
-func (s *serviceProvider) GetSenderService(ctx context.Context) *sender.Service {
+func (s *serviceProvider) GetSenderService(ctx context.Context) (*sender.Service, error) {
 	if s.senderService == nil {
+		consumer, err := s.GetConsumer()
+		if err != nil {
+			return nil, err
+		}
+
+		store, err := s.GetTemplates()
+		if err != nil {
+			return nil, err
+		}
+
+		dedup, err := s.GetDedupRepository(ctx)
+		if err != nil {
+			return nil, err
+		}
+
 		s.senderService = sender.NewSenderService(
 			s.GetLogger(),
-			s.GetRabbitConsumer())
+			s.GetTracer(ctx),
+			s.GetMeter(ctx),
+			consumer,
+			store,
+			s.GetActionService(ctx),
+			s.GetConfig().GetActionsConfig().BaseURL,
+			s.GetTelegramClient(),
+			s.GetConfig().GetWorkersConfig().Concurrency,
+			s.GetConfig().GetWorkersConfig().ShutdownTimeout,
+			dedup,
+			s.GetConfig().GetDedupConfig().Lease,
+			s.GetConfig().GetDedupConfig().Retention)
+	}
+
+	return s.senderService, nil
+}
+
+func (s *serviceProvider) GetDedupRepository(ctx context.Context) (dedupRepository.Repository, error) {
+	if s.dedupRepository == nil {
+		dbc, err := s.GetDB(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.dedupRepository = dedupRepository.NewDedupRepository(dbc, s.GetLogger(), s.GetTracer(ctx))
+	}
+
+	return s.dedupRepository, nil
+}
+
+func (s *serviceProvider) GetActionService(ctx context.Context) action.Service {
+	if s.actionService == nil {
+		s.actionService = action.NewActionService(s.GetConfig().GetActionsConfig().Secret, s.GetLogger(), s.GetTracer(ctx))
 	}
 
-	return s.senderService
+	return s.actionService
+}
+
+// GetTelegramClient returns nil if no bot token is configured, notifications are only logged then.
+func (s *serviceProvider) GetTelegramClient() *telegram.Client {
+	if s.telegramClient == nil {
+		cfg := s.GetConfig().GetTelegramConfig()
+		if cfg.Token == "" {
+			return nil
+		}
+		s.telegramClient = telegram.NewClient(cfg.APIURL, cfg.Token, cfg.Timeout, 0)
+	}
+
+	return s.telegramClient
 }
 
 func (s *serviceProvider) GetLogger() *slog.Logger {
@@ -85,16 +187,88 @@ func (s *serviceProvider) GetLogger() *slog.Logger {
 	return s.log
 }
 
-// GetRabbitProducer ...
-func (s *serviceProvider) GetRabbitConsumer() rabbit.Consumer {
-	if s.rabbitConsumer == nil {
-		rc, err := rabbit.NewConsumer(s.GetConfig().GetRabbitConsumerConfig())
+// GetConsumer connects to the message broker selected in the config.
+func (s *serviceProvider) GetConsumer() (broker.Consumer, error) {
+	if s.consumer == nil {
+		var (
+			c   broker.Consumer
+			err error
+		)
+
+		switch kind := s.GetConfig().GetBrokerConfig().Kind; kind {
+		case broker.KindRabbitMQ:
+			c, err = rabbit.NewConsumer(s.GetConfig().GetRabbitConsumerConfig(), s.GetLogger())
+		case broker.KindNats:
+			c, err = nats.NewConsumer(s.GetConfig().GetNatsConfig(), s.GetLogger())
+		case broker.KindMemory:
+			var b *memory.Broker
+			b, err = memory.Default()
+			if err == nil {
+				cfg := s.GetConfig().GetMemoryConfig()
+				c = b.NewConsumer(cfg.QueueName, cfg.MaxRetries, cfg.RetryDelay)
+			}
+		default:
+			err = fmt.Errorf("%w: %q", broker.ErrUnknownKind, kind)
+		}
+		if err != nil {
+			s.GetLogger().Error("could not connect to message broker", sl.Err(err))
+			return nil, err
+		}
+		s.consumer = c
+	}
+
+	return s.consumer, nil
+}
+
+// GetTemplates ...
+func (s *serviceProvider) GetTemplates() (*templates.Store, error) {
+	if s.templates == nil {
+		cfg := s.GetConfig().GetTemplatesConfig()
+		store, err := templates.NewStore(cfg.Dir, cfg.DefaultLanguage, s.GetLogger())
 		if err != nil {
-			s.log.Error("could not connect to rabbit consumer", sl.Err(err))
-			os.Exit(1)
+			s.GetLogger().Error("could not load notification templates", sl.Err(err))
+			return nil, err
 		}
-		s.rabbitConsumer = rc
+		s.templates = store
+	}
+
+	return s.templates, nil
+}
+
+// Close releases the connections that have been established, some may be missing if the setup failed.
+func (s *serviceProvider) Close() {
+	if s.consumer != nil {
+		s.consumer.Close() //nolint:errcheck
+	}
+	if s.db != nil {
+		s.db.Close() //nolint:errcheck
+	}
+}
+
+func (s *serviceProvider) GetTracer(ctx context.Context) trace.Tracer {
+	if s.tracer == nil {
+		tracer, err := observability.NewTracer(ctx, s.GetConfig().GetTracerConfig().EndpointURL, "sender", s.GetConfig().GetTracerConfig().SamplingRate, s.GetConfig().GetTracerConfig().Propagator)
+		if err != nil {
+			s.GetLogger().Error("failed to create tracer", sl.Err(err))
+			return nil
+		}
+
+		s.tracer = tracer
+	}
+
+	return s.tracer
+}
+
+func (s *serviceProvider) GetMeter(ctx context.Context) metric.Meter {
+	if s.meter == nil {
+		meter, err := observability.NewMeter(ctx, "sender")
+		if err != nil {
+			s.GetLogger().Error("failed to create meter", sl.Err(err))
+			return nil
+		}
+
+		s.meter = meter
 	}
 
-	return s.rabbitConsumer
+	return s.meter
 }
@@ -0,0 +1,117 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultURL is the address of the Telegram Bot API.
+const DefaultURL = "https://api.telegram.org"
+
+var ErrRequest = errors.New("telegram bot api request failed")
+
+// Client is a minimal Telegram Bot API client covering what the bot needs: long polling for updates,
+// sending and editing messages with inline keyboards and answering button presses.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+// NewClient creates a client for the Bot API at baseURL, e.g. DefaultURL or the address of a local fake server.
+// The HTTP timeout is extended by the long polling timeout so that getUpdates requests are not cut short.
+func NewClient(baseURL string, token string, timeout time.Duration, pollTimeout time.Duration) *Client {
+	return &Client{
+		baseURL: baseURL,
+		token:   token,
+		http:    &http.Client{Timeout: timeout + pollTimeout},
+	}
+}
+
+type response struct {
+	Ok          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+}
+
+// call invokes the Bot API method with params encoded as JSON and decodes the result into res, if it is not nil.
+func (c *Client) call(ctx context.Context, method string, params interface{}, res interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	var apiResp response
+	err = json.NewDecoder(resp.Body).Decode(&apiResp)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrRequest, method, err)
+	}
+
+	if !apiResp.Ok {
+		return fmt.Errorf("%w: %s: %d %s", ErrRequest, method, apiResp.ErrorCode, apiResp.Description)
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	return json.Unmarshal(apiResp.Result, res)
+}
+
+// GetUpdates long polls for updates starting with offset for up to timeout.
+func (c *Client) GetUpdates(ctx context.Context, offset int64, timeout time.Duration) ([]Update, error) {
+	var res []Update
+	err := c.call(ctx, "getUpdates", &GetUpdatesRequest{
+		Offset:         offset,
+		Timeout:        int(timeout.Seconds()),
+		AllowedUpdates: []string{"message", "callback_query"},
+	}, &res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// SendMessage sends a text message and returns it as delivered.
+func (c *Client) SendMessage(ctx context.Context, req *SendMessageRequest) (*Message, error) {
+	res := new(Message)
+	err := c.call(ctx, "sendMessage", req, res)
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// EditMessageText replaces the text and the keyboard of a message sent by the bot.
+func (c *Client) EditMessageText(ctx context.Context, req *EditMessageTextRequest) error {
+	return c.call(ctx, "editMessageText", req, nil)
+}
+
+// AnswerCallbackQuery stops the loading indicator on the pressed button, optionally showing text.
+func (c *Client) AnswerCallbackQuery(ctx context.Context, callbackQueryID string, text string) error {
+	return c.call(ctx, "answerCallbackQuery", &AnswerCallbackQueryRequest{
+		CallbackQueryID: callbackQueryID,
+		Text:            text,
+	}, nil)
+}
@@ -0,0 +1,264 @@
+// Package telegramtest provides a local fake of the Telegram Bot API, so that the bot can be run end to end
+// without reaching Telegram: users' messages and button presses are queued as updates, and everything the bot
+// sends is recorded.
+package telegramtest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"booking-schedule/internal/pkg/telegram"
+)
+
+var ErrTimeout = errors.New("timed out waiting for bot messages")
+
+// Sent is a message the bot sent or edited.
+type Sent struct {
+	// Метод Bot API: sendMessage или editMessageText
+	Method    string
+	ChatID    int64
+	MessageID int64
+	Text      string
+	Keyboard  [][]telegram.InlineKeyboardButton
+}
+
+// Server is a fake Bot API server. Point telegram.NewClient at its URL with its token.
+type Server struct {
+	*httptest.Server
+	Token string
+
+	mu       sync.Mutex
+	updates  []telegram.Update
+	sent     []Sent
+	answered []string
+	nextID   int64
+	// закрывается и пересоздается при каждом изменении, чтобы разбудить ожидающих
+	changed chan struct{}
+}
+
+// NewServer starts a fake Bot API server that accepts requests for the token.
+func NewServer(token string) *Server {
+	s := &Server{
+		Token:   token,
+		changed: make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// SendText queues a private message from the user to the bot.
+func (s *Server) SendText(userID int64, text string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	s.updates = append(s.updates, telegram.Update{
+		UpdateID: s.nextID,
+		Message: &telegram.Message{
+			MessageID: s.nextID,
+			From:      &telegram.User{ID: userID, FirstName: "user"},
+			Chat:      telegram.Chat{ID: userID, Type: "private"},
+			Date:      time.Now().Unix(),
+			Text:      text,
+		},
+	})
+	s.notify()
+}
+
+// Press queues a press of the inline keyboard button with data on the message the bot sent to the user.
+func (s *Server) Press(userID int64, messageID int64, data string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	s.updates = append(s.updates, telegram.Update{
+		UpdateID: s.nextID,
+		CallbackQuery: &telegram.CallbackQuery{
+			ID:   strconv.FormatInt(s.nextID, 10),
+			From: telegram.User{ID: userID, FirstName: "user"},
+			Message: &telegram.Message{
+				MessageID: messageID,
+				Chat:      telegram.Chat{ID: userID, Type: "private"},
+			},
+			Data: data,
+		},
+	})
+	s.notify()
+}
+
+// Sent returns the messages the bot has sent or edited so far.
+func (s *Server) Sent() []Sent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Sent(nil), s.sent...)
+}
+
+// Answered returns the ids of the button presses the bot has answered.
+func (s *Server) Answered() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.answered...)
+}
+
+// WaitSent waits until the bot has sent or edited at least n messages and returns all of them.
+func (s *Server) WaitSent(n int, timeout time.Duration) ([]Sent, error) {
+	deadline := time.After(timeout)
+	for {
+		s.mu.Lock()
+		if len(s.sent) >= n {
+			res := append([]Sent(nil), s.sent...)
+			s.mu.Unlock()
+			return res, nil
+		}
+		changed := s.changed
+		s.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return s.Sent(), ErrTimeout
+		}
+	}
+}
+
+// notify wakes up long polls and waiters, must be called with mu held.
+func (s *Server) notify() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+type response struct {
+	Ok          bool        `json:"ok"`
+	Result      interface{} `json:"result,omitempty"`
+	ErrorCode   int         `json:"error_code,omitempty"`
+	Description string      `json:"description,omitempty"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	method, ok := strings.CutPrefix(r.URL.Path, "/bot"+s.Token+"/")
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, &response{ErrorCode: http.StatusUnauthorized, Description: "Unauthorized"})
+		return
+	}
+
+	switch method {
+	case "getUpdates":
+		req := new(telegram.GetUpdatesRequest)
+		if !decode(w, r, req) {
+			return
+		}
+		writeJSON(w, http.StatusOK, &response{Ok: true, Result: s.getUpdates(r, req)})
+	case "sendMessage":
+		req := new(telegram.SendMessageRequest)
+		if !decode(w, r, req) {
+			return
+		}
+		writeJSON(w, http.StatusOK, &response{Ok: true, Result: s.record("sendMessage", req.ChatID, 0, req.Text, req.ReplyMarkup)})
+	case "editMessageText":
+		req := new(telegram.EditMessageTextRequest)
+		if !decode(w, r, req) {
+			return
+		}
+		writeJSON(w, http.StatusOK, &response{Ok: true, Result: s.record("editMessageText", req.ChatID, req.MessageID, req.Text, req.ReplyMarkup)})
+	case "answerCallbackQuery":
+		req := new(telegram.AnswerCallbackQueryRequest)
+		if !decode(w, r, req) {
+			return
+		}
+		s.mu.Lock()
+		s.answered = append(s.answered, req.CallbackQueryID)
+		s.notify()
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, &response{Ok: true, Result: true})
+	default:
+		writeJSON(w, http.StatusNotFound, &response{ErrorCode: http.StatusNotFound, Description: "Not Found: method not found"})
+	}
+}
+
+// getUpdates returns the updates starting with the offset, waiting for new ones up to the requested timeout.
+// Updates before the offset are considered confirmed and dropped, as the real Bot API does.
+func (s *Server) getUpdates(r *http.Request, req *telegram.GetUpdatesRequest) []telegram.Update {
+	deadline := time.After(time.Duration(req.Timeout) * time.Second)
+	for {
+		s.mu.Lock()
+		res := make([]telegram.Update, 0)
+		kept := s.updates[:0]
+		for _, update := range s.updates {
+			if update.UpdateID < req.Offset {
+				continue
+			}
+			kept = append(kept, update)
+			res = append(res, update)
+		}
+		s.updates = kept
+		changed := s.changed
+		s.mu.Unlock()
+
+		if len(res) != 0 || req.Timeout == 0 {
+			return res
+		}
+
+		select {
+		case <-changed:
+		case <-deadline:
+			return res
+		case <-r.Context().Done():
+			return res
+		}
+	}
+}
+
+func (s *Server) record(method string, chatID int64, messageID int64, text string, markup *telegram.InlineKeyboardMarkup) *telegram.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if messageID == 0 {
+		s.nextID++
+		messageID = s.nextID
+	}
+
+	sent := Sent{
+		Method:    method,
+		ChatID:    chatID,
+		MessageID: messageID,
+		Text:      text,
+	}
+	if markup != nil {
+		sent.Keyboard = markup.InlineKeyboard
+	}
+	s.sent = append(s.sent, sent)
+	s.notify()
+
+	return &telegram.Message{
+		MessageID:   messageID,
+		Chat:        telegram.Chat{ID: chatID, Type: "private"},
+		Date:        time.Now().Unix(),
+		Text:        text,
+		ReplyMarkup: markup,
+	}
+}
+
+func decode(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, &response{ErrorCode: http.StatusBadRequest, Description: "Bad Request: " + err.Error()})
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, resp *response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp) //nolint:errcheck
+}
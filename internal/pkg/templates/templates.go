@@ -0,0 +1,193 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"booking-schedule/internal/logger/sl"
+)
+
+const templateExt = ".tmpl"
+
+var (
+	ErrNoTemplates      = errors.New("no notification templates found")
+	ErrTemplateNotFound = errors.New("no notification template for message type")
+)
+
+// Store renders notification texts from text/template files laid out as <dir>/<language>/<message type>.tmpl,
+// e.g. configs/templates/ru/booking.reminder.tmpl.
+type Store struct {
+	dir             string
+	defaultLanguage string
+	log             *slog.Logger
+
+	mu sync.RWMutex
+	// язык -> тип сообщения -> шаблон
+	templates map[string]map[string]*template.Template
+	// путь к файлу -> время его изменения на момент загрузки
+	modTimes map[string]time.Time
+}
+
+// NewStore loads all templates from dir. Templates missing in a language fall back to defaultLanguage.
+func NewStore(dir string, defaultLanguage string, log *slog.Logger) (*Store, error) {
+	s := &Store{
+		dir:             dir,
+		defaultLanguage: defaultLanguage,
+		log:             log,
+	}
+
+	err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *Store) load() error {
+	files, err := s.scan()
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("%w in %s", ErrNoTemplates, s.dir)
+	}
+
+	templates := make(map[string]map[string]*template.Template)
+	for path := range files {
+		language := filepath.Base(filepath.Dir(path))
+		msgType := strings.TrimSuffix(filepath.Base(path), templateExt)
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		tmpl, err := template.New(msgType).Option("missingkey=error").Parse(string(data))
+		if err != nil {
+			return err
+		}
+
+		if templates[language] == nil {
+			templates[language] = make(map[string]*template.Template)
+		}
+		templates[language][msgType] = tmpl
+	}
+
+	s.mu.Lock()
+	s.templates = templates
+	s.modTimes = files
+	s.mu.Unlock()
+
+	return nil
+}
+
+// scan returns modification times of all template files found in the language directories.
+func (s *Store) scan() (map[string]time.Time, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*", "*"+templateExt))
+	if err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]time.Time, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		res[path] = info.ModTime()
+	}
+
+	return res, nil
+}
+
+func (s *Store) changed() (bool, error) {
+	files, err := s.scan()
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(files) != len(s.modTimes) {
+		return true, nil
+	}
+
+	for path, modTime := range files {
+		loaded, ok := s.modTimes[path]
+		if !ok || !loaded.Equal(modTime) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Watch reloads the templates whenever files in the directory are added, removed or modified.
+// If the new set fails to load the previous one stays in use. Watch returns when ctx is done.
+func (s *Store) Watch(ctx context.Context, period time.Duration) {
+	const op = "templates.Store.Watch"
+
+	log := s.log.With(slog.String("op", op))
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := s.changed()
+		if err != nil {
+			log.Error("failed to check templates for changes", sl.Err(err))
+			continue
+		}
+		if !changed {
+			continue
+		}
+
+		err = s.load()
+		if err != nil {
+			log.Error("failed to reload templates, keeping previous ones", sl.Err(err))
+			continue
+		}
+
+		log.Info("templates reloaded")
+	}
+}
+
+// Render executes the template of the message type in the given language.
+func (s *Store) Render(language string, msgType string, data interface{}) (string, error) {
+	s.mu.RLock()
+	tmpl, ok := s.templates[language][msgType]
+	if !ok {
+		tmpl, ok = s.templates[s.defaultLanguage][msgType]
+	}
+	s.mu.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("%w: %s (%s)", ErrTemplateNotFound, msgType, language)
+	}
+
+	var sb strings.Builder
+	err := tmpl.Execute(&sb, data)
+	if err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}